@@ -0,0 +1,302 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Telegram allows about 30 messages per second to different chats.
+const broadcastInterval = 50 * time.Millisecond
+
+// UserActivity is the last message received from the Telegram user.
+type UserActivity struct {
+	UserName   string
+	UserID     int
+	ChatID     int64
+	LastActive time.Time
+}
+
+// FinalSurgeStats counts FinalSurge requests and failed ones since the start.
+type FinalSurgeStats struct {
+	fs FinalSurge
+
+	requests atomic.Int64
+	errors   atomic.Int64
+}
+
+func NewFinalSurgeStats(fs FinalSurge) *FinalSurgeStats {
+	return &FinalSurgeStats{fs: fs}
+}
+
+func (s *FinalSurgeStats) Login(ctx context.Context, email, password string) (UserToken, error) {
+	userToken, err := s.fs.Login(ctx, email, password)
+	s.count(err)
+
+	return userToken, err //nolint:wrapcheck // transparent wrapper
+}
+
+func (s *FinalSurgeStats) Workouts(ctx context.Context, userToken UserToken, startDate, endDate time.Time,
+) ([]Workout, error) {
+	workouts, err := s.fs.Workouts(ctx, userToken, startDate, endDate)
+	s.count(err)
+
+	return workouts, err //nolint:wrapcheck // transparent wrapper
+}
+
+// Counts returns the number of requests and the number of failed ones.
+func (s *FinalSurgeStats) Counts() (requests, failed int64) {
+	return s.requests.Load(), s.errors.Load()
+}
+
+func (s *FinalSurgeStats) count(err error) {
+	s.requests.Add(1)
+
+	if err != nil {
+		s.errors.Add(1)
+	}
+}
+
+// isAdmin reports whether the Telegram user is listed in ADMIN_IDS.
+func (b *Bot) isAdmin(user *tgbotapi.User) bool {
+	for _, id := range b.config.AdminIDs {
+		if user != nil && user.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// trackActivity records the last message of the user for /stats and /broadcast.
+func (b *Bot) trackActivity(ctx context.Context, message *tgbotapi.Message) {
+	if message.From == nil {
+		return
+	}
+
+	if err := b.db.UpdateUserActivity(ctx, UserActivity{
+		UserName:   message.From.UserName,
+		UserID:     message.From.ID,
+		ChatID:     message.Chat.ID,
+		LastActive: message.Time(),
+	}); err != nil {
+		log.Printf("update activity of %s: %v", message.From.UserName, err)
+	}
+}
+
+func (b *Bot) commandStats(ctx context.Context, chatID int64, loc *Locale) (tgbotapi.Chattable, error) {
+	authorized, err := b.db.AuthorizedUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get authorized users: %w", err)
+	}
+
+	activities, err := b.db.UserActivities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get user activities: %w", err)
+	}
+
+	now := b.clock.Now()
+
+	var activeDay, activeWeek int
+
+	for _, a := range activities {
+		if a.LastActive.After(now.AddDate(0, 0, -1)) {
+			activeDay++
+		}
+
+		if a.LastActive.After(now.AddDate(0, 0, -digestDays)) {
+			activeWeek++
+		}
+	}
+
+	requests, failed := b.fsStats.Counts()
+
+	errorRate := 0.0
+	if requests > 0 {
+		errorRate = float64(failed) / float64(requests) * 100
+	}
+
+	return tgbotapi.NewMessage(chatID, loc.T(msgStats, authorized, activeDay, activeWeek, requests, failed,
+		errorRate)), nil
+}
+
+// commandBroadcast sends the text to every known chat in the background, so updates are not delayed.
+// The admin gets a report when the broadcast is done.
+func (b *Bot) commandBroadcast(ctx context.Context, chatID int64, loc *Locale, text string,
+) (tgbotapi.Chattable, error) {
+	if strings.TrimSpace(text) == "" {
+		return tgbotapi.NewMessage(chatID, loc.T(msgBroadcastUsage)), nil
+	}
+
+	chatIDs, err := b.broadcastChats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	go b.broadcast(ctx, chatID, loc, chatIDs, text)
+
+	return tgbotapi.NewMessage(chatID, loc.T(msgBroadcastStarted, len(chatIDs))), nil
+}
+
+// broadcastChats returns chats of active users and subscribers without duplicates.
+func (b *Bot) broadcastChats(ctx context.Context) ([]int64, error) {
+	activities, err := b.db.UserActivities(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get user activities: %w", err)
+	}
+
+	subscriptions, err := b.db.Subscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get subscriptions: %w", err)
+	}
+
+	seen := make(map[int64]bool, len(activities)+len(subscriptions))
+
+	var chatIDs []int64
+
+	add := func(chatID int64) {
+		if !seen[chatID] {
+			seen[chatID] = true
+			chatIDs = append(chatIDs, chatID)
+		}
+	}
+
+	for _, a := range activities {
+		add(a.ChatID)
+	}
+
+	for _, s := range subscriptions {
+		add(s.ChatID)
+	}
+
+	sort.Slice(chatIDs, func(i, j int) bool { return chatIDs[i] < chatIDs[j] })
+
+	return chatIDs, nil
+}
+
+func (b *Bot) broadcast(ctx context.Context, adminChatID int64, loc *Locale, chatIDs []int64, text string) {
+	var sent, failed int
+
+	for i, chatID := range chatIDs {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-b.clock.After(broadcastInterval):
+			}
+		}
+
+		if _, err := b.bot.Send(tgbotapi.NewMessage(chatID, text)); err != nil {
+			log.Printf("broadcast to chat %d: %v", chatID, err)
+
+			failed++
+
+			continue
+		}
+
+		sent++
+	}
+
+	if _, err := b.bot.Send(tgbotapi.NewMessage(adminChatID, loc.T(msgBroadcastDone, sent, failed))); err != nil {
+		log.Printf("send broadcast report to chat %d: %v", adminChatID, err)
+	}
+}
+
+// commandUser shows the link status of the user found by Telegram ID or @username.
+func (b *Bot) commandUser(ctx context.Context, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		return tgbotapi.NewMessage(chatID, loc.T(msgUserUsage)), nil
+	}
+
+	activity, err := b.findUserActivity(ctx, args)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	// Users linked before activity tracking started are found by @username in accounts and subscriptions.
+	active := err == nil
+	if !active {
+		if _, errID := strconv.Atoi(args); errID == nil {
+			return tgbotapi.NewMessage(chatID, loc.T(msgUserNotFound)), nil
+		}
+
+		activity = UserActivity{UserName: strings.TrimPrefix(args, "@")}
+	}
+
+	accounts, err := b.db.Accounts(ctx, activity.UserName)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("get accounts: %w", err)
+	}
+
+	labels := make([]string, 0, len(accounts))
+	for _, a := range accounts {
+		label := a.Label
+		if a.Active {
+			label += "*"
+		}
+
+		labels = append(labels, label)
+	}
+
+	linked := strings.Join(labels, ", ")
+	if len(labels) == 0 {
+		linked = loc.T(msgUserNotLinked)
+	}
+
+	subscriptions, err := b.db.Subscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get subscriptions: %w", err)
+	}
+
+	subscribed := false
+
+	for _, s := range subscriptions {
+		if strings.EqualFold(s.UserName, activity.UserName) {
+			subscribed = true
+		}
+	}
+
+	yesNo := loc.T(msgNo)
+	if subscribed {
+		yesNo = loc.T(msgYes)
+	}
+
+	if !active {
+		if len(accounts) == 0 && !subscribed {
+			return tgbotapi.NewMessage(chatID, loc.T(msgUserNotFound)), nil
+		}
+
+		return tgbotapi.NewMessage(chatID, loc.T(msgUserInfoInactive, activity.UserName, linked, yesNo)), nil
+	}
+
+	return tgbotapi.NewMessage(chatID, loc.T(msgUserInfo, activity.UserName, activity.UserID, activity.ChatID,
+		activity.LastActive.UTC().Format("2006-01-02 15:04 MST"), linked, yesNo)), nil
+}
+
+func (b *Bot) findUserActivity(ctx context.Context, idOrName string) (UserActivity, error) {
+	activities, err := b.db.UserActivities(ctx)
+	if err != nil {
+		return UserActivity{}, fmt.Errorf("get user activities: %w", err)
+	}
+
+	id, errID := strconv.Atoi(idOrName)
+	userName := strings.TrimPrefix(idOrName, "@")
+
+	for _, a := range activities {
+		if (errID == nil && a.UserID == id) || strings.EqualFold(a.UserName, userName) {
+			return a, nil
+		}
+	}
+
+	return UserActivity{}, ErrNotFound
+}
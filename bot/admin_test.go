@@ -0,0 +1,183 @@
+package bot_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestBot_ProcessUpdate_Admin(t *testing.T) {
+	const userName = "alexandear"
+	const adminID = 7
+	const chatID = int64(20)
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	activities := []UserActivity{
+		{UserName: userName, UserID: adminID, ChatID: chatID, LastActive: now.Add(-time.Hour)},
+		{UserName: "runner", UserID: 8, ChatID: 30, LastActive: now.AddDate(0, 0, -3)},
+		{UserName: "cyclist", UserID: 9, ChatID: 40, LastActive: now.AddDate(0, 0, -30)},
+	}
+	command := func(text string, userID int) tgbotapi.Update {
+		name, _, _ := strings.Cut(text[1:], " ")
+
+		return tgbotapi.Update{
+			Message: &tgbotapi.Message{
+				Chat:     &tgbotapi.Chat{ID: chatID},
+				From:     &tgbotapi.User{ID: userID, UserName: userName},
+				Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(name) + 1}},
+				Text:     text,
+			},
+		}
+	}
+
+	t.Run("stats", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		fsMock := mock.NewMockFinalSurge(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		clockMock := mock.NewMockClock(ctrl)
+		bot := NewBot(senderMock, storageMock, fsMock, clockMock, &Config{AdminIDs: []int{adminID}}, NewTaskTemplates())
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		fsMock.EXPECT().Login(gomock.Any(), "user@example.com", "wrong").Return(UserToken{}, errors.New("401")).Times(1)
+		fsMock.EXPECT().Login(gomock.Any(), "user@example.com", "password").Return(UserToken{}, nil).Times(1)
+		_, _ = bot.FinalSurge().Login(context.Background(), "user@example.com", "wrong")
+		_, _ = bot.FinalSurge().Login(context.Background(), "user@example.com", "password")
+
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		storageMock.EXPECT().AuthorizedUsers(gomock.Any()).Return(2, nil).Times(1)
+		storageMock.EXPECT().UserActivities(gomock.Any()).Return(activities, nil).Times(1)
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text: `Authorized users: 2
+Active in the last day: 1
+Active in the last week: 2
+FinalSurge requests: 2, errors: 1 (50.0%)`,
+		}).Times(1)
+		if err := bot.ProcessUpdate(context.Background(), command("/stats", adminID)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("not admin", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		bot := NewBot(senderMock, storageMock, nil, nil, &Config{AdminIDs: []int{adminID}}, NewTaskTemplates())
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text:     "Unknown command /stats. Enter /help to see all commands.",
+		}).Times(1)
+		if err := bot.ProcessUpdate(context.Background(), command("/stats", 8)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("broadcast", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		clockMock := mock.NewMockClock(ctrl)
+		bot := NewBot(senderMock, storageMock, nil, clockMock, &Config{AdminIDs: []int{adminID}}, NewTaskTemplates())
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		const text = "New version:\n/plan accepts ranges"
+		storageMock.EXPECT().UserActivities(gomock.Any()).Return(activities, nil).Times(1)
+		storageMock.EXPECT().Subscriptions(gomock.Any()).Return([]Subscription{
+			{UserName: "runner", ChatID: 30},
+			{UserName: "swimmer", ChatID: 50},
+		}, nil).Times(1)
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text:     "Sending to 4 chats...",
+		}).Times(1)
+		clockMock.EXPECT().After(gomock.Any()).DoAndReturn(func(time.Duration) <-chan time.Time {
+			c := make(chan time.Time, 1)
+			c <- now
+
+			return c
+		}).Times(3)
+		for _, id := range []int64{chatID, 30, 50} {
+			senderMock.EXPECT().Send(tgbotapi.NewMessage(id, text)).Times(1)
+		}
+		senderMock.EXPECT().Send(tgbotapi.NewMessage(40, text)).Return(tgbotapi.Message{}, errors.New("blocked")).
+			Times(1)
+		done := make(chan struct{})
+		senderMock.EXPECT().Send(tgbotapi.NewMessage(chatID, "Broadcast done: 3 sent, 1 failed")).
+			Do(func(tgbotapi.Chattable) { close(done) }).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), command("/broadcast "+text, adminID)); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("broadcast is not done")
+		}
+	})
+
+	t.Run("user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		bot := NewBot(senderMock, storageMock, nil, nil, &Config{AdminIDs: []int{adminID}}, NewTaskTemplates())
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		storageMock.EXPECT().UserActivities(gomock.Any()).Return(activities, nil).Times(3)
+		storageMock.EXPECT().Accounts(gomock.Any(), "runner").Return([]Account{
+			{Label: "personal", Active: true},
+			{Label: "club"},
+		}, nil).Times(1)
+		storageMock.EXPECT().Subscriptions(gomock.Any()).Return([]Subscription{{UserName: "runner", ChatID: 30}}, nil).
+			Times(3)
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text: `@runner, ID 8, chat 30
+Last active: 2026-10-16 12:00 UTC
+Accounts: personal*, club
+Subscribed: yes`,
+		}).Times(1)
+		if err := bot.ProcessUpdate(context.Background(), command("/user 8", adminID)); err != nil {
+			t.Fatal(err)
+		}
+
+		// Linked before activity tracking started.
+		storageMock.EXPECT().Accounts(gomock.Any(), "coach").Return([]Account{{Label: "coach", Active: true}}, nil).
+			Times(1)
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text: `@coach, no messages since activity tracking started
+Accounts: coach*
+Subscribed: no`,
+		}).Times(1)
+		if err := bot.ProcessUpdate(context.Background(), command("/user @coach", adminID)); err != nil {
+			t.Fatal(err)
+		}
+
+		storageMock.EXPECT().Accounts(gomock.Any(), "nobody").Return(nil, nil).Times(1)
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text:     "User not found",
+		}).Times(1)
+		if err := bot.ProcessUpdate(context.Background(), command("/user @nobody", adminID)); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,7 +14,38 @@ import (
 )
 
 const (
-	CommandStart = "start"
+	CommandStart         = "start"
+	CommandSubscribe     = "subscribe"
+	CommandUnsubscribe   = "unsubscribe"
+	CommandCalendar      = "calendar"
+	CommandExport        = "export"
+	CommandExportWorkout = "export_workout"
+	CommandAccounts      = "accounts"
+	CommandLogout        = "logout"
+	CommandForgetMe      = "forgetme"
+	CommandLanguage      = "language"
+	CommandMarkup        = "markup"
+	CommandFormat        = "format"
+	CommandChart         = "chart"
+	CommandRaces         = "races"
+	CommandPlan          = "plan"
+	CommandTimeZone      = "timezone"
+	CommandStats         = "stats"
+	CommandBroadcast     = "broadcast"
+	CommandUser          = "user"
+	CommandTask          = "task"
+	CommandHelp          = "help"
+	CommandCancel        = "cancel"
+	CommandBack          = "back"
+	CommandSettings      = "settings"
+	CommandSteps         = "steps"
+	CommandYesterday     = "yesterday"
+
+	AccountArgUse    = "use"
+	AccountArgAll    = "all"
+	AccountArgUnlink = "unlink"
+
+	DefaultAccountLabel = "default"
 
 	KeyboardButtonTask = "/task"
 
@@ -25,18 +59,96 @@ type UserToken struct {
 	Token   string
 }
 
+type Account struct {
+	Label     string
+	UserToken UserToken
+	Active    bool
+}
+
 type Workout struct {
 	Date        time.Time
 	Description string
+	RestDay     bool
+	// Activity is the FinalSurge activity type name such as "Run" or "Bike".
+	Activity  string
+	Name      string
+	Race      bool
+	Completed bool
+	Planned   Volume
+	Actual    Volume
+}
+
+// Volume is a distance in meters and a duration of a workout.
+type Volume struct {
+	Distance float64
+	Duration time.Duration
+}
+
+func (v Volume) Add(o Volume) Volume {
+	return Volume{Distance: v.Distance + o.Distance, Duration: v.Duration + o.Duration}
+}
+
+type Subscription struct {
+	UserName string
+	ChatID   int64
+}
+
+// DeletedRows is the number of rows deleted from the table.
+type DeletedRows struct {
+	Table string
+	Rows  int64
 }
 
 type Sender interface {
 	Send(msg tgbotapi.Chattable) (tgbotapi.Message, error)
+	AnswerInlineQuery(config tgbotapi.InlineConfig) (tgbotapi.APIResponse, error)
+	AnswerCallbackQuery(config tgbotapi.CallbackConfig) (tgbotapi.APIResponse, error)
+	// MakeRequest calls Bot API methods not supported by tgbotapi such as setMyCommands.
+	MakeRequest(endpoint string, params url.Values) (tgbotapi.APIResponse, error)
 }
 
 type Storage interface {
+	SettingsStore
+
+	// UserToken returns the token of the first active account.
 	UserToken(ctx context.Context, userName string) (UserToken, error)
-	UpdateUserToken(ctx context.Context, userName string, userToken UserToken) error
+	// UserTokens returns tokens of all active accounts.
+	UserTokens(ctx context.Context, userName string) ([]UserToken, error)
+	// UpdateUserToken links the account with the label and makes it the only active one.
+	UpdateUserToken(ctx context.Context, userName, label string, userToken UserToken) error
+	Accounts(ctx context.Context, userName string) ([]Account, error)
+	ActivateAccount(ctx context.Context, userName, label string) error
+	ActivateAllAccounts(ctx context.Context, userName string) error
+	// DeleteAccount unlinks the account and activates another one when no active accounts are left.
+	DeleteAccount(ctx context.Context, userName, label string) error
+	// DeleteAccounts unlinks all accounts of the user.
+	DeleteAccounts(ctx context.Context, userName string) error
+	Subscriptions(ctx context.Context) ([]Subscription, error)
+	Subscribe(ctx context.Context, subscription Subscription) error
+	Unsubscribe(ctx context.Context, userName string) error
+	WorkoutSnapshot(ctx context.Context, userName string) (WorkoutSnapshot, error)
+	UpdateWorkoutSnapshot(ctx context.Context, userName string, snapshot WorkoutSnapshot) error
+	CalendarToken(ctx context.Context, userName string) (string, error)
+	UpdateCalendarToken(ctx context.Context, userName, token string) error
+	CalendarUserName(ctx context.Context, token string) (string, error)
+	// GoalRace returns the race pinned by the user with /races pin.
+	GoalRace(ctx context.Context, userName string) (Race, error)
+	UpdateGoalRace(ctx context.Context, userName string, race Race) error
+	DeleteGoalRace(ctx context.Context, userName string) error
+	// UpdateUserActivity records the last message of the user.
+	UpdateUserActivity(ctx context.Context, activity UserActivity) error
+	UserActivities(ctx context.Context) ([]UserActivity, error)
+	// AuthorizedUsers returns the number of users with at least one linked account.
+	AuthorizedUsers(ctx context.Context) (int, error)
+	// DeleteUser removes every row of the user from all tables.
+	DeleteUser(ctx context.Context, userName string) ([]DeletedRows, error)
+}
+
+// SettingsStore keeps preferences of users changed with /settings and commands such as /language.
+type SettingsStore interface {
+	// Settings returns ErrNotFound when the user has not changed any setting.
+	Settings(ctx context.Context, userName string) (Settings, error)
+	UpdateSettings(ctx context.Context, userName string, settings Settings) error
 }
 
 type FinalSurge interface {
@@ -46,42 +158,83 @@ type FinalSurge interface {
 
 type Clock interface {
 	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// UpdatesAPI is the part of the Telegram Bot API that delivers updates with a webhook or long polling.
+type UpdatesAPI interface {
+	SetWebhook(config tgbotapi.WebhookConfig) (tgbotapi.APIResponse, error)
+	GetWebhookInfo() (tgbotapi.WebhookInfo, error)
+	GetUpdatesChan(config tgbotapi.UpdateConfig) (tgbotapi.UpdatesChannel, error)
+	// MakeRequest calls deleteWebhook, which tgbotapi does not support.
+	MakeRequest(endpoint string, params url.Values) (tgbotapi.APIResponse, error)
 }
 
 //go:generate mockgen -source=$GOFILE -package mock -destination mock/interfaces_mock.go
 type Bot struct {
-	bot   Sender
-	db    Storage
-	fs    FinalSurge
-	clock Clock
+	bot     Sender
+	db      Storage
+	fs      FinalSurge
+	fsStats *FinalSurgeStats
+	clock   Clock
+	config  *Config
+
+	templates *TaskTemplates
+	commands  []Command
 
 	keyboard tgbotapi.ReplyKeyboardMarkup
 
-	userEmails map[string]string
+	loginDialog *Dialog
+	dialogs     map[string]*DialogSession
 }
 
-func NewBot(bot Sender, db Storage, fs FinalSurge, clock Clock) *Bot {
-	return &Bot{
-		bot:   bot,
-		db:    db,
-		fs:    fs,
-		clock: clock,
+func NewBot(bot Sender, db Storage, fs FinalSurge, clock Clock, config *Config, templates *TaskTemplates) *Bot {
+	fsStats := NewFinalSurgeStats(fs)
+
+	b := &Bot{
+		bot:     bot,
+		db:      db,
+		fs:      fsStats,
+		fsStats: fsStats,
+		clock:   clock,
+		config:  config,
+
+		templates: templates,
 
 		keyboard: tgbotapi.NewReplyKeyboard(tgbotapi.NewKeyboardButtonRow(
 			tgbotapi.NewKeyboardButton(KeyboardButtonTask),
 		)),
 
-		userEmails: make(map[string]string, Emails),
+		dialogs: make(map[string]*DialogSession, Emails),
 	}
+	b.commands = b.newCommands()
+	b.loginDialog = b.newLoginDialog()
+
+	return b
 }
 
 func (b *Bot) ProcessUpdate(ctx context.Context, update tgbotapi.Update) error {
+	if update.InlineQuery != nil {
+		return b.inlineQuery(ctx, update.InlineQuery)
+	}
+
+	if update.CallbackQuery != nil {
+		return b.callbackQuery(ctx, update.CallbackQuery)
+	}
+
 	if update.Message == nil {
 		return nil
 	}
 
+	b.trackActivity(ctx, update.Message)
+
 	msg, err := b.message(ctx, update.Message)
 	if err != nil {
+		// The user gets an answer even when the update fails.
+		if _, errSend := b.bot.Send(b.newErrorMsg(ctx, update.Message, err)); errSend != nil {
+			log.Printf("send error reply to chat %d: %v", update.Message.Chat.ID, errSend)
+		}
+
 		return fmt.Errorf("get message: %w", err)
 	}
 
@@ -89,8 +242,48 @@ func (b *Bot) ProcessUpdate(ctx context.Context, update tgbotapi.Update) error {
 		return nil
 	}
 
-	if _, err := b.bot.Send(*msg); err != nil {
-		return fmt.Errorf("send reply msg to chat %d: %w", msg.ChatID, err)
+	if _, err := b.bot.Send(msg); err != nil {
+		return fmt.Errorf("send reply msg to chat %d: %w", update.Message.Chat.ID, err)
+	}
+
+	return nil
+}
+
+// callbackQuery handles buttons of inline keyboards. Telegram shows a progress indicator on the button
+// until the query is answered, so it is answered even when the update fails.
+func (b *Bot) callbackQuery(ctx context.Context, query *tgbotapi.CallbackQuery) error {
+	loc, err := UserLocale(ctx, b.db, query.From.UserName, query.From.LanguageCode)
+	if err != nil {
+		return fmt.Errorf("get locale: %w", err)
+	}
+
+	var (
+		msg    tgbotapi.Chattable
+		notice string
+	)
+
+	// Buttons of inline messages have no message to edit.
+	if query.Message != nil && strings.HasPrefix(query.Data, settingsCallback) {
+		msg, notice, err = b.settingsCallbackQuery(ctx, query, loc)
+		if err != nil {
+			notice = loc.T(msgInternalError)
+		}
+	}
+
+	if _, errAnswer := b.bot.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, notice)); errAnswer != nil {
+		log.Printf("answer callback query %s: %v", query.ID, errAnswer)
+	}
+
+	if err != nil {
+		return fmt.Errorf("get callback query message: %w", err)
+	}
+
+	if msg == nil {
+		return nil
+	}
+
+	if _, err := b.bot.Send(msg); err != nil {
+		return fmt.Errorf("send edited msg to chat %d: %w", query.Message.Chat.ID, err)
 	}
 
 	return nil
@@ -100,128 +293,721 @@ func (b *Bot) Keyboard() tgbotapi.ReplyKeyboardMarkup {
 	return b.keyboard
 }
 
-func (b *Bot) message(ctx context.Context, message *tgbotapi.Message) (*tgbotapi.MessageConfig, error) {
+// FinalSurge returns the client that counts requests for /stats. Other FinalSurge users should share it.
+func (b *Bot) FinalSurge() FinalSurge {
+	return b.fs
+}
+
+func (b *Bot) message(ctx context.Context, message *tgbotapi.Message) (tgbotapi.Chattable, error) {
 	userName := message.From.UserName
 	chatID := message.Chat.ID
-	text := message.Text
 
-	if message.IsCommand() && message.Command() == CommandStart {
-		b.userEmails[userName] = ""
+	loc, err := UserLocale(ctx, b.db, userName, message.From.LanguageCode)
+	if err != nil {
+		return nil, fmt.Errorf("get locale: %w", err)
+	}
+
+	if name, ok := commandName(message); ok {
+		return b.command(ctx, message, loc, name)
+	}
+
+	msg, ok, err := b.continueDialog(ctx, message, loc)
+	if ok {
+		return msg, err
+	}
+
+	return b.newChooseOptionMsg(chatID, loc), nil
+}
+
+func (b *Bot) buttonTask(ctx context.Context, userName string, chatID int64, loc *Locale,
+) (tgbotapi.Chattable, error) {
+	userTokens, err := b.db.UserTokens(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgAuthorizeFirst))
+
+		return msg, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get usertokens: %w", err)
+	}
+
+	today, err := b.userToday(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := UserSettings(ctx, b.db, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	taskRange := PlanRange{Start: today, End: today.AddDate(0, 0, settings.Days()-1)}
+
+	var workouts []Workout
+
+	// Workouts after the shown days are requested only to find a race for the countdown.
+	for _, userToken := range userTokens {
+		accountWorkouts, err := b.fs.Workouts(ctx, userToken, today, today.AddDate(0, 0, countdownDays-1))
+		if err != nil {
+			return nil, fmt.Errorf("get workouts: %w", err)
+		}
+
+		workouts = append(workouts, accountWorkouts...)
+	}
+
+	goal, err := b.goalRace(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	view := NewPlanView(loc, workouts, taskRange, today)
+	if race, ok := CountdownRace(UpcomingRaces(workouts, goal, today), goal); ok {
+		view.Countdown = MessageCountdown(loc, race, today)
+	}
+
+	return b.newTaskMsg(ctx, userName, chatID, view)
+}
+
+func (b *Bot) newTaskMsg(ctx context.Context, userName string, chatID int64, view TaskView,
+) (tgbotapi.Chattable, error) {
+	task, parseMode, err := b.renderTask(ctx, userName, view)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(chatID, task)
+	msg.ParseMode = parseMode
+
+	return msg, nil
+}
+
+// renderTask renders the view with the template, the markup and the units chosen by the user.
+func (b *Bot) renderTask(ctx context.Context, userName string, view TaskView) (task, parseMode string, err error) {
+	settings, err := UserSettings(ctx, b.db, userName)
+	if err != nil {
+		return "", "", err
+	}
+
+	markup := MarkupFor(settings.Markup)
+
+	task, err = b.templates.Render(b.templates.Format(settings), markup, ConvertTaskView(view, settings.Units))
+	if err != nil {
+		return "", "", fmt.Errorf("render task: %w", err)
+	}
+
+	return task, markup.ParseMode(), nil
+}
+
+// userToday returns the current date in the time zone of the user.
+func (b *Bot) userToday(ctx context.Context, userName string) (time.Time, error) {
+	now := b.clock.Now()
+
+	zone, err := UserTimeZone(ctx, b.db, userName, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("get time zone: %w", err)
+	}
+
+	return NewDate(now.In(zone)), nil
+}
+
+func (b *Bot) commandPlan(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	today, err := b.userToday(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
 
-		msg := tgbotapi.NewMessage(chatID, "Enter FinalSurge email:")
+	planRange, err := ParsePlanArgs(args, today)
+	if errors.Is(err, ErrPlanArgs) {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgPlanUsage))
 
-		return &msg, nil
+		return msg, nil
 	}
 
-	if text == KeyboardButtonTask {
-		return b.buttonTask(ctx, userName, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("parse plan args: %w", err)
 	}
 
-	email, ok := b.userEmails[userName]
-	if !ok {
-		return b.newChooseOptionMsg(chatID), nil
+	userTokens, err := b.db.UserTokens(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgAuthorizeFirst))
+
+		return msg, nil
 	}
 
-	switch {
-	case email == "":
-		b.userEmails[userName] = text
+	if err != nil {
+		return nil, fmt.Errorf("get usertokens: %w", err)
+	}
 
-		msg := tgbotapi.NewMessage(chatID, "Enter FinalSurge password:")
+	var workouts []Workout
 
-		return &msg, nil
-	case email != "":
-		userToken, err := b.fs.Login(ctx, email, text)
+	for _, userToken := range userTokens {
+		accountWorkouts, err := b.fs.Workouts(ctx, userToken, planRange.Start, planRange.End)
 		if err != nil {
-			return nil, fmt.Errorf("login: %w", err)
+			return nil, fmt.Errorf("get workouts: %w", err)
 		}
 
-		if err := b.db.UpdateUserToken(ctx, userName, userToken); err != nil {
-			return nil, fmt.Errorf("update user token: %w", err)
+		workouts = append(workouts, accountWorkouts...)
+	}
+
+	return b.newTaskMsg(ctx, userName, chatID, NewPlanView(loc, workouts, planRange, today))
+}
+
+func (b *Bot) commandTimeZone(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	name := strings.TrimSpace(args)
+	if name == "" {
+		zone, err := UserTimeZone(ctx, b.db, userName, b.clock.Now())
+		if err != nil {
+			return nil, fmt.Errorf("get time zone: %w", err)
 		}
 
-		b.userEmails[userName] = ""
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgTimeZone, zone)+"\n"+loc.T(msgTimeZoneUsage))
 
-		return b.newChooseOptionMsg(chatID), nil
+		return msg, nil
 	}
 
-	return nil, nil
+	zone, err := time.LoadLocation(name)
+	if err != nil {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgTimeZoneUsage))
+
+		return msg, nil //nolint:nilerr // unknown zone is a user input error
+	}
+
+	if err := b.updateSettings(ctx, userName, func(s *Settings) { s.TimeZone = zone.String() }); err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(chatID, loc.T(msgTimeZone, zone))
+
+	return msg, nil
 }
 
-func (b *Bot) buttonTask(ctx context.Context, userName string, chatID int64) (*tgbotapi.MessageConfig, error) {
+func (b *Bot) commandStart(message *tgbotapi.Message, loc *Locale) (tgbotapi.Chattable, error) {
+	label := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+	if label == "" {
+		label = DefaultAccountLabel
+	}
+
+	if !IsValidAccountLabel(label) {
+		msg := tgbotapi.NewMessage(message.Chat.ID, loc.T(msgInvalidAccountLabel))
+
+		return msg, nil
+	}
+
+	return b.startDialog(b.loginDialog, message, loc, map[string]string{loginParamLabel: label}), nil
+}
+
+func (b *Bot) commandSubscribe(ctx context.Context, userName string, chatID int64, loc *Locale,
+) (tgbotapi.Chattable, error) {
+	_, err := b.db.UserToken(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgAuthorizeFirst))
+
+		return msg, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get usertoken: %w", err)
+	}
+
+	if err := b.db.Subscribe(ctx, Subscription{UserName: userName, ChatID: chatID}); err != nil {
+		return nil, fmt.Errorf("subscribe: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, loc.T(msgSubscribed))
+
+	return msg, nil
+}
+
+func (b *Bot) commandUnsubscribe(ctx context.Context, userName string, chatID int64, loc *Locale,
+) (tgbotapi.Chattable, error) {
+	if err := b.db.Unsubscribe(ctx, userName); err != nil {
+		return nil, fmt.Errorf("unsubscribe: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, loc.T(msgUnsubscribed))
+
+	return msg, nil
+}
+
+func (b *Bot) commandCalendar(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	_, err := b.db.UserToken(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgAuthorizeFirst))
+
+		return msg, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get usertoken: %w", err)
+	}
+
+	token, err := b.db.CalendarToken(ctx, userName)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("get calendar token: %w", err)
+	}
+
+	if errors.Is(err, ErrNotFound) || strings.TrimSpace(args) == CalendarArgReset {
+		if token, err = NewCalendarToken(); err != nil {
+			return nil, fmt.Errorf("new calendar token: %w", err)
+		}
+
+		if err := b.db.UpdateCalendarToken(ctx, userName, token); err != nil {
+			return nil, fmt.Errorf("update calendar token: %w", err)
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, loc.T(msgCalendarLink, CalendarURL(b.config.PublicURL, token)))
+
+	return msg, nil
+}
+
+func (b *Bot) commandExport(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	exportRange, err := ParseExportArgs(args)
+	if errors.Is(err, ErrExportArgs) {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgExportUsage))
+
+		return msg, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parse export args: %w", err)
+	}
+
 	userToken, err := b.db.UserToken(ctx, userName)
 	if errors.Is(err, ErrNotFound) {
-		msg := tgbotapi.NewMessage(chatID, "Please authorize first by entering /start")
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgAuthorizeFirst))
 
-		return &msg, nil
+		return msg, nil
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("get usertoken: %w", err)
 	}
 
-	today := NewDate(b.clock.Now())
-	tomorrow := today.AddDate(0, 0, 1)
+	workouts, err := ExportWorkouts(ctx, b.fs, userToken, exportRange)
+	if err != nil {
+		return nil, fmt.Errorf("export workouts: %w", err)
+	}
 
-	workouts, err := b.fs.Workouts(context.Background(), userToken, today, tomorrow)
+	bs, err := MarshalWorkouts(workouts, exportRange.Format)
 	if err != nil {
-		return nil, fmt.Errorf("get workouts: %w", err)
+		return nil, fmt.Errorf("marshal workouts: %w", err)
 	}
 
-	task := MessageTask(workouts, today, tomorrow)
+	return tgbotapi.NewDocumentUpload(chatID, tgbotapi.FileBytes{
+		Name:  exportRange.FileName(),
+		Bytes: bs,
+	}), nil
+}
 
-	msg := tgbotapi.NewMessage(chatID, task)
+func (b *Bot) commandChart(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	today, err := b.userToday(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	chartRange, err := ParseChartArgs(args, today)
+	if errors.Is(err, ErrChartArgs) {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgChartUsage))
+
+		return msg, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parse chart args: %w", err)
+	}
+
+	userTokens, err := b.db.UserTokens(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgAuthorizeFirst))
+
+		return msg, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get usertokens: %w", err)
+	}
+
+	var workouts []Workout
 
-	return &msg, nil
+	for _, userToken := range userTokens {
+		accountWorkouts, err := ExportWorkouts(ctx, b.fs, userToken, ExportRange{
+			Start: chartRange.Start,
+			End:   chartRange.End,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get workouts: %w", err)
+		}
+
+		workouts = append(workouts, accountWorkouts...)
+	}
+
+	bs, err := RenderChart(ChartBars(loc, workouts, chartRange))
+	if err != nil {
+		return nil, fmt.Errorf("render chart: %w", err)
+	}
+
+	caption := msgChartPerDay
+	if chartRange.PerWeek {
+		caption = msgChartPerWeek
+	}
+
+	photo := tgbotapi.NewPhotoUpload(chatID, tgbotapi.FileBytes{Name: "chart.png", Bytes: bs})
+	photo.Caption = loc.T(caption, loc.Date(chartRange.Start), loc.Date(chartRange.End))
+
+	return photo, nil
 }
 
-func MessageTask(workouts []Workout, today, tomorrow time.Time) string {
-	todayDescriptions := make([]string, 0, len(workouts))
-	tomorrowDescriptions := make([]string, 0, len(workouts))
+func (b *Bot) commandRaces(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	today, err := b.userToday(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	action, actionArgs, _ := strings.Cut(strings.TrimSpace(args), " ")
 
-	for _, w := range workouts {
-		switch {
-		case w.Date.Equal(today):
-			todayDescriptions = append(todayDescriptions, w.Description)
-		case w.Date.Equal(tomorrow):
-			tomorrowDescriptions = append(tomorrowDescriptions, w.Description)
-		default:
+	switch strings.ToLower(action) {
+	case "":
+	case RaceArgPin:
+		race, err := ParseGoalRace(actionArgs, today)
+		if errors.Is(err, ErrRaceArgs) {
+			msg := tgbotapi.NewMessage(chatID, loc.T(msgRacesUsage))
+
+			return msg, nil
 		}
+
+		if err != nil {
+			return nil, fmt.Errorf("parse goal race: %w", err)
+		}
+
+		if err := b.db.UpdateGoalRace(ctx, userName, race); err != nil {
+			return nil, fmt.Errorf("update goal race: %w", err)
+		}
+
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgGoalRacePinned, loc.Date(race.Date), race.Name))
+
+		return msg, nil
+	case RaceArgUnpin:
+		if err := b.db.DeleteGoalRace(ctx, userName); err != nil {
+			return nil, fmt.Errorf("delete goal race: %w", err)
+		}
+
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgGoalRaceUnpinned))
+
+		return msg, nil
+	default:
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgRacesUsage))
+
+		return msg, nil
 	}
 
-	task := strings.Builder{}
-	task.WriteString("Tasks:")
-	task.WriteByte('\n')
+	userTokens, err := b.db.UserTokens(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgAuthorizeFirst))
 
-	writeDescriptions := func(day string, date time.Time, descriptions []string) {
-		task.WriteString(day)
-		task.WriteByte(' ')
-		task.WriteString(date.Format("02.01"))
-		task.WriteByte(':')
-		task.WriteByte('\n')
+		return msg, nil
+	}
 
-		if len(descriptions) != 0 {
-			for _, d := range descriptions {
-				task.WriteString(d)
-				task.WriteByte('\n')
-			}
-		} else {
-			task.WriteString("not set")
-			task.WriteByte('\n')
+	if err != nil {
+		return nil, fmt.Errorf("get usertokens: %w", err)
+	}
+
+	var workouts []Workout
+
+	for _, userToken := range userTokens {
+		accountWorkouts, err := ExportWorkouts(ctx, b.fs, userToken, ExportRange{
+			Start: today,
+			End:   today.AddDate(0, 0, racesDays-1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("get workouts: %w", err)
 		}
+
+		workouts = append(workouts, accountWorkouts...)
+	}
+
+	goal, err := b.goalRace(ctx, userName)
+	if err != nil {
+		return nil, err
 	}
 
-	writeDescriptions("Today", today, todayDescriptions)
-	task.WriteByte('\n')
-	writeDescriptions("Tomorrow", tomorrow, tomorrowDescriptions)
+	msg := tgbotapi.NewMessage(chatID, MessageRaces(loc, UpcomingRaces(workouts, goal, today), goal, today))
 
-	return task.String()
+	return msg, nil
 }
 
-func (b *Bot) newChooseOptionMsg(chatID int64) *tgbotapi.MessageConfig {
-	msg := tgbotapi.NewMessage(chatID, "Choose option:")
+// goalRace returns nil if the user has not pinned a goal race.
+func (b *Bot) goalRace(ctx context.Context, userName string) (*Race, error) {
+	goal, err := b.db.GoalRace(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get goal race: %w", err)
+	}
+
+	return &goal, nil
+}
+
+func (b *Bot) commandAccounts(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	var action, label string
+	if fields := strings.Fields(strings.ToLower(args)); len(fields) > 0 {
+		action = fields[0]
+		if len(fields) > 1 {
+			label = fields[1]
+		}
+	}
+
+	if (action == AccountArgUse || action == AccountArgUnlink) && label == "" {
+		msg := tgbotapi.NewMessage(chatID, MessageAccountsUsage(loc))
+
+		return msg, nil
+	}
+
+	var (
+		err  error
+		text string
+	)
+
+	switch action {
+	case "":
+	case AccountArgUse:
+		err = b.db.ActivateAccount(ctx, userName, label)
+		text = loc.T(msgSwitchedAccount, label)
+	case AccountArgAll:
+		err = b.db.ActivateAllAccounts(ctx, userName)
+		text = loc.T(msgMergedAccounts)
+	case AccountArgUnlink:
+		err = b.db.DeleteAccount(ctx, userName, label)
+		text = loc.T(msgUnlinkedAccount, label)
+	default:
+		msg := tgbotapi.NewMessage(chatID, MessageAccountsUsage(loc))
+
+		return msg, nil
+	}
+
+	if errors.Is(err, ErrNotFound) {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgAccountNotFound, label))
+
+		return msg, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s account: %w", action, err)
+	}
+
+	accounts, err := b.db.Accounts(ctx, userName)
+	if err != nil {
+		return nil, fmt.Errorf("get accounts: %w", err)
+	}
+
+	reply := MessageAccounts(loc, accounts)
+	if text != "" {
+		reply = text + "\n\n" + reply
+	}
+
+	msg := tgbotapi.NewMessage(chatID, reply)
+
+	return msg, nil
+}
+
+func MessageAccounts(loc *Locale, accounts []Account) string {
+	if len(accounts) == 0 {
+		return loc.T(msgNoAccounts)
+	}
+
+	msg := strings.Builder{}
+	msg.WriteString(loc.T(msgAccounts))
+	msg.WriteByte('\n')
+
+	for _, a := range accounts {
+		msg.WriteString(a.Label)
+
+		if a.Active {
+			msg.WriteString(loc.T(msgActiveAccount))
+		}
+
+		msg.WriteByte('\n')
+	}
+
+	msg.WriteByte('\n')
+	msg.WriteString(MessageAccountsUsage(loc))
+
+	return msg.String()
+}
+
+func MessageAccountsUsage(loc *Locale) string {
+	return loc.T(msgAccountsUsage)
+}
+
+func IsValidAccountLabel(label string) bool {
+	const maxLabelLen = 20
+
+	if label == "" || len(label) > maxLabelLen {
+		return false
+	}
+
+	for _, r := range label {
+		if !('a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b *Bot) commandLogout(ctx context.Context, userName string, chatID int64, loc *Locale,
+) (tgbotapi.Chattable, error) {
+	b.forgetDialog(userName)
+
+	if err := b.db.DeleteAccounts(ctx, userName); err != nil {
+		return nil, fmt.Errorf("delete accounts: %w", err)
+	}
+
+	if err := b.db.Unsubscribe(ctx, userName); err != nil {
+		return nil, fmt.Errorf("unsubscribe: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, loc.T(msgLoggedOut))
+
+	return msg, nil
+}
+
+func (b *Bot) commandForgetMe(ctx context.Context, userName string, chatID int64, loc *Locale,
+) (tgbotapi.Chattable, error) {
+	b.forgetDialog(userName)
+
+	deleted, err := b.db.DeleteUser(ctx, userName)
+	if err != nil {
+		return nil, fmt.Errorf("delete user: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, MessageDeleted(loc, deleted))
+
+	return msg, nil
+}
+
+func MessageDeleted(loc *Locale, deleted []DeletedRows) string {
+	msg := strings.Builder{}
+	msg.WriteString(loc.T(msgDataDeleted))
+	msg.WriteByte('\n')
+
+	for _, d := range deleted {
+		if d.Rows == 0 {
+			continue
+		}
+
+		msg.WriteString(loc.T(msgTablePrefix + d.Table))
+		msg.WriteString(": ")
+		msg.WriteString(strconv.FormatInt(d.Rows, 10))
+		msg.WriteByte('\n')
+	}
+
+	msg.WriteByte('\n')
+	msg.WriteString(loc.T(msgNothingStored))
+
+	return msg.String()
+}
+
+func (b *Bot) commandLanguage(ctx context.Context, message *tgbotapi.Message, loc *Locale,
+) (tgbotapi.Chattable, error) {
+	chatID := message.Chat.ID
+	language := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+
+	if language == "" {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgLanguage, loc.Name)+"\n"+loc.T(msgLanguageUsage))
+
+		return msg, nil
+	}
+
+	if language != LanguageAuto && !IsSupportedLanguage(language) {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgLanguageUsage))
+
+		return msg, nil
+	}
+
+	if err := b.updateSettings(ctx, message.From.UserName, func(s *Settings) { s.Language = language }); err != nil {
+		return nil, err
+	}
+
+	loc = Settings{Language: language}.Locale(message.From.LanguageCode)
+
+	msg := tgbotapi.NewMessage(chatID, loc.T(msgLanguage, loc.Name))
+
+	return msg, nil
+}
+
+func (b *Bot) commandMarkup(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	name := strings.ToLower(strings.TrimSpace(args))
+	if name != MarkupHTML && name != MarkupPlain {
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgMarkupUsage))
+
+		return msg, nil
+	}
+
+	if err := b.updateSettings(ctx, userName, func(s *Settings) { s.Markup = name }); err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(chatID, loc.T(msgMarkup, name))
+
+	return msg, nil
+}
+
+func (b *Bot) commandFormat(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	name := strings.ToLower(strings.TrimSpace(args))
+	if !b.templates.Has(name) {
+		format, err := b.templates.UserTaskFormat(ctx, b.db, userName)
+		if err != nil {
+			return nil, fmt.Errorf("get task format: %w", err)
+		}
+
+		msg := tgbotapi.NewMessage(chatID, loc.T(msgFormat, format)+"\n"+
+			loc.T(msgFormatUsage, strings.Join(b.templates.Names(), "|")))
+
+		return msg, nil
+	}
+
+	if err := b.updateSettings(ctx, userName, func(s *Settings) { s.TaskFormat = name }); err != nil {
+		return nil, err
+	}
+
+	msg := tgbotapi.NewMessage(chatID, loc.T(msgFormat, name))
+
+	return msg, nil
+}
+
+func (b *Bot) newChooseOptionMsg(chatID int64, loc *Locale) tgbotapi.MessageConfig {
+	msg := tgbotapi.NewMessage(chatID, loc.T(msgChooseOption))
 	msg.ReplyMarkup = b.keyboard
 
-	return &msg
+	return msg
+}
+
+// newErrorMsg tells the user that the update failed. Details stay in the log.
+func (b *Bot) newErrorMsg(ctx context.Context, message *tgbotapi.Message, err error) tgbotapi.MessageConfig {
+	loc, errLocale := UserLocale(ctx, b.db, message.From.UserName, message.From.LanguageCode)
+	if errLocale != nil {
+		loc = LocaleFor(message.From.LanguageCode)
+	}
+
+	var fsErr *FinalSurgeError
+	if errors.As(err, &fsErr) {
+		return tgbotapi.NewMessage(message.Chat.ID, loc.T(msgFinalSurgeError, fsErr.Description))
+	}
+
+	return tgbotapi.NewMessage(message.Chat.ID, loc.T(msgInternalError))
 }
 
 func NewDate(t time.Time) time.Time {
@@ -18,9 +18,11 @@ func TestBot_ProcessUpdate(t *testing.T) {
 		senderMock := mock.NewMockSender(ctrl)
 		fsMock := mock.NewMockFinalSurge(ctrl)
 		storageMock := mock.NewMockStorage(ctrl)
-		bot := NewBot(senderMock, storageMock, fsMock, nil)
+		bot := NewBot(senderMock, storageMock, fsMock, nil, &Config{}, NewTaskTemplates())
 		const userName = "alexandear"
 		const chatID = int64(20)
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 		const startCommand = "/start@final_surge_bot"
 		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
@@ -59,7 +61,7 @@ func TestBot_ProcessUpdate(t *testing.T) {
 			Token:   "7f2a5f06-1b20-4dde-ba31-2c0a33be6b69",
 		}
 		fsMock.EXPECT().Login(gomock.Any(), email, password).Return(userToken, nil).Times(1)
-		storageMock.EXPECT().UpdateUserToken(gomock.Any(), userName, userToken).Return(nil).Times(1)
+		storageMock.EXPECT().UpdateUserToken(gomock.Any(), userName, DefaultAccountLabel, userToken).Return(nil).Times(1)
 		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
 			BaseChat: tgbotapi.BaseChat{
 				ChatID:      chatID,
@@ -84,11 +86,13 @@ func TestBot_ProcessUpdate(t *testing.T) {
 		senderMock := mock.NewMockSender(ctrl)
 		fsMock := mock.NewMockFinalSurge(ctrl)
 		storageMock := mock.NewMockStorage(ctrl)
-		bot := NewBot(senderMock, storageMock, fsMock, nil)
+		bot := NewBot(senderMock, storageMock, fsMock, nil, &Config{}, NewTaskTemplates())
 		const userName = "alexandear"
 		const chatID = int64(20)
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-		storageMock.EXPECT().UserToken(gomock.Any(), userName).Return(UserToken{}, ErrNotFound).Times(1)
+		storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return(nil, ErrNotFound).Times(1)
 		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
 			BaseChat: tgbotapi.BaseChat{
 				ChatID: chatID,
@@ -113,9 +117,11 @@ func TestBot_ProcessUpdate(t *testing.T) {
 		fsMock := mock.NewMockFinalSurge(ctrl)
 		storageMock := mock.NewMockStorage(ctrl)
 		clockMock := mock.NewMockClock(ctrl)
-		bot := NewBot(senderMock, storageMock, fsMock, clockMock)
+		bot := NewBot(senderMock, storageMock, fsMock, clockMock, &Config{}, NewTaskTemplates())
 		const userName = "alexandear"
 		const chatID = int64(20)
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 		userToken := UserToken{
 			UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
@@ -124,23 +130,86 @@ func TestBot_ProcessUpdate(t *testing.T) {
 		now := time.Date(2020, time.December, 20, 15, 15, 20, 0, time.UTC)
 		today := time.Date(2020, time.December, 20, 0, 0, 0, 0, time.UTC)
 		clockMock.EXPECT().Now().Return(now).Times(1)
-		storageMock.EXPECT().UserToken(gomock.Any(), userName).Return(userToken, nil).Times(1)
+		storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return([]UserToken{userToken}, nil).Times(1)
 		fsMock.EXPECT().Workouts(gomock.Any(), userToken,
-			today, time.Date(2020, time.December, 21, 0, 0, 0, 0, time.UTC)).
+			today, time.Date(2021, time.January, 16, 0, 0, 0, 0, time.UTC)).
 			Return([]Workout{
 				{
 					Date:        today,
-					Description: "10 km",
+					Description: "10 km <easy>",
+					Activity:    "Run",
+				},
+				{
+					Date:     time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC),
+					Name:     "New Year 5K",
+					Activity: "Race",
+					Race:     true,
 				},
 			}, nil).Times(1)
+		storageMock.EXPECT().GoalRace(gomock.Any(), userName).Return(Race{}, ErrNotFound).Times(1)
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text: `Race in 12 days: New Year 5K
+<b>Tasks:</b>
+<b>Today 20.12:</b>
+🏃 10 km &lt;easy&gt;
+
+<b>Tomorrow 21.12:</b>
+not set
+`,
+			ParseMode: tgbotapi.ModeHTML,
+		}).Times(1)
+		if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+			Message: &tgbotapi.Message{
+				Chat: &tgbotapi.Chat{ID: chatID},
+				From: &tgbotapi.User{UserName: userName},
+				Text: "/task",
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("button task merges accounts", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		fsMock := mock.NewMockFinalSurge(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		clockMock := mock.NewMockClock(ctrl)
+		bot := NewBot(senderMock, storageMock, fsMock, clockMock, &Config{}, NewTaskTemplates())
+		const userName = "alexandear"
+		const chatID = int64(20)
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{Markup: MarkupPlain}, nil).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		personal := UserToken{
+			UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+			Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+		}
+		club := UserToken{
+			UserKey: "b0d1c67e-0d8c-4b67-8faa-c02104ec4f72",
+			Token:   "7f2a5f06-1b20-4dde-ba31-2c0a33be6b69",
+		}
+		now := time.Date(2020, time.December, 20, 15, 15, 20, 0, time.UTC)
+		today := time.Date(2020, time.December, 20, 0, 0, 0, 0, time.UTC)
+		tomorrow := time.Date(2020, time.December, 21, 0, 0, 0, 0, time.UTC)
+		end := time.Date(2021, time.January, 16, 0, 0, 0, 0, time.UTC)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return([]UserToken{personal, club}, nil).Times(1)
+		fsMock.EXPECT().Workouts(gomock.Any(), personal, today, end).
+			Return([]Workout{{Date: tomorrow, Description: "Rest Day", RestDay: true}}, nil).Times(1)
+		fsMock.EXPECT().Workouts(gomock.Any(), club, today, end).
+			Return([]Workout{{Date: today, Description: "Club intervals"}}, nil).Times(1)
+		storageMock.EXPECT().GoalRace(gomock.Any(), userName).Return(Race{}, ErrNotFound).Times(1)
 		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
 			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
 			Text: `Tasks:
 Today 20.12:
-10 km
+Club intervals
 
 Tomorrow 21.12:
-not set
+😴 Rest Day
 `,
 		}).Times(1)
 		if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
@@ -153,91 +222,255 @@ not set
 			t.Fatal(err)
 		}
 	})
-}
 
-func TestBot_MessageTask(t *testing.T) {
-	today := time.Date(2020, time.December, 23, 0, 0, 0, 0, time.UTC)
-	tomorrow := time.Date(2020, time.December, 24, 0, 0, 0, 0, time.UTC)
-	for name, tc := range map[string]struct {
-		data     []Workout
-		expected string
-	}{
-		"today and tomorrow": {
-			data: []Workout{
-				{
-					Date:        today,
-					Description: "Warm-up",
-				},
-				{
-					Date:        today,
-					Description: "6 km",
-				},
-				{
-					Date:        tomorrow,
-					Description: "12 km",
-				},
+	t.Run("accounts use", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		bot := NewBot(senderMock, storageMock, nil, nil, &Config{}, NewTaskTemplates())
+		const userName = "alexandear"
+		const chatID = int64(20)
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		storageMock.EXPECT().ActivateAccount(gomock.Any(), userName, "club").Return(nil).Times(1)
+		storageMock.EXPECT().Accounts(gomock.Any(), userName).Return([]Account{
+			{Label: "default"},
+			{Label: "club", Active: true},
+		}, nil).Times(1)
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text: `Switched to account club
+
+Accounts:
+default
+club (active)
+
+` + MessageAccountsUsage(LocaleFor(LanguageEnglish)),
+		}).Times(1)
+
+		const command = "/accounts use club"
+		if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+			Message: &tgbotapi.Message{
+				Chat:     &tgbotapi.Chat{ID: chatID},
+				From:     &tgbotapi.User{UserName: userName},
+				Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/accounts")}},
+				Text:     command,
 			},
-			expected: `Tasks:
-Today 23.12:
-Warm-up
-6 km
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
 
-Tomorrow 24.12:
-12 km
-`,
-		},
-		"today not set": {
-			data: []Workout{
-				{
-					Date:        tomorrow,
-					Description: "12 km",
-				},
+	t.Run("language", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		bot := NewBot(senderMock, storageMock, nil, nil, &Config{}, NewTaskTemplates())
+		const userName = "alexandear"
+		const chatID = int64(20)
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		storageMock.EXPECT().UpdateSettings(gomock.Any(), userName, Settings{Language: LanguageGerman}).
+			Return(nil).Times(1)
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text:     "Sprache: Deutsch",
+		}).Times(1)
+
+		const command = "/language de"
+		if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+			Message: &tgbotapi.Message{
+				Chat:     &tgbotapi.Chat{ID: chatID},
+				From:     &tgbotapi.User{UserName: userName, LanguageCode: "uk"},
+				Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/language")}},
+				Text:     command,
 			},
-			expected: `Tasks:
-Today 23.12:
-not set
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
 
-Tomorrow 24.12:
-12 km
-`,
-		},
-		"tomorrow not set": {
-			data: []Workout{
-				{
-					Date:        today,
-					Description: "Warm-up",
+	t.Run("format", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		bot := NewBot(senderMock, storageMock, nil, nil, &Config{}, NewTaskTemplates())
+		const userName = "alexandear"
+		const chatID = int64(20)
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text:     "Task format: full\nUsage: /format compact|full|today",
+		}).Times(1)
+		storageMock.EXPECT().UpdateSettings(gomock.Any(), userName, Settings{TaskFormat: TaskFormatCompact}).
+			Return(nil).Times(1)
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text:     "Task format: compact",
+		}).Times(1)
+
+		for _, command := range []string{"/format", "/format compact"} {
+			if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+				Message: &tgbotapi.Message{
+					Chat:     &tgbotapi.Chat{ID: chatID},
+					From:     &tgbotapi.User{UserName: userName},
+					Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/format")}},
+					Text:     command,
 				},
-				{
-					Date:        today,
-					Description: "6 km",
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+
+	t.Run("language detected from client", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		bot := NewBot(senderMock, storageMock, nil, nil, &Config{}, NewTaskTemplates())
+		const userName = "alexandear"
+		const chatID = int64(20)
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return(nil, ErrNotFound).Times(1)
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text:     "Bitte melde dich zuerst mit /start an",
+		}).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+			Message: &tgbotapi.Message{
+				Chat: &tgbotapi.Chat{ID: chatID},
+				From: &tgbotapi.User{UserName: userName, LanguageCode: "de-AT"},
+				Text: "/task",
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("forget me", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		bot := NewBot(senderMock, storageMock, nil, nil, &Config{}, NewTaskTemplates())
+		const userName = "alexandear"
+		const chatID = int64(20)
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+		command := func(text string) tgbotapi.Update {
+			return tgbotapi.Update{
+				Message: &tgbotapi.Message{
+					Chat:     &tgbotapi.Chat{ID: chatID},
+					From:     &tgbotapi.User{UserName: userName},
+					Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(text)}},
+					Text:     text,
 				},
+			}
+		}
+
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text:     "Enter FinalSurge email:",
+		}).Times(1)
+		if err := bot.ProcessUpdate(context.Background(), command("/start")); err != nil {
+			t.Fatal(err)
+		}
+
+		storageMock.EXPECT().DeleteUser(gomock.Any(), userName).Return([]DeletedRows{
+			{Table: "accounts", Rows: 2},
+			{Table: "subscriptions", Rows: 0},
+			{Table: "calendar_tokens", Rows: 1},
+		}, nil).Times(1)
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text: `All your data is deleted:
+accounts: 2
+calendar tokens: 1
+
+The bot no longer stores anything about you.`,
+		}).Times(1)
+		if err := bot.ProcessUpdate(context.Background(), command("/forgetme")); err != nil {
+			t.Fatal(err)
+		}
+
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{
+				ChatID:      chatID,
+				ReplyMarkup: bot.Keyboard(),
+			},
+			Text: "Choose option:",
+		}).Times(1)
+		if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+			Message: &tgbotapi.Message{
+				Chat: &tgbotapi.Chat{ID: chatID},
+				From: &tgbotapi.User{UserName: userName},
+				Text: "user@example.com",
 			},
-			expected: `Tasks:
-Today 23.12:
-Warm-up
-6 km
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
 
-Tomorrow 24.12:
-not set
-`,
-		},
-		"today and tomorrow not set": {
-			data: []Workout{},
-			expected: `Tasks:
-Today 23.12:
-not set
+const (
+	testUserName = "alexandear"
+	testChatID   = int64(20)
+)
 
-Tomorrow 24.12:
-not set
-`,
-		},
-	} {
-		t.Run(name, func(t *testing.T) {
-			actual := MessageTask(tc.data, today, tomorrow)
+// testBot is a bot with mocked dependencies for tests of conversations with testUserName.
+type testBot struct {
+	*Bot
+	sender  *mock.MockSender
+	fs      *mock.MockFinalSurge
+	storage *mock.MockStorage
+	clock   *mock.MockClock
+}
 
-			if actual != tc.expected {
-				t.Errorf("actual=%s, expected=%s", actual, tc.expected)
-			}
-		})
+// newTestBot returns the bot that reads the settings for testUserName and records any activity.
+func newTestBot(t *testing.T, config *Config, settings Settings) *testBot {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	b := &testBot{
+		sender:  mock.NewMockSender(ctrl),
+		fs:      mock.NewMockFinalSurge(ctrl),
+		storage: mock.NewMockStorage(ctrl),
+		clock:   mock.NewMockClock(ctrl),
+	}
+	b.Bot = NewBot(b.sender, b.storage, b.fs, b.clock, config, NewTaskTemplates())
+	b.storage.EXPECT().Settings(gomock.Any(), testUserName).Return(settings, nil).AnyTimes()
+	b.storage.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return b
+}
+
+// expectReply expects the text sent to testChatID without a keyboard.
+func (b *testBot) expectReply(text string) {
+	b.sender.EXPECT().Send(tgbotapi.MessageConfig{
+		BaseChat: tgbotapi.BaseChat{ChatID: testChatID},
+		Text:     text,
+	}).Times(1)
+}
+
+// process processes the updates in order.
+func (b *testBot) process(t *testing.T, updates ...tgbotapi.Update) {
+	t.Helper()
+
+	for _, u := range updates {
+		if err := b.ProcessUpdate(context.Background(), u); err != nil {
+			t.Fatal(err)
+		}
 	}
 }
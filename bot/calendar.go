@@ -0,0 +1,202 @@
+package bot
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	CalendarPath     = "/calendar/"
+	CalendarArgReset = "reset"
+
+	calendarExt        = ".ics"
+	calendarTokenBytes = 16
+	calendarPastDays   = 7
+	calendarLineLen    = 75
+)
+
+type Calendar struct {
+	db    Storage
+	fs    FinalSurge
+	clock Clock
+
+	days int
+}
+
+func NewCalendar(db Storage, fs FinalSurge, clock Clock, days int) *Calendar {
+	return &Calendar{
+		db:    db,
+		fs:    fs,
+		clock: clock,
+
+		days: days,
+	}
+}
+
+// ServeHTTP serves the iCalendar feed of the user found by the secret token from the URL path.
+func (c *Calendar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	name := path.Base(r.URL.Path)
+	if !strings.HasSuffix(name, calendarExt) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	feed, err := c.feed(r.Context(), strings.TrimSuffix(name, calendarExt))
+	if errors.Is(err, ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+
+		return
+	}
+
+	if err != nil {
+		log.Printf("get calendar feed: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := w.Write([]byte(feed)); err != nil {
+		log.Printf("write calendar feed: %v", err)
+	}
+}
+
+func (c *Calendar) feed(ctx context.Context, token string) (string, error) {
+	userName, err := c.db.CalendarUserName(ctx, token)
+	if err != nil {
+		return "", fmt.Errorf("get calendar user name: %w", err)
+	}
+
+	userToken, err := c.db.UserToken(ctx, userName)
+	if err != nil {
+		return "", fmt.Errorf("get usertoken: %w", err)
+	}
+
+	now := c.clock.Now()
+	today := NewDate(now)
+
+	workouts, err := c.fs.Workouts(ctx, userToken, today.AddDate(0, 0, -calendarPastDays), today.AddDate(0, 0, c.days))
+	if err != nil {
+		return "", fmt.Errorf("get workouts: %w", err)
+	}
+
+	return ICalendar(workouts, token, now), nil
+}
+
+func NewCalendarToken() (string, error) {
+	b := make([]byte, calendarTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("read random: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func CalendarURL(publicURL, token string) string {
+	return strings.TrimSuffix(publicURL, "/") + CalendarPath + token + calendarExt
+}
+
+// ICalendar renders workouts as all-day events of an RFC 5545 calendar of the feed with the token.
+func ICalendar(workouts []Workout, token string, now time.Time) string {
+	cal := strings.Builder{}
+
+	writeLine := func(line string) {
+		// Lines longer than 75 octets are folded by inserting CRLF followed by a space.
+		for limit := calendarLineLen; len(line) > limit; limit = calendarLineLen - 1 {
+			cut := limit
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+
+			cal.WriteString(line[:cut])
+			cal.WriteString("\r\n ")
+			line = line[cut:]
+		}
+
+		cal.WriteString(line)
+		cal.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//final-surge-bot//EN")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("METHOD:PUBLISH")
+	writeLine("X-WR-CALNAME:FinalSurge")
+
+	indexes := make(map[string]int, len(workouts))
+
+	for _, w := range workouts {
+		key := calendarWorkoutKey(w)
+		index := indexes[key]
+		indexes[key]++
+
+		summary, _, _ := strings.Cut(strings.TrimSpace(w.Description), "\n")
+		if summary == "" {
+			summary = "Workout"
+		}
+
+		writeLine("BEGIN:VEVENT")
+		writeLine("UID:" + calendarUID(token, key, index) + "@final-surge-bot")
+		writeLine("DTSTAMP:" + now.UTC().Format("20060102T150405Z"))
+		writeLine("DTSTART;VALUE=DATE:" + w.Date.Format("20060102"))
+		writeLine("DTEND;VALUE=DATE:" + w.Date.AddDate(0, 0, 1).Format("20060102"))
+		writeLine("SUMMARY:" + icalText(summary))
+
+		if w.RestDay {
+			writeLine("TRANSP:TRANSPARENT")
+		} else {
+			writeLine("DESCRIPTION:" + icalText(w.Description))
+		}
+
+		writeLine("END:VEVENT")
+	}
+
+	writeLine("END:VCALENDAR")
+
+	return cal.String()
+}
+
+// calendarWorkoutKey identifies the workout in the feed while its description is edited.
+// FinalSurge returns no workout IDs, so workouts of a day are told apart by the activity and the name.
+func calendarWorkoutKey(w Workout) string {
+	return w.Date.Format("20060102") + "\n" + w.Activity + "\n" + w.Name
+}
+
+// calendarUID is the event UID that stays the same between feed requests. It is a hash with the token,
+// so events of different feeds never share UIDs and the UID does not reveal the user.
+func calendarUID(token, key string, index int) string {
+	sum := sha256.Sum256([]byte(token + "\n" + key + "\n" + strconv.Itoa(index)))
+
+	return hex.EncodeToString(sum[:16])
+}
+
+func icalText(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(s)
+}
@@ -0,0 +1,158 @@
+package bot_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	"github.com/golang/mock/gomock"
+)
+
+func TestCalendar_ServeHTTP(t *testing.T) {
+	const userName = "alexandear"
+	const token = "0123456789abcdef0123456789abcdef"
+
+	t.Run("feed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		fsMock := mock.NewMockFinalSurge(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		clockMock := mock.NewMockClock(ctrl)
+		calendar := NewCalendar(storageMock, fsMock, clockMock, 14)
+
+		userToken := UserToken{
+			UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+			Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+		}
+		now := time.Date(2020, time.December, 20, 15, 15, 20, 0, time.UTC)
+		today := time.Date(2020, time.December, 20, 0, 0, 0, 0, time.UTC)
+		storageMock.EXPECT().CalendarUserName(gomock.Any(), token).Return(userName, nil).Times(1)
+		storageMock.EXPECT().UserToken(gomock.Any(), userName).Return(userToken, nil).Times(1)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		fsMock.EXPECT().Workouts(gomock.Any(), userToken,
+			time.Date(2020, time.December, 13, 0, 0, 0, 0, time.UTC),
+			time.Date(2021, time.January, 3, 0, 0, 0, 0, time.UTC)).
+			Return([]Workout{{Date: today, Description: "10 km"}}, nil).Times(1)
+
+		rec := httptest.NewRecorder()
+		calendar.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, CalendarPath+token+".ics", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("actual=%d, expected=%d", rec.Code, http.StatusOK)
+		}
+
+		if !strings.Contains(rec.Body.String(), "SUMMARY:10 km\r\n") {
+			t.Errorf("actual=%s", rec.Body.String())
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		storageMock := mock.NewMockStorage(ctrl)
+		calendar := NewCalendar(storageMock, nil, nil, 14)
+
+		storageMock.EXPECT().CalendarUserName(gomock.Any(), token).Return("", ErrNotFound).Times(1)
+
+		rec := httptest.NewRecorder()
+		calendar.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, CalendarPath+token+".ics", nil))
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("actual=%d, expected=%d", rec.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestICalendar(t *testing.T) {
+	const token = "0123456789abcdef0123456789abcdef"
+	now := time.Date(2020, time.December, 20, 15, 15, 20, 0, time.UTC)
+	today := time.Date(2020, time.December, 20, 0, 0, 0, 0, time.UTC)
+	tomorrow := time.Date(2020, time.December, 21, 0, 0, 0, 0, time.UTC)
+
+	actual := ICalendar([]Workout{
+		{
+			Date:        today,
+			Description: "Warm-up 2 km\n6x800m, 400m jog",
+		},
+		{
+			Date:        tomorrow,
+			Description: "Rest Day",
+			RestDay:     true,
+		},
+	}, token, now)
+
+	expected := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//final-surge-bot//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:FinalSurge",
+		"BEGIN:VEVENT",
+		"UID:2a18cf801de9f197cee7d3236cd433f8@final-surge-bot",
+		"DTSTAMP:20201220T151520Z",
+		"DTSTART;VALUE=DATE:20201220",
+		"DTEND;VALUE=DATE:20201221",
+		"SUMMARY:Warm-up 2 km",
+		`DESCRIPTION:Warm-up 2 km\n6x800m\, 400m jog`,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:2620e950b383f859a29837dccc540649@final-surge-bot",
+		"DTSTAMP:20201220T151520Z",
+		"DTSTART;VALUE=DATE:20201221",
+		"DTEND;VALUE=DATE:20201222",
+		"SUMMARY:Rest Day",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	if actual != expected {
+		t.Errorf("actual=%s, expected=%s", actual, expected)
+	}
+}
+
+func TestICalendar_UID(t *testing.T) {
+	const token = "0123456789abcdef0123456789abcdef"
+	now := time.Date(2020, time.December, 20, 15, 15, 20, 0, time.UTC)
+	today := time.Date(2020, time.December, 20, 0, 0, 0, 0, time.UTC)
+
+	uids := func(token string, workouts ...Workout) []string {
+		var uids []string
+
+		for _, line := range strings.Split(ICalendar(workouts, token, now), "\r\n") {
+			if uid := strings.TrimPrefix(line, "UID:"); uid != line {
+				uids = append(uids, uid)
+			}
+		}
+
+		return uids
+	}
+
+	run := Workout{Date: today, Activity: "Run", Description: "10 km"}
+	edited := Workout{Date: today, Activity: "Run", Description: "12 km"}
+	swim := Workout{Date: today, Activity: "Swim", Description: "2000m"}
+
+	if actual, expected := uids(token, edited), uids(token, run); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("edited: actual=%v, expected=%v", actual, expected)
+	}
+
+	// The run keeps its UID when another workout is planned before it on the same day.
+	if actual, expected := uids(token, swim, run)[1], uids(token, run)[0]; actual != expected {
+		t.Errorf("moved: actual=%s, expected=%s", actual, expected)
+	}
+
+	if actual := uids(token, run, run); actual[0] == actual[1] {
+		t.Errorf("same workouts: actual=%v, expected different UIDs", actual)
+	}
+
+	if actual, other := uids(token, run)[0], uids("fedcba9876543210fedcba9876543210", run)[0]; actual == other {
+		t.Errorf("other token: actual=%s, expected different UIDs", actual)
+	}
+}
@@ -0,0 +1,171 @@
+package bot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	ChartPerDay  = "day"
+	ChartPerWeek = "week"
+
+	chartDefaultWeeks = 8
+	chartMaxWeeks     = 26
+
+	chartWidth        = 800
+	chartHeight       = 400
+	chartMarginLeft   = 50
+	chartMarginRight  = 20
+	chartMarginTop    = 40
+	chartMarginBottom = 30
+	chartBarGap       = 0.2
+	chartLabelWidth   = 40
+)
+
+var ErrChartArgs = errors.New("invalid chart arguments")
+
+var (
+	chartBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	chartAxis       = color.RGBA{R: 0x90, G: 0x90, B: 0x90, A: 0xff}
+	chartBar        = color.RGBA{R: 0x3b, G: 0x82, B: 0xc4, A: 0xff}
+	chartText       = color.RGBA{R: 0x20, G: 0x20, B: 0x20, A: 0xff}
+)
+
+type ChartRange struct {
+	Start   time.Time
+	End     time.Time
+	PerWeek bool
+}
+
+type ChartBar struct {
+	Label string
+	Value float64
+}
+
+// ParseChartArgs parses arguments of the form "[day|week] [weeks]".
+// The range covers whole weeks from Monday to Sunday ending with the current week.
+func ParseChartArgs(args string, today time.Time) (ChartRange, error) {
+	r := ChartRange{PerWeek: true}
+	weeks := chartDefaultWeeks
+
+	for _, field := range strings.Fields(strings.ToLower(args)) {
+		switch field {
+		case ChartPerDay:
+			r.PerWeek = false
+		case ChartPerWeek:
+			r.PerWeek = true
+		default:
+			n, err := strconv.Atoi(field)
+			if err != nil || n < 1 || n > chartMaxWeeks {
+				return ChartRange{}, fmt.Errorf("%w: %s", ErrChartArgs, field)
+			}
+
+			weeks = n
+		}
+	}
+
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	r.Start = monday.AddDate(0, 0, -7*(weeks-1))
+	r.End = monday.AddDate(0, 0, 6)
+
+	return r, nil
+}
+
+// ChartBars sums planned distance in kilometers per day or per week of the range.
+func ChartBars(loc *Locale, workouts []Workout, r ChartRange) []ChartBar {
+	const metersInKm = 1000
+
+	step := 1
+	if r.PerWeek {
+		step = 7
+	}
+
+	var bars []ChartBar
+	for start := r.Start; !start.After(r.End); start = start.AddDate(0, 0, step) {
+		bars = append(bars, ChartBar{Label: loc.Date(start)})
+	}
+
+	for _, w := range workouts {
+		if w.Date.Before(r.Start) || w.Date.After(r.End) {
+			continue
+		}
+
+		i := int(w.Date.Sub(r.Start).Hours()/24) / step
+		bars[i].Value += w.Planned.Distance / metersInKm
+	}
+
+	return bars
+}
+
+// RenderChart draws a PNG bar chart of kilometers.
+// basicfont has only ASCII glyphs, so localized text goes to the photo caption.
+func RenderChart(bars []ChartBar) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, chartHeight))
+	draw.Draw(img, img.Bounds(), image.NewUniform(chartBackground), image.Point{}, draw.Src)
+
+	plot := image.Rect(chartMarginLeft, chartMarginTop, chartWidth-chartMarginRight, chartHeight-chartMarginBottom)
+
+	maxValue := 0.0
+	for _, b := range bars {
+		maxValue = math.Max(maxValue, b.Value)
+	}
+
+	// Round the axis up to a multiple of 10 km so the scale is easy to read.
+	maxValue = math.Max(10, math.Ceil(maxValue/10)*10)
+
+	drawText(img, 5, chartMarginTop/2, "km")
+	drawText(img, 5, plot.Min.Y+5, strconv.FormatFloat(maxValue, 'f', 0, 64))
+	drawText(img, 5, plot.Max.Y, "0")
+
+	if len(bars) > 0 {
+		slot := float64(plot.Dx()) / float64(len(bars))
+		labelStep := int(math.Ceil(chartLabelWidth / slot))
+
+		for i, b := range bars {
+			x0 := plot.Min.X + int(float64(i)*slot+slot*chartBarGap/2)
+			x1 := plot.Min.X + int(float64(i+1)*slot-slot*chartBarGap/2)
+			y0 := plot.Max.Y - int(b.Value/maxValue*float64(plot.Dy()))
+
+			draw.Draw(img, image.Rect(x0, y0, x1, plot.Max.Y), image.NewUniform(chartBar), image.Point{}, draw.Src)
+
+			if i%labelStep == 0 {
+				drawText(img, x0, chartHeight-chartMarginBottom/3, b.Label)
+			}
+		}
+	}
+
+	draw.Draw(img, image.Rect(plot.Min.X, plot.Min.Y, plot.Min.X+1, plot.Max.Y),
+		image.NewUniform(chartAxis), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(plot.Min.X, plot.Max.Y, plot.Max.X, plot.Max.Y+1),
+		image.NewUniform(chartAxis), image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func drawText(img draw.Image, x, y int, text string) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(chartText),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
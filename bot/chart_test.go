@@ -0,0 +1,170 @@
+package bot_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestParseChartArgs(t *testing.T) {
+	// Wednesday.
+	today := time.Date(2020, time.December, 23, 0, 0, 0, 0, time.UTC)
+
+	for args, tc := range map[string]struct {
+		expected ChartRange
+		err      error
+	}{
+		"": {
+			expected: ChartRange{
+				Start:   time.Date(2020, time.November, 2, 0, 0, 0, 0, time.UTC),
+				End:     time.Date(2020, time.December, 27, 0, 0, 0, 0, time.UTC),
+				PerWeek: true,
+			},
+		},
+		"day 2": {
+			expected: ChartRange{
+				Start: time.Date(2020, time.December, 14, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2020, time.December, 27, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"27":    {err: ErrChartArgs},
+		"month": {err: ErrChartArgs},
+	} {
+		args, tc := args, tc
+
+		t.Run(args, func(t *testing.T) {
+			actual, err := ParseChartArgs(args, today)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("actual err=%v, expected=%v", err, tc.err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("actual=%+v, expected=%+v", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestChartBars(t *testing.T) {
+	start := time.Date(2020, time.December, 14, 0, 0, 0, 0, time.UTC)
+	chartRange := ChartRange{Start: start, End: start.AddDate(0, 0, 13), PerWeek: true}
+
+	actual := ChartBars(LocaleFor(LanguageEnglish), []Workout{
+		{Date: start, Planned: Volume{Distance: 10000}},
+		{Date: start.AddDate(0, 0, 6), Planned: Volume{Distance: 15000}},
+		{Date: start.AddDate(0, 0, 7), Planned: Volume{Distance: 8000}},
+		{Date: start.AddDate(0, 0, 14), Planned: Volume{Distance: 30000}},
+	}, chartRange)
+
+	if expected := []ChartBar{
+		{Label: "14.12", Value: 25},
+		{Label: "21.12", Value: 8},
+	}; !reflect.DeepEqual(actual, expected) {
+		t.Errorf("actual=%+v, expected=%+v", actual, expected)
+	}
+}
+
+func TestRenderChart(t *testing.T) {
+	bs, err := RenderChart([]ChartBar{{Label: "14.12", Value: 25}, {Label: "21.12", Value: 8}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size := img.Bounds().Size(); size.X != 800 || size.Y != 400 {
+		t.Errorf("actual size=%v, expected=800x400", size)
+	}
+}
+
+func TestBot_ProcessUpdate_Chart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	senderMock := mock.NewMockSender(ctrl)
+	fsMock := mock.NewMockFinalSurge(ctrl)
+	storageMock := mock.NewMockStorage(ctrl)
+	clockMock := mock.NewMockClock(ctrl)
+	bot := NewBot(senderMock, storageMock, fsMock, clockMock, &Config{}, NewTaskTemplates())
+	const userName = "alexandear"
+	const chatID = int64(20)
+	storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+	storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	start := time.Date(2020, time.December, 21, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, time.December, 27, 0, 0, 0, 0, time.UTC)
+	clockMock.EXPECT().Now().Return(time.Date(2020, time.December, 23, 10, 0, 0, 0, time.UTC)).Times(1)
+	storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return([]UserToken{userToken}, nil).Times(1)
+	fsMock.EXPECT().Workouts(gomock.Any(), userToken, start, end).
+		Return([]Workout{{Date: start, Planned: Volume{Distance: 10000}}}, nil).Times(1)
+	senderMock.EXPECT().Send(gomock.Any()).DoAndReturn(func(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+		photo, ok := c.(tgbotapi.PhotoConfig)
+		if !ok {
+			t.Fatalf("actual=%T, expected photo", c)
+		}
+
+		if expected := "Planned distance per day, km, 21.12-27.12"; photo.Caption != expected {
+			t.Errorf("actual=%s, expected=%s", photo.Caption, expected)
+		}
+
+		return tgbotapi.Message{}, nil
+	}).Times(1)
+
+	const command = "/chart day 1"
+	if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: chatID},
+			From:     &tgbotapi.User{UserName: userName},
+			Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/chart")}},
+			Text:     command,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBot_ProcessUpdate_ChartTimeZone(t *testing.T) {
+	bot := newTestBot(t, &Config{}, Settings{TimeZone: "Europe/Kyiv"})
+
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	// It is already Monday in Kyiv, so the chart shows the new week.
+	start := time.Date(2020, time.December, 28, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2021, time.January, 3, 0, 0, 0, 0, time.UTC)
+	bot.clock.EXPECT().Now().Return(time.Date(2020, time.December, 27, 23, 30, 0, 0, time.UTC)).Times(1)
+	bot.storage.EXPECT().UserTokens(gomock.Any(), testUserName).Return([]UserToken{userToken}, nil).Times(1)
+	bot.fs.EXPECT().Workouts(gomock.Any(), userToken, start, end).Return(nil, nil).Times(1)
+	bot.sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+		if expected := "Planned distance per day, km, 28.12-03.01"; c.(tgbotapi.PhotoConfig).Caption != expected {
+			t.Errorf("actual=%s, expected=%s", c.(tgbotapi.PhotoConfig).Caption, expected)
+		}
+
+		return tgbotapi.Message{}, nil
+	}).Times(1)
+
+	bot.process(t, tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: testChatID},
+			From:     &tgbotapi.User{UserName: testUserName},
+			Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/chart")}},
+			Text:     "/chart day 1",
+		},
+	})
+}
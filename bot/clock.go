@@ -13,3 +13,7 @@ func NewClock() *RealClock {
 func (c *RealClock) Now() time.Time {
 	return time.Now()
 }
+
+func (c *RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
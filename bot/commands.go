@@ -0,0 +1,190 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Command is a bot command listed in /help and in the Telegram command menu.
+type Command struct {
+	Name string
+	// Args is the syntax of arguments such as "[day|week] [weeks]".
+	Args string
+	// Description is the locale key of the one-line description.
+	Description string
+	// Admin commands are available only to ADMIN_IDS. Other users get the reply for an unknown command.
+	Admin bool
+
+	handler commandHandler
+}
+
+type commandHandler func(ctx context.Context, message *tgbotapi.Message, loc *Locale) (tgbotapi.Chattable, error)
+
+// botCommand is the BotCommand object of the Telegram Bot API.
+type botCommand struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+// newCommands returns commands in the order they are shown in /help and in the menu.
+func (b *Bot) newCommands() []Command {
+	return []Command{
+		{Name: CommandTask, Description: msgCommandTask, handler: withoutArgs(b.buttonTask)},
+		{Name: CommandPlan, Args: "[today|tomorrow|week|3d|20.10-26.10]", Description: msgCommandPlan,
+			handler: withArgs(b.commandPlan)},
+		{Name: CommandSteps, Args: "[today|tomorrow|sat|20.10]", Description: msgCommandSteps,
+			handler: withArgs(b.commandSteps)},
+		{Name: CommandYesterday, Description: msgCommandYesterday, handler: withoutArgs(b.commandYesterday)},
+		{Name: CommandRaces, Args: "[pin <date> <name>|unpin]", Description: msgCommandRaces,
+			handler: withArgs(b.commandRaces)},
+		{Name: CommandChart, Args: "[day|week] [weeks]", Description: msgCommandChart,
+			handler: withArgs(b.commandChart)},
+		{Name: CommandExport, Args: "<start> <end> [csv|json]", Description: msgCommandExport,
+			handler: withArgs(b.commandExport)},
+		{Name: CommandExportWorkout, Args: "[fit|tcx|zwo]", Description: msgCommandExportWorkout,
+			handler: withArgs(b.commandExportWorkout)},
+		{Name: CommandCalendar, Args: "[reset]", Description: msgCommandCalendar, handler: withArgs(b.commandCalendar)},
+		{Name: CommandSubscribe, Description: msgCommandSubscribe, handler: withoutArgs(b.commandSubscribe)},
+		{Name: CommandUnsubscribe, Description: msgCommandUnsubscribe, handler: withoutArgs(b.commandUnsubscribe)},
+		{Name: CommandStart, Args: "[label]", Description: msgCommandStart,
+			handler: func(_ context.Context, message *tgbotapi.Message, loc *Locale) (tgbotapi.Chattable, error) {
+				return b.commandStart(message, loc)
+			}},
+		{Name: CommandAccounts, Args: "[use <label>|all|unlink <label>]", Description: msgCommandAccounts,
+			handler: withArgs(b.commandAccounts)},
+		{Name: CommandSettings, Description: msgCommandSettings, handler: withoutArgs(b.commandSettings)},
+		{Name: CommandLanguage, Args: "[en|uk|de|auto]", Description: msgCommandLanguage, handler: b.commandLanguage},
+		{Name: CommandTimeZone, Args: "[Europe/Kyiv]", Description: msgCommandTimeZone,
+			handler: withArgs(b.commandTimeZone)},
+		{Name: CommandMarkup, Args: "[html|plain]", Description: msgCommandMarkup, handler: withArgs(b.commandMarkup)},
+		{Name: CommandFormat, Args: "[" + strings.Join(b.templates.Names(), "|") + "]", Description: msgCommandFormat,
+			handler: withArgs(b.commandFormat)},
+		{Name: CommandLogout, Description: msgCommandLogout, handler: withoutArgs(b.commandLogout)},
+		{Name: CommandForgetMe, Description: msgCommandForgetMe, handler: withoutArgs(b.commandForgetMe)},
+		{Name: CommandBack, Description: msgCommandBack,
+			handler: func(_ context.Context, message *tgbotapi.Message, loc *Locale) (tgbotapi.Chattable, error) {
+				return b.commandBack(message, loc), nil
+			}},
+		{Name: CommandCancel, Description: msgCommandCancel, handler: withoutArgs(b.commandCancel)},
+		{Name: CommandHelp, Description: msgCommandHelp, handler: b.commandHelp},
+		{Name: CommandStats, Description: msgCommandStats, Admin: true,
+			handler: func(ctx context.Context, message *tgbotapi.Message, loc *Locale) (tgbotapi.Chattable, error) {
+				return b.commandStats(ctx, message.Chat.ID, loc)
+			}},
+		{Name: CommandBroadcast, Args: "<text>", Description: msgCommandBroadcast, Admin: true,
+			handler: func(ctx context.Context, message *tgbotapi.Message, loc *Locale) (tgbotapi.Chattable, error) {
+				return b.commandBroadcast(ctx, message.Chat.ID, loc, message.CommandArguments())
+			}},
+		{Name: CommandUser, Args: "<id|@username>", Description: msgCommandUser, Admin: true,
+			handler: func(ctx context.Context, message *tgbotapi.Message, loc *Locale) (tgbotapi.Chattable, error) {
+				return b.commandUser(ctx, message.Chat.ID, loc, message.CommandArguments())
+			}},
+	}
+}
+
+func withArgs(h func(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error),
+) commandHandler {
+	return func(ctx context.Context, message *tgbotapi.Message, loc *Locale) (tgbotapi.Chattable, error) {
+		return h(ctx, message.From.UserName, message.Chat.ID, loc, message.CommandArguments())
+	}
+}
+
+func withoutArgs(h func(ctx context.Context, userName string, chatID int64, loc *Locale) (tgbotapi.Chattable, error),
+) commandHandler {
+	return func(ctx context.Context, message *tgbotapi.Message, loc *Locale) (tgbotapi.Chattable, error) {
+		return h(ctx, message.From.UserName, message.Chat.ID, loc)
+	}
+}
+
+// Commands returns commands available to the Telegram user.
+func (b *Bot) Commands(user *tgbotapi.User) []Command {
+	commands := make([]Command, 0, len(b.commands))
+
+	for _, c := range b.commands {
+		if !c.Admin || b.isAdmin(user) {
+			commands = append(commands, c)
+		}
+	}
+
+	return commands
+}
+
+// commandName returns the command of the message. The keyboard button is a command even when
+// the client sends it as plain text.
+func commandName(message *tgbotapi.Message) (string, bool) {
+	if message.IsCommand() {
+		return message.Command(), true
+	}
+
+	if message.Text == KeyboardButtonTask {
+		return CommandTask, true
+	}
+
+	return "", false
+}
+
+func (b *Bot) command(ctx context.Context, message *tgbotapi.Message, loc *Locale, name string,
+) (tgbotapi.Chattable, error) {
+	for _, c := range b.Commands(message.From) {
+		if c.Name == name {
+			return c.handler(ctx, message, loc)
+		}
+	}
+
+	return tgbotapi.NewMessage(message.Chat.ID, loc.T(msgUnknownCommand, name)), nil
+}
+
+func (b *Bot) commandHelp(_ context.Context, message *tgbotapi.Message, loc *Locale) (tgbotapi.Chattable, error) {
+	help := strings.Builder{}
+	help.WriteString(loc.T(msgHelp))
+	help.WriteByte('\n')
+
+	for _, c := range b.Commands(message.From) {
+		help.WriteString("/" + c.Name)
+
+		if c.Args != "" {
+			help.WriteString(" " + c.Args)
+		}
+
+		help.WriteString(" - " + loc.T(c.Description))
+		help.WriteByte('\n')
+	}
+
+	return tgbotapi.NewMessage(message.Chat.ID, help.String()), nil
+}
+
+// RegisterCommands sets the Telegram command menu for every supported language.
+// English is also the default for other languages. Admin commands are not listed.
+func (b *Bot) RegisterCommands() error {
+	for _, language := range append([]string{""}, Languages...) {
+		loc := LocaleFor(language)
+
+		var commands []botCommand
+
+		for _, c := range b.Commands(nil) {
+			commands = append(commands, botCommand{Command: c.Name, Description: loc.T(c.Description)})
+		}
+
+		data, err := json.Marshal(commands)
+		if err != nil {
+			return fmt.Errorf("marshal commands: %w", err)
+		}
+
+		params := url.Values{"commands": {string(data)}}
+		if language != "" {
+			params.Set("language_code", language)
+		}
+
+		if _, err := b.bot.MakeRequest("setMyCommands", params); err != nil {
+			return fmt.Errorf("set commands for language %q: %w", language, err)
+		}
+	}
+
+	return nil
+}
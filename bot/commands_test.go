@@ -0,0 +1,108 @@
+package bot_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestBot_ProcessUpdate_Help(t *testing.T) {
+	const userName = "alexandear"
+	const chatID = int64(20)
+	const adminID = 7
+	command := func(text string, userID int) tgbotapi.Update {
+		return tgbotapi.Update{
+			Message: &tgbotapi.Message{
+				Chat:     &tgbotapi.Chat{ID: chatID},
+				From:     &tgbotapi.User{ID: userID, UserName: userName},
+				Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(text)}},
+				Text:     text,
+			},
+		}
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	senderMock := mock.NewMockSender(ctrl)
+	storageMock := mock.NewMockStorage(ctrl)
+	bot := NewBot(senderMock, storageMock, nil, nil, &Config{AdminIDs: []int{adminID}}, NewTaskTemplates())
+	storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+	storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	var help string
+	senderMock.EXPECT().Send(gomock.Any()).Do(func(msg tgbotapi.Chattable) {
+		help = msg.(tgbotapi.MessageConfig).Text
+	}).Times(1)
+	if err := bot.ProcessUpdate(context.Background(), command("/help", 8)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"Commands:\n/task - Workouts for today and tomorrow\n",
+		"/chart [day|week] [weeks] - Chart of planned distance\n",
+		"/format [compact|full|today] - Template of task messages\n",
+	} {
+		if !strings.Contains(help, line) {
+			t.Errorf("help=%q must contain %q", help, line)
+		}
+	}
+
+	if strings.Contains(help, "/stats") {
+		t.Errorf("help=%q must not contain admin commands", help)
+	}
+
+	senderMock.EXPECT().Send(gomock.Any()).Do(func(msg tgbotapi.Chattable) {
+		help = msg.(tgbotapi.MessageConfig).Text
+	}).Times(1)
+	if err := bot.ProcessUpdate(context.Background(), command("/help", adminID)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(help, "/user <id|@username> - Link status of the user\n") {
+		t.Errorf("help=%q must contain admin commands", help)
+	}
+
+	senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+		BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+		Text:     "Unknown command /weather. Enter /help to see all commands.",
+	}).Times(1)
+	if err := bot.ProcessUpdate(context.Background(), command("/weather", 8)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBot_RegisterCommands(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	senderMock := mock.NewMockSender(ctrl)
+	bot := NewBot(senderMock, nil, nil, nil, &Config{AdminIDs: []int{7}}, NewTaskTemplates())
+
+	var languages []string
+	senderMock.EXPECT().MakeRequest("setMyCommands", gomock.Any()).Do(func(_ string, params url.Values) {
+		languages = append(languages, params.Get("language_code"))
+
+		commands := params.Get("commands")
+		if strings.Contains(commands, `"stats"`) {
+			t.Errorf("commands=%s must not contain admin commands", commands)
+		}
+
+		if params.Get("language_code") == LanguageGerman &&
+			!strings.Contains(commands, `{"command":"task","description":"Trainings für heute und morgen"}`) {
+			t.Errorf("commands=%s must be in German", commands)
+		}
+	}).Return(tgbotapi.APIResponse{Ok: true}, nil).Times(4)
+
+	if err := bot.RegisterCommands(); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(languages, ",") != ",en,uk,de" {
+		t.Errorf("actual=%q, expected default, en, uk, de", languages)
+	}
+}
@@ -0,0 +1,227 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// MessageYesterday shows which workouts of the day are logged in FinalSurge with the actual and the planned volume.
+func MessageYesterday(loc *Locale, units string, date time.Time, workouts []Workout) string {
+	msg := strings.Builder{}
+	msg.WriteString(loc.T(msgYesterday, loc.Date(date)))
+
+	var day []Workout
+
+	for _, w := range workouts {
+		if !w.Date.Equal(date) {
+			continue
+		}
+
+		day = append(day, w)
+
+		msg.WriteString("\n\n" + workoutTitle(w))
+
+		if w.RestDay {
+			continue
+		}
+
+		msg.WriteString("\n" + workoutStatus(loc, units, w))
+	}
+
+	if len(day) == 0 {
+		msg.WriteString("\n" + loc.T(msgNotSet))
+
+		return msg.String()
+	}
+
+	if summary := NewWeekSummary(day); summary.Sessions > 0 {
+		msg.WriteString("\n\n" + loc.T(msgCompliance, summary.Compliance()))
+	}
+
+	return msg.String()
+}
+
+// workoutTitle is the activity emoji and the name or the first line of the description.
+func workoutTitle(w Workout) string {
+	title := workoutName(w)
+	if emoji := ActivityEmoji(w); emoji != "" {
+		title = emoji + " " + title
+	}
+
+	return title
+}
+
+func workoutStatus(loc *Locale, units string, w Workout) string {
+	if !w.Completed {
+		return loc.T(msgWorkoutNotLogged)
+	}
+
+	swim := strings.Contains(strings.ToLower(w.Activity), "swim")
+	actual := formatVolume(w.Actual, units, swim)
+	planned := formatVolume(w.Planned, units, swim)
+
+	switch {
+	case actual == "":
+		return loc.T(msgWorkoutDone)
+	case planned == "":
+		return loc.T(msgWorkoutDoneVolume, actual)
+	default:
+		return loc.T(msgWorkoutDonePlanned, actual, planned)
+	}
+}
+
+// formatVolume formats the distance in the units and the duration, or returns "" for the zero volume.
+func formatVolume(v Volume, units string, swim bool) string {
+	parts := make([]string, 0, 2)
+
+	if v.Distance > 0 {
+		parts = append(parts, formatStepDistance(v.Distance, units, swim))
+	}
+
+	if v.Duration > 0 {
+		parts = append(parts, FormatDuration(v.Duration))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// MessageReminder lists workouts of the day not logged yet or returns "" when there are none.
+func MessageReminder(loc *Locale, date time.Time, workouts []Workout) string {
+	msg := strings.Builder{}
+
+	for _, w := range workouts {
+		if !w.Date.Equal(date) || w.RestDay || w.Completed {
+			continue
+		}
+
+		if msg.Len() == 0 {
+			msg.WriteString(loc.T(msgReminder))
+		}
+
+		msg.WriteString("\n" + workoutTitle(w))
+	}
+
+	return msg.String()
+}
+
+// commandYesterday shows whether yesterday's workouts are logged.
+func (b *Bot) commandYesterday(ctx context.Context, userName string, chatID int64, loc *Locale,
+) (tgbotapi.Chattable, error) {
+	today, err := b.userToday(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	yesterday := today.AddDate(0, 0, -1)
+
+	userTokens, err := b.db.UserTokens(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		return tgbotapi.NewMessage(chatID, loc.T(msgAuthorizeFirst)), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get usertokens: %w", err)
+	}
+
+	workouts, err := accountsWorkouts(ctx, b.fs, userTokens, yesterday, yesterday)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := UserSettings(ctx, b.db, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	return tgbotapi.NewMessage(chatID, MessageYesterday(loc, settings.Units, yesterday, workouts)), nil
+}
+
+// Reminder reminds subscribed users in the evening about today's workouts not logged yet.
+type Reminder struct {
+	bot   Sender
+	db    Storage
+	fs    FinalSurge
+	clock Clock
+
+	hour int
+}
+
+func NewReminder(bot Sender, db Storage, fs FinalSurge, clock Clock, hour int) *Reminder {
+	return &Reminder{
+		bot:   bot,
+		db:    db,
+		fs:    fs,
+		clock: clock,
+
+		hour: hour,
+	}
+}
+
+// Run sends reminders at the start of every hour until ctx is done.
+func (r *Reminder) Run(ctx context.Context) {
+	runHourly(ctx, r.clock, "send reminders", r.Send)
+}
+
+// Send sends reminders to subscribed users for whom it is their reminder hour now.
+func (r *Reminder) Send(ctx context.Context) error {
+	subscriptions, err := r.db.Subscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("get subscriptions: %w", err)
+	}
+
+	now := r.clock.Now()
+
+	for _, s := range subscriptions {
+		if err := r.sendSubscription(ctx, s, now); err != nil {
+			log.Printf("send reminder to %s: %v", s.UserName, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Reminder) sendSubscription(ctx context.Context, subscription Subscription, now time.Time) error {
+	settings, err := UserSettings(ctx, r.db, subscription.UserName)
+	if err != nil {
+		return err
+	}
+
+	zone, err := settings.Location(now.Location())
+	if err != nil {
+		return fmt.Errorf("get time zone: %w", err)
+	}
+
+	now = now.In(zone)
+	if hour, ok := settings.ReminderHour(r.hour); !ok || now.Hour() != hour {
+		return nil
+	}
+
+	userTokens, err := r.db.UserTokens(ctx, subscription.UserName)
+	if err != nil {
+		return fmt.Errorf("get usertokens: %w", err)
+	}
+
+	today := NewDate(now)
+
+	workouts, err := accountsWorkouts(ctx, r.fs, userTokens, today, today)
+	if err != nil {
+		return err
+	}
+
+	text := MessageReminder(settings.Locale(""), today, workouts)
+	if text == "" {
+		return nil
+	}
+
+	if _, err := r.bot.Send(tgbotapi.NewMessage(subscription.ChatID, text)); err != nil {
+		return fmt.Errorf("send reminder to chat %d: %w", subscription.ChatID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package bot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestBot_ProcessUpdate_Yesterday(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	senderMock := mock.NewMockSender(ctrl)
+	fsMock := mock.NewMockFinalSurge(ctrl)
+	storageMock := mock.NewMockStorage(ctrl)
+	clockMock := mock.NewMockClock(ctrl)
+	bot := NewBot(senderMock, storageMock, fsMock, clockMock, &Config{}, NewTaskTemplates())
+	const userName = "alexandear"
+	const chatID = int64(20)
+	storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{Units: UnitsImperial}, nil).AnyTimes()
+	storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	yesterday := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	clockMock.EXPECT().Now().Return(time.Date(2026, time.October, 20, 8, 0, 0, 0, time.UTC)).Times(1)
+	storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return([]UserToken{userToken}, nil).Times(1)
+	fsMock.EXPECT().Workouts(gomock.Any(), userToken, yesterday, yesterday).Return([]Workout{
+		{
+			Date:      yesterday,
+			Activity:  "Run",
+			Name:      "Easy run",
+			Completed: true,
+			Planned:   Volume{Distance: 16093.44, Duration: 80 * time.Minute},
+			Actual:    Volume{Distance: 16898.112, Duration: 82 * time.Minute},
+		},
+		{Date: yesterday, Activity: "Swim", Description: "10x100m\nEasy"},
+		{Date: yesterday, Activity: "Bike", Name: "Spin", Completed: true},
+	}, nil).Times(1)
+	senderMock.EXPECT().Send(tgbotapi.NewMessage(chatID, `Yesterday 19.10:
+
+🏃 Easy run
+✅ Done: 10.5 mi, 1:22 of 10 mi, 1:20 planned
+
+🏊 10x100m
+❌ Not logged
+
+🚴 Spin
+✅ Done
+
+Compliance: 67%`)).Times(1)
+
+	if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: chatID},
+			From:     &tgbotapi.User{UserName: userName},
+			Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/yesterday")}},
+			Text:     "/yesterday",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReminder_Send(t *testing.T) {
+	const userName = "alexandear"
+	const chatID = int64(20)
+
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	// 20:00 in Kyiv.
+	now := time.Date(2026, time.October, 20, 17, 0, 0, 0, time.UTC)
+	today := time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)
+	track := Workout{Date: today, Activity: "Run", Name: "Track"}
+
+	newReminder := func(t *testing.T, settings Settings) (*Reminder, *mock.MockSender, *mock.MockStorage,
+		*mock.MockFinalSurge,
+	) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+		senderMock := mock.NewMockSender(ctrl)
+		fsMock := mock.NewMockFinalSurge(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		clockMock := mock.NewMockClock(ctrl)
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		storageMock.EXPECT().Subscriptions(gomock.Any()).
+			Return([]Subscription{{UserName: userName, ChatID: chatID}}, nil).Times(1)
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(settings, nil).AnyTimes()
+
+		return NewReminder(senderMock, storageMock, fsMock, clockMock, 20), senderMock, storageMock, fsMock
+	}
+
+	t.Run("not logged", func(t *testing.T) {
+		reminder, senderMock, storageMock, fsMock := newReminder(t, Settings{TimeZone: "Europe/Kyiv"})
+		storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return([]UserToken{userToken}, nil).Times(1)
+		fsMock.EXPECT().Workouts(gomock.Any(), userToken, today, today).Return([]Workout{
+			{Date: today, Activity: "Bike", Name: "Spin", Completed: true},
+			track,
+		}, nil).Times(1)
+		senderMock.EXPECT().Send(tgbotapi.NewMessage(chatID, "⏰ Not logged yet today:\n🏃 Track")).Times(1)
+
+		if err := reminder.Send(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("logged", func(t *testing.T) {
+		reminder, _, storageMock, fsMock := newReminder(t, Settings{TimeZone: "Europe/Kyiv"})
+		storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return([]UserToken{userToken}, nil).Times(1)
+		completed := track
+		completed.Completed = true
+		fsMock.EXPECT().Workouts(gomock.Any(), userToken, today, today).
+			Return([]Workout{completed, {Date: today, RestDay: true}}, nil).Times(1)
+
+		if err := reminder.Send(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("other hour", func(t *testing.T) {
+		hour := 21
+		reminder, _, _, _ := newReminder(t, Settings{TimeZone: "Europe/Kyiv", RemindHour: &hour})
+
+		if err := reminder.Send(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("off", func(t *testing.T) {
+		off := ReminderOff
+		reminder, _, _, _ := newReminder(t, Settings{TimeZone: "Europe/Kyiv", RemindHour: &off})
+
+		if err := reminder.Send(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
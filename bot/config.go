@@ -1,18 +1,54 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
 
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+
+	UpdateModeAuto    = "auto"
+	UpdateModeWebhook = "webhook"
+	UpdateModePolling = "polling"
+)
+
 type Config struct {
-	Debug       bool   `envconfig:"DEBUG"`
-	PublicURL   string `envconfig:"PUBLIC_URL" required:"true"`
-	BotAPIKey   string `envconfig:"BOT_API_KEY" required:"true"`
-	Port        int    `envconfig:"PORT" required:"true"`
-	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
-	RunOnCloud  bool   `envconfig:"RUN_ON_CLOUD"`
+	Debug      bool   `envconfig:"DEBUG"`
+	PublicURL  string `envconfig:"PUBLIC_URL" required:"true"`
+	BotAPIKey  string `envconfig:"BOT_API_KEY" required:"true"`
+	Port       int    `envconfig:"PORT" required:"true"`
+	RunOnCloud bool   `envconfig:"RUN_ON_CLOUD"`
+	// UpdateMode is how updates are received: UpdateModeWebhook, UpdateModePolling or UpdateModeAuto
+	// for a webhook on the cloud and long polling otherwise.
+	UpdateMode string `envconfig:"UPDATE_MODE" default:"auto"`
+
+	Storage     string `envconfig:"STORAGE" default:"postgres"`
+	DatabaseURL string `envconfig:"DATABASE_URL"`
+	SQLitePath  string `envconfig:"SQLITE_PATH" default:"final-surge-bot.db"`
+
+	WatchDays     int           `envconfig:"WATCH_DAYS" default:"7"`
+	WatchInterval time.Duration `envconfig:"WATCH_INTERVAL" default:"30m"`
+
+	CalendarDays int `envconfig:"CALENDAR_DAYS" default:"28"`
+
+	DigestDay  string `envconfig:"DIGEST_DAY" default:"sunday"`
+	DigestHour int    `envconfig:"DIGEST_HOUR" default:"19"`
+
+	// ReminderHour is the local hour of the reminder about today's workouts not logged yet.
+	ReminderHour int `envconfig:"REMINDER_HOUR" default:"20"`
+
+	// AdminIDs are Telegram user IDs allowed to use /stats, /broadcast and /user.
+	AdminIDs []int `envconfig:"ADMIN_IDS"`
+
+	// TemplateDir contains custom *.tmpl task templates selectable with /format.
+	TemplateDir string `envconfig:"TEMPLATE_DIR"`
 }
 
 func NewConfig() (*Config, error) {
@@ -21,5 +57,59 @@ func NewConfig() (*Config, error) {
 		return nil, fmt.Errorf("process config: %w", err)
 	}
 
+	switch c.Storage {
+	case StoragePostgres:
+		if c.DatabaseURL == "" {
+			return nil, errors.New("required key DATABASE_URL missing value for postgres storage")
+		}
+	case StorageSQLite, StorageMemory:
+	default:
+		return nil, fmt.Errorf("unknown storage %s, must be one of %s, %s, %s", c.Storage,
+			StoragePostgres, StorageSQLite, StorageMemory)
+	}
+
+	switch c.UpdateMode {
+	case UpdateModeAuto, UpdateModeWebhook, UpdateModePolling:
+	default:
+		return nil, fmt.Errorf("unknown update mode %s, must be one of %s, %s, %s", c.UpdateMode,
+			UpdateModeAuto, UpdateModeWebhook, UpdateModePolling)
+	}
+
+	if _, err := ParseWeekday(c.DigestDay); err != nil {
+		return nil, fmt.Errorf("parse DIGEST_DAY: %w", err)
+	}
+
+	if c.DigestHour < 0 || c.DigestHour > 23 {
+		return nil, fmt.Errorf("DIGEST_HOUR %d must be from 0 to 23", c.DigestHour)
+	}
+
+	if c.ReminderHour < 0 || c.ReminderHour > 23 {
+		return nil, fmt.Errorf("REMINDER_HOUR %d must be from 0 to 23", c.ReminderHour)
+	}
+
 	return c, nil
 }
+
+// Updates returns the update mode with UpdateModeAuto resolved by RunOnCloud.
+func (c *Config) Updates() string {
+	if c.UpdateMode != UpdateModeAuto && c.UpdateMode != "" {
+		return c.UpdateMode
+	}
+
+	if c.RunOnCloud {
+		return UpdateModeWebhook
+	}
+
+	return UpdateModePolling
+}
+
+// ParseWeekday parses an English weekday name such as "sunday" or "Sun".
+func ParseWeekday(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.EqualFold(s, d.String()) || strings.EqualFold(s, d.String()[:3]) {
+			return d, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown weekday %s", s)
+}
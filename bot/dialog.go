@@ -0,0 +1,163 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// dialogTimeout is measured between messages of the user, so an abandoned dialog does not catch later text.
+const dialogTimeout = 10 * time.Minute
+
+// Dialog is a conversation that asks steps in order and calls Done with the answers.
+// Users may go back with /back and cancel with /cancel at any step.
+type Dialog struct {
+	Name  string
+	Steps []DialogStep
+	Done  func(ctx context.Context, session *DialogSession, loc *Locale) (tgbotapi.Chattable, error)
+}
+
+// DialogStep asks for one answer.
+type DialogStep struct {
+	Name string
+	// Prompt is the locale key of the question.
+	Prompt string
+	// Validate returns the locale key of the reason the answer is rejected or "" when it is accepted.
+	// Nil accepts any answer.
+	Validate func(answer string) string
+}
+
+// DialogSession is the progress of the user in a dialog.
+type DialogSession struct {
+	Dialog   *Dialog
+	UserName string
+	ChatID   int64
+	Step     int
+	// Params are set when the dialog starts, for example the account label of the login.
+	Params map[string]string
+	// Answers are keyed by step names.
+	Answers map[string]string
+	// LastMessage is the time of the last message of the user in the dialog.
+	LastMessage time.Time
+}
+
+// startDialog replaces any dialog of the user with the new one and asks the first step.
+func (b *Bot) startDialog(dialog *Dialog, message *tgbotapi.Message, loc *Locale, params map[string]string,
+) tgbotapi.Chattable {
+	session := &DialogSession{
+		Dialog:      dialog,
+		UserName:    message.From.UserName,
+		ChatID:      message.Chat.ID,
+		Params:      params,
+		Answers:     make(map[string]string, len(dialog.Steps)),
+		LastMessage: message.Time(),
+	}
+	b.dialogs[session.UserName] = session
+
+	return tgbotapi.NewMessage(session.ChatID, loc.T(dialog.Steps[0].Prompt))
+}
+
+// continueDialog takes the message as the answer to the current step.
+// It returns false when the user has no dialog.
+func (b *Bot) continueDialog(ctx context.Context, message *tgbotapi.Message, loc *Locale,
+) (tgbotapi.Chattable, bool, error) {
+	session, ok := b.dialogs[message.From.UserName]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if message.Time().Sub(session.LastMessage) > dialogTimeout {
+		b.forgetDialog(session.UserName)
+
+		return tgbotapi.NewMessage(session.ChatID, loc.T(msgDialogTimeout)), true, nil
+	}
+
+	session.LastMessage = message.Time()
+	step := session.Dialog.Steps[session.Step]
+
+	if step.Validate != nil {
+		if reason := step.Validate(message.Text); reason != "" {
+			return tgbotapi.NewMessage(session.ChatID, loc.T(reason)), true, nil
+		}
+	}
+
+	session.Answers[step.Name] = message.Text
+	session.Step++
+
+	if session.Step < len(session.Dialog.Steps) {
+		return tgbotapi.NewMessage(session.ChatID, loc.T(session.Dialog.Steps[session.Step].Prompt)), true, nil
+	}
+
+	b.forgetDialog(session.UserName)
+
+	msg, err := session.Dialog.Done(ctx, session, loc)
+	if err != nil {
+		return nil, true, fmt.Errorf("finish dialog %s: %w", session.Dialog.Name, err)
+	}
+
+	// Done has called Retry.
+	if session.Step < len(session.Dialog.Steps) {
+		b.dialogs[session.UserName] = session
+	}
+
+	return msg, true, nil
+}
+
+// Retry lets Done ask the step again, for example when FinalSurge rejects the password.
+// Answers from the step on are dropped.
+func (s *DialogSession) Retry(stepName string) {
+	for i, step := range s.Dialog.Steps {
+		if step.Name == stepName {
+			s.Step = i
+		}
+	}
+
+	for _, step := range s.Dialog.Steps[s.Step:] {
+		delete(s.Answers, step.Name)
+	}
+}
+
+func (b *Bot) forgetDialog(userName string) {
+	delete(b.dialogs, userName)
+}
+
+func (b *Bot) commandCancel(_ context.Context, userName string, chatID int64, loc *Locale,
+) (tgbotapi.Chattable, error) {
+	if _, ok := b.dialogs[userName]; !ok {
+		return tgbotapi.NewMessage(chatID, loc.T(msgNoDialog)), nil
+	}
+
+	b.forgetDialog(userName)
+
+	msg := tgbotapi.NewMessage(chatID, loc.T(msgDialogCancelled))
+	msg.ReplyMarkup = b.keyboard
+
+	return msg, nil
+}
+
+// commandBack asks the previous step again. The first step is asked again too.
+func (b *Bot) commandBack(message *tgbotapi.Message, loc *Locale) tgbotapi.Chattable {
+	session, ok := b.dialogs[message.From.UserName]
+	if !ok {
+		return tgbotapi.NewMessage(message.Chat.ID, loc.T(msgNoDialog))
+	}
+
+	if message.Time().Sub(session.LastMessage) > dialogTimeout {
+		b.forgetDialog(session.UserName)
+
+		return tgbotapi.NewMessage(session.ChatID, loc.T(msgDialogTimeout))
+	}
+
+	session.LastMessage = message.Time()
+
+	if session.Step > 0 {
+		session.Step--
+	}
+
+	step := session.Dialog.Steps[session.Step]
+	delete(session.Answers, step.Name)
+
+	return tgbotapi.NewMessage(session.ChatID, loc.T(step.Prompt))
+}
@@ -0,0 +1,111 @@
+package bot_test
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestBot_ProcessUpdate_Dialog(t *testing.T) {
+	message := func(text string, date int) tgbotapi.Update {
+		m := &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: testChatID},
+			From: &tgbotapi.User{UserName: testUserName},
+			Date: date,
+			Text: text,
+		}
+		if command, _, _ := strings.Cut(text, " "); command[0] == '/' {
+			m.Entities = &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}}
+		}
+
+		return tgbotapi.Update{Message: m}
+	}
+
+	t.Run("back", func(t *testing.T) {
+		bot := newTestBot(t, &Config{}, Settings{})
+
+		bot.expectReply("Enter FinalSurge email:")
+		bot.expectReply("Enter FinalSurge password:")
+		bot.expectReply("Enter FinalSurge email:")
+		bot.expectReply("Enter FinalSurge password:")
+		bot.fs.EXPECT().Login(gomock.Any(), "right@example.com", "password").Return(UserToken{}, nil).Times(1)
+		bot.storage.EXPECT().UpdateUserToken(gomock.Any(), testUserName, "club", UserToken{}).Return(nil).Times(1)
+		bot.sender.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: testChatID, ReplyMarkup: bot.Keyboard()},
+			Text:     "Choose option:",
+		}).Times(2)
+
+		bot.process(t,
+			message("/start club", 0),
+			message("wrong@example.com", 1),
+			message("/back", 2),
+			message("right@example.com", 3),
+			message("password", 4),
+			// The dialog is over, so the text is not taken as an email.
+			message("other@example.com", 5),
+		)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		bot := newTestBot(t, &Config{}, Settings{})
+
+		bot.expectReply("Enter FinalSurge email:")
+		bot.sender.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: testChatID, ReplyMarkup: bot.Keyboard()},
+			Text:     "Cancelled",
+		}).Times(1)
+		bot.expectReply("There is no question to answer")
+
+		bot.process(t,
+			message("/start", 0),
+			message("/cancel", 1),
+			message("/cancel", 2),
+		)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		bot := newTestBot(t, &Config{}, Settings{})
+
+		bot.expectReply("Enter FinalSurge email:")
+		bot.expectReply("The dialog is cancelled because there was no answer for too long. Start again.")
+
+		bot.process(t,
+			message("/start", 0),
+			message("user@example.com", 11*60),
+		)
+	})
+
+	t.Run("back after timeout", func(t *testing.T) {
+		bot := newTestBot(t, &Config{}, Settings{})
+
+		bot.expectReply("Enter FinalSurge email:")
+		bot.expectReply("The dialog is cancelled because there was no answer for too long. Start again.")
+		bot.expectReply("There is no question to answer")
+
+		bot.process(t,
+			message("/start", 0),
+			message("/back", 11*60),
+			message("/back", 11*60+1),
+		)
+	})
+
+	t.Run("back keeps dialog", func(t *testing.T) {
+		bot := newTestBot(t, &Config{}, Settings{})
+
+		bot.expectReply("Enter FinalSurge email:")
+		bot.expectReply("Enter FinalSurge password:")
+		bot.expectReply("Enter FinalSurge email:")
+		bot.expectReply("Enter FinalSurge password:")
+
+		// Every answer is within the timeout of /back, not of the start.
+		bot.process(t,
+			message("/start", 0),
+			message("user@example.com", 9*60),
+			message("/back", 18*60),
+			message("user@example.com", 27*60),
+		)
+	})
+}
@@ -0,0 +1,197 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const digestDays = 7
+
+// WeekSummary compares planned and completed workouts.
+type WeekSummary struct {
+	Sessions  int
+	Completed int
+	Missed    int
+	RestDays  int
+	Planned   Volume
+	Actual    Volume
+}
+
+// NewWeekSummary summarizes past workouts, so every session that is not completed is missed.
+func NewWeekSummary(workouts []Workout) WeekSummary {
+	var s WeekSummary
+
+	for _, w := range workouts {
+		if w.RestDay {
+			s.RestDays++
+
+			continue
+		}
+
+		s.Sessions++
+		s.Planned = s.Planned.Add(w.Planned)
+
+		if w.Completed {
+			s.Completed++
+			s.Actual = s.Actual.Add(w.Actual)
+		}
+	}
+
+	s.Missed = s.Sessions - s.Completed
+
+	return s
+}
+
+// Compliance is the percentage of completed sessions, 100 when no sessions are planned.
+func (s WeekSummary) Compliance() int {
+	if s.Sessions == 0 {
+		return 100
+	}
+
+	return int(math.Round(float64(s.Completed) * 100 / float64(s.Sessions)))
+}
+
+// Digest sends the weekly summary to subscribed users on the weekday at the hour chosen in /settings.
+type Digest struct {
+	bot   Sender
+	db    Storage
+	fs    FinalSurge
+	clock Clock
+
+	weekday time.Weekday
+	hour    int
+}
+
+func NewDigest(bot Sender, db Storage, fs FinalSurge, clock Clock, weekday time.Weekday, hour int) *Digest {
+	return &Digest{
+		bot:   bot,
+		db:    db,
+		fs:    fs,
+		clock: clock,
+
+		weekday: weekday,
+		hour:    hour,
+	}
+}
+
+// Run sends digests at the start of every hour until ctx is done.
+// Users have their own time zones and hours, so every hour some of them may be due.
+func (d *Digest) Run(ctx context.Context) {
+	runHourly(ctx, d.clock, "send digests", d.Send)
+}
+
+// runHourly calls send at the start of every hour until ctx is done and logs its errors.
+func runHourly(ctx context.Context, clock Clock, name string, send func(ctx context.Context) error) {
+	for {
+		now := clock.Now()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-clock.After(NextHour(now).Sub(now)):
+		}
+
+		if err := send(ctx); err != nil {
+			log.Printf("%s: %v", name, err)
+		}
+	}
+}
+
+// NextHour returns the start of the hour after now.
+func NextHour(now time.Time) time.Time {
+	return now.Truncate(time.Hour).Add(time.Hour)
+}
+
+// Send sends digests to subscribed users for whom it is the weekday at their hour now.
+func (d *Digest) Send(ctx context.Context) error {
+	subscriptions, err := d.db.Subscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("get subscriptions: %w", err)
+	}
+
+	now := d.clock.Now()
+
+	for _, s := range subscriptions {
+		if err := d.sendSubscription(ctx, s, now); err != nil {
+			log.Printf("send digest to %s: %v", s.UserName, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *Digest) sendSubscription(ctx context.Context, subscription Subscription, now time.Time) error {
+	settings, err := UserSettings(ctx, d.db, subscription.UserName)
+	if err != nil {
+		return err
+	}
+
+	zone, err := settings.Location(now.Location())
+	if err != nil {
+		return fmt.Errorf("get time zone: %w", err)
+	}
+
+	now = now.In(zone)
+	if now.Weekday() != d.weekday || now.Hour() != settings.DigestHour(d.hour) {
+		return nil
+	}
+
+	userTokens, err := d.db.UserTokens(ctx, subscription.UserName)
+	if err != nil {
+		return fmt.Errorf("get usertokens: %w", err)
+	}
+
+	end := NewDate(now).AddDate(0, 0, -1)
+	start := end.AddDate(0, 0, 1-digestDays)
+
+	workouts, err := accountsWorkouts(ctx, d.fs, userTokens, start, end)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(subscription.ChatID,
+		MessageDigest(settings.Locale(""), NewWeekSummary(workouts), start, end))
+	if _, err := d.bot.Send(msg); err != nil {
+		return fmt.Errorf("send digest to chat %d: %w", subscription.ChatID, err)
+	}
+
+	return nil
+}
+
+func MessageDigest(loc *Locale, summary WeekSummary, start, end time.Time) string {
+	const metersInKm = 1000
+
+	digest := strings.Builder{}
+	digest.WriteString(loc.T(msgDigest, loc.Date(start), loc.Date(end)))
+	digest.WriteByte('\n')
+	digest.WriteString(loc.T(msgDigestSessions, summary.Sessions, summary.Completed, summary.Missed))
+	digest.WriteByte('\n')
+
+	if summary.Sessions > 0 {
+		digest.WriteString(loc.T(msgCompliance, summary.Compliance()))
+		digest.WriteByte('\n')
+	}
+
+	digest.WriteString(loc.T(msgDigestRestDays, summary.RestDays))
+	digest.WriteByte('\n')
+	digest.WriteString(loc.T(msgDigestDistance, summary.Planned.Distance/metersInKm, summary.Actual.Distance/metersInKm))
+	digest.WriteByte('\n')
+	digest.WriteString(loc.T(msgDigestTime, FormatDuration(summary.Planned.Duration),
+		FormatDuration(summary.Actual.Duration)))
+	digest.WriteByte('\n')
+
+	return digest.String()
+}
+
+// FormatDuration formats the duration as hours and minutes, for example "4:05".
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+
+	return fmt.Sprintf("%d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,119 @@
+package bot_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestDigest_Send(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	senderMock := mock.NewMockSender(ctrl)
+	fsMock := mock.NewMockFinalSurge(ctrl)
+	storageMock := mock.NewMockStorage(ctrl)
+	clockMock := mock.NewMockClock(ctrl)
+	digest := NewDigest(senderMock, storageMock, fsMock, clockMock, time.Sunday, 19)
+	const userName = "alexandear"
+	const chatID = int64(20)
+
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	now := time.Date(2020, time.December, 20, 19, 0, 0, 0, time.UTC)
+	start := time.Date(2020, time.December, 13, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, time.December, 19, 0, 0, 0, 0, time.UTC)
+	clockMock.EXPECT().Now().Return(now).Times(1)
+	storageMock.EXPECT().Subscriptions(gomock.Any()).
+		Return([]Subscription{{UserName: userName, ChatID: chatID}}, nil).Times(1)
+	storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return([]UserToken{userToken}, nil).Times(1)
+	fsMock.EXPECT().Workouts(gomock.Any(), userToken, start, end).Return([]Workout{
+		{
+			Date:      start,
+			Completed: true,
+			Planned:   Volume{Distance: 10000, Duration: time.Hour},
+			Actual:    Volume{Distance: 10500, Duration: 55 * time.Minute},
+		},
+		{
+			Date:    start.AddDate(0, 0, 1),
+			RestDay: true,
+		},
+		{
+			Date:    start.AddDate(0, 0, 2),
+			Planned: Volume{Distance: 8000, Duration: 45 * time.Minute},
+		},
+	}, nil).Times(1)
+	storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+	senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+		BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+		Text: `Weekly summary 13.12-19.12:
+Sessions: 2 planned, 1 completed, 1 missed
+Compliance: 50%
+Rest days: 1
+Distance: 18.0 km planned, 10.5 km completed
+Time: 1:45 planned, 0:55 completed
+`,
+	}).Times(1)
+
+	if err := digest.Send(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDigest_Send_userHour(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	senderMock := mock.NewMockSender(ctrl)
+	fsMock := mock.NewMockFinalSurge(ctrl)
+	storageMock := mock.NewMockStorage(ctrl)
+	clockMock := mock.NewMockClock(ctrl)
+	digest := NewDigest(senderMock, storageMock, fsMock, clockMock, time.Sunday, 19)
+	const userName = "alexandear"
+	const earlyUserName = "early"
+	const chatID = int64(20)
+
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	// It is Sunday 07:00 in Kyiv.
+	clockMock.EXPECT().Now().Return(time.Date(2020, time.December, 20, 5, 0, 0, 0, time.UTC)).Times(1)
+	storageMock.EXPECT().Subscriptions(gomock.Any()).Return([]Subscription{
+		{UserName: userName, ChatID: 10},
+		{UserName: earlyUserName, ChatID: chatID},
+	}, nil).Times(1)
+	hour := 7
+	storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).Times(1)
+	storageMock.EXPECT().Settings(gomock.Any(), earlyUserName).
+		Return(Settings{TimeZone: "Europe/Kyiv", Language: LanguageGerman, NotifyHour: &hour}, nil).Times(1)
+	storageMock.EXPECT().UserTokens(gomock.Any(), earlyUserName).Return([]UserToken{userToken}, nil).Times(1)
+	fsMock.EXPECT().Workouts(gomock.Any(), userToken, time.Date(2020, time.December, 13, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, time.December, 19, 0, 0, 0, 0, time.UTC)).Return(nil, nil).Times(1)
+	senderMock.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg tgbotapi.Chattable) (tgbotapi.Message, error) {
+		if m := msg.(tgbotapi.MessageConfig); m.ChatID != chatID || !strings.HasPrefix(m.Text, "Wochen") {
+			t.Errorf("unexpected digest %+v", m)
+		}
+
+		return tgbotapi.Message{}, nil
+	}).Times(1)
+
+	if err := digest.Send(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNextHour(t *testing.T) {
+	now := time.Date(2020, time.December, 20, 18, 59, 30, 0, time.UTC)
+	expected := time.Date(2020, time.December, 20, 19, 0, 0, 0, time.UTC)
+
+	if actual := NextHour(now); !actual.Equal(expected) {
+		t.Errorf("actual=%v, expected=%v", actual, expected)
+	}
+}
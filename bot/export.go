@@ -0,0 +1,180 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+
+	exportDateLayout = "2006-01-02"
+	exportChunkDays  = 31
+	exportMaxDays    = 366
+)
+
+var ErrExportArgs = errors.New("invalid export arguments")
+
+type ExportRange struct {
+	Start  time.Time
+	End    time.Time
+	Format string
+}
+
+// exportWorkout is a workout in the export. Distances are in meters and durations in seconds,
+// zero when FinalSurge has none.
+type exportWorkout struct {
+	Date            string `json:"date"`
+	Description     string `json:"description"`
+	RestDay         bool   `json:"rest_day"`
+	PlannedDistance int64  `json:"planned_distance_m"`
+	PlannedDuration int64  `json:"planned_duration_s"`
+	ActualDistance  int64  `json:"actual_distance_m"`
+	ActualDuration  int64  `json:"actual_duration_s"`
+	Completed       bool   `json:"completed"`
+}
+
+func newExportWorkout(w Workout) exportWorkout {
+	return exportWorkout{
+		Date:            w.Date.Format(exportDateLayout),
+		Description:     w.Description,
+		RestDay:         w.RestDay,
+		PlannedDistance: int64(math.Round(w.Planned.Distance)),
+		PlannedDuration: int64(w.Planned.Duration.Round(time.Second).Seconds()),
+		ActualDistance:  int64(math.Round(w.Actual.Distance)),
+		ActualDuration:  int64(w.Actual.Duration.Round(time.Second).Seconds()),
+		Completed:       w.Completed,
+	}
+}
+
+// ParseExportArgs parses arguments of the form "2020-01-01 2020-03-31 [csv|json]".
+func ParseExportArgs(args string) (ExportRange, error) {
+	fields := strings.Fields(args)
+	if len(fields) != 2 && len(fields) != 3 {
+		return ExportRange{}, ErrExportArgs
+	}
+
+	start, err := time.Parse(exportDateLayout, fields[0])
+	if err != nil {
+		return ExportRange{}, fmt.Errorf("%w: start date: %v", ErrExportArgs, err)
+	}
+
+	end, err := time.Parse(exportDateLayout, fields[1])
+	if err != nil {
+		return ExportRange{}, fmt.Errorf("%w: end date: %v", ErrExportArgs, err)
+	}
+
+	if end.Before(start) || end.Sub(start) >= exportMaxDays*24*time.Hour {
+		return ExportRange{}, fmt.Errorf("%w: range %s-%s", ErrExportArgs, fields[0], fields[1])
+	}
+
+	format := ExportFormatCSV
+	if len(fields) == 3 {
+		format = strings.ToLower(fields[2])
+	}
+
+	if format != ExportFormatCSV && format != ExportFormatJSON {
+		return ExportRange{}, fmt.Errorf("%w: format %s", ErrExportArgs, format)
+	}
+
+	return ExportRange{
+		Start:  start,
+		End:    end,
+		Format: format,
+	}, nil
+}
+
+func (r ExportRange) FileName() string {
+	return "workouts_" + r.Start.Format(exportDateLayout) + "_" + r.End.Format(exportDateLayout) + "." + r.Format
+}
+
+// ExportWorkouts requests workouts by chunks because FinalSurge is slow to answer for long ranges.
+func ExportWorkouts(ctx context.Context, fs FinalSurge, userToken UserToken, r ExportRange) ([]Workout, error) {
+	var workouts []Workout
+
+	for start := r.Start; !start.After(r.End); start = start.AddDate(0, 0, exportChunkDays) {
+		end := start.AddDate(0, 0, exportChunkDays-1)
+		if end.After(r.End) {
+			end = r.End
+		}
+
+		chunk, err := fs.Workouts(ctx, userToken, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("get workouts %s-%s: %w", finalSurgeDate(start), finalSurgeDate(end), err)
+		}
+
+		workouts = append(workouts, chunk...)
+	}
+
+	return workouts, nil
+}
+
+func MarshalWorkouts(workouts []Workout, format string) ([]byte, error) {
+	switch format {
+	case ExportFormatCSV:
+		return marshalWorkoutsCSV(workouts)
+	case ExportFormatJSON:
+		return marshalWorkoutsJSON(workouts)
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+}
+
+func marshalWorkoutsCSV(workouts []Workout) ([]byte, error) {
+	buf := bytes.Buffer{}
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write([]string{
+		"date", "description", "rest_day", "planned_distance_m", "planned_duration_s",
+		"actual_distance_m", "actual_duration_s", "completed",
+	}); err != nil {
+		return nil, fmt.Errorf("write header: %w", err)
+	}
+
+	for _, workout := range workouts {
+		e := newExportWorkout(workout)
+		if err := w.Write([]string{
+			e.Date,
+			e.Description,
+			strconv.FormatBool(e.RestDay),
+			strconv.FormatInt(e.PlannedDistance, 10),
+			strconv.FormatInt(e.PlannedDuration, 10),
+			strconv.FormatInt(e.ActualDistance, 10),
+			strconv.FormatInt(e.ActualDuration, 10),
+			strconv.FormatBool(e.Completed),
+		}); err != nil {
+			return nil, fmt.Errorf("write record: %w", err)
+		}
+	}
+
+	w.Flush()
+
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("flush: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func marshalWorkoutsJSON(workouts []Workout) ([]byte, error) {
+	data := make([]exportWorkout, 0, len(workouts))
+	for _, w := range workouts {
+		data = append(data, newExportWorkout(w))
+	}
+
+	bs, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	return bs, nil
+}
@@ -0,0 +1,153 @@
+package bot_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestBot_ProcessUpdate_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	senderMock := mock.NewMockSender(ctrl)
+	fsMock := mock.NewMockFinalSurge(ctrl)
+	storageMock := mock.NewMockStorage(ctrl)
+	bot := NewBot(senderMock, storageMock, fsMock, nil, &Config{}, NewTaskTemplates())
+	const userName = "alexandear"
+	const chatID = int64(20)
+	storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+	storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	storageMock.EXPECT().UserToken(gomock.Any(), userName).Return(userToken, nil).Times(1)
+	gomock.InOrder(
+		fsMock.EXPECT().Workouts(gomock.Any(), userToken,
+			time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, time.January, 31, 0, 0, 0, 0, time.UTC)).
+			Return([]Workout{{
+				Date:        time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC),
+				Description: "6x800m, 400m jog",
+				Completed:   true,
+				Planned:     Volume{Distance: 10000, Duration: 50 * time.Minute},
+				Actual:      Volume{Distance: 10240.4, Duration: 49*time.Minute + 30*time.Second},
+			}}, nil).Times(1),
+		fsMock.EXPECT().Workouts(gomock.Any(), userToken,
+			time.Date(2020, time.February, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, time.February, 10, 0, 0, 0, 0, time.UTC)).
+			Return([]Workout{{
+				Date:        time.Date(2020, time.February, 3, 0, 0, 0, 0, time.UTC),
+				Description: "Rest Day",
+				RestDay:     true,
+			}}, nil).Times(1),
+	)
+	senderMock.EXPECT().Send(tgbotapi.NewDocumentUpload(chatID, tgbotapi.FileBytes{
+		Name: "workouts_2020-01-01_2020-02-10.csv",
+		Bytes: []byte(`date,description,rest_day,planned_distance_m,planned_duration_s,actual_distance_m,actual_duration_s,completed
+2020-01-02,"6x800m, 400m jog",false,10000,3000,10240,2970,true
+2020-02-03,Rest Day,true,0,0,0,0,false
+`),
+	})).Times(1)
+
+	const command = "/export 2020-01-01 2020-02-10 csv"
+	if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: chatID},
+			From:     &tgbotapi.User{UserName: userName},
+			Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/export")}},
+			Text:     command,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMarshalWorkouts_JSON(t *testing.T) {
+	actual, err := MarshalWorkouts([]Workout{{
+		Date:        time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC),
+		Description: "10 km",
+		Completed:   true,
+		Planned:     Volume{Distance: 10000, Duration: 50 * time.Minute},
+		Actual:      Volume{Distance: 10240.4, Duration: 49*time.Minute + 30*time.Second},
+	}}, ExportFormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const expected = `[
+  {
+    "date": "2020-01-02",
+    "description": "10 km",
+    "rest_day": false,
+    "planned_distance_m": 10000,
+    "planned_duration_s": 3000,
+    "actual_distance_m": 10240,
+    "actual_duration_s": 2970,
+    "completed": true
+  }
+]`
+	if string(actual) != expected {
+		t.Errorf("actual=%s, expected=%s", actual, expected)
+	}
+}
+
+func TestParseExportArgs(t *testing.T) {
+	for name, tc := range map[string]struct {
+		args     string
+		expected ExportRange
+		err      error
+	}{
+		"default format": {
+			args: "2020-01-01 2020-03-31",
+			expected: ExportRange{
+				Start:  time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2020, time.March, 31, 0, 0, 0, 0, time.UTC),
+				Format: ExportFormatCSV,
+			},
+		},
+		"json": {
+			args: "2020-01-01 2020-01-01 JSON",
+			expected: ExportRange{
+				Start:  time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+				End:    time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC),
+				Format: ExportFormatJSON,
+			},
+		},
+		"empty": {
+			args: "",
+			err:  ErrExportArgs,
+		},
+		"end before start": {
+			args: "2020-03-01 2020-01-01",
+			err:  ErrExportArgs,
+		},
+		"longer than year": {
+			args: "2020-01-01 2021-01-01",
+			err:  ErrExportArgs,
+		},
+		"unknown format": {
+			args: "2020-01-01 2020-01-02 xlsx",
+			err:  ErrExportArgs,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseExportArgs(tc.args)
+
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("actual err=%v, expected=%v", err, tc.err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("actual=%+v, expected=%+v", actual, tc.expected)
+			}
+		})
+	}
+}
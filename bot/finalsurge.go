@@ -18,6 +18,10 @@ const (
 	finalSurgeAPI = "https://beta.finalsurge.com/api"
 
 	activityTypeNameRestDay = "Rest Day"
+	activityTypeNameRace    = "race"
+
+	// workoutCompletionNone means the workout is not completed. Other values mean completed fully or partially.
+	workoutCompletionNone = 0
 )
 
 type FinalSurgeAPI struct {
@@ -55,13 +59,21 @@ type FinalSurgeWorkoutList struct {
 }
 
 type FinalSurgeWorkoutData struct {
-	WorkoutDate string               `json:"workout_date"`
-	Description *string              `json:"description"`
-	Activities  []FinalSurgeActivity `json:"activities"`
+	WorkoutDate       string               `json:"workout_date"`
+	Name              *string              `json:"name"`
+	Description       *string              `json:"description"`
+	WorkoutCompletion int                  `json:"workout_completion"`
+	Activities        []FinalSurgeActivity `json:"activities"`
 }
 
 type FinalSurgeActivity struct {
-	ActivityTypeName string `json:"activity_type_name"`
+	ActivityTypeName  string   `json:"activity_type_name"`
+	PlannedAmount     *float64 `json:"planned_amount"`
+	PlannedAmountType *string  `json:"planned_amount_type"`
+	PlannedDuration   *float64 `json:"planned_duration"`
+	Amount            *float64 `json:"amount"`
+	AmountType        *string  `json:"amount_type"`
+	Duration          *float64 `json:"duration"`
 }
 
 type FinalSurgeStatus struct {
@@ -158,6 +170,13 @@ func (f *FinalSurgeAPI) Workouts(ctx context.Context, userToken UserToken, start
 		workouts = append(workouts, Workout{
 			Date:        NewDate(date),
 			Description: desc(w),
+			RestDay:     isRestDay(w),
+			Activity:    activityTypeName(w),
+			Name:        stringValue(w.Name),
+			Race:        isRace(w),
+			Completed:   w.WorkoutCompletion != workoutCompletionNone,
+			Planned:     plannedVolume(w),
+			Actual:      actualVolume(w),
 		})
 	}
 
@@ -209,10 +228,79 @@ func isRestDay(data FinalSurgeWorkoutData) bool {
 	return len(data.Activities) == 1 && strings.EqualFold(data.Activities[0].ActivityTypeName, activityTypeNameRestDay)
 }
 
+// isRace matches activity types such as "Race" or "Run Race".
+func isRace(data FinalSurgeWorkoutData) bool {
+	for _, a := range data.Activities {
+		if strings.Contains(strings.ToLower(a.ActivityTypeName), activityTypeNameRace) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func activityTypeName(data FinalSurgeWorkoutData) string {
+	if len(data.Activities) == 0 {
+		return ""
+	}
+
+	return data.Activities[0].ActivityTypeName
+}
+
+func plannedVolume(data FinalSurgeWorkoutData) Volume {
+	var v Volume
+	for _, a := range data.Activities {
+		v = v.Add(newVolume(a.PlannedAmount, a.PlannedAmountType, a.PlannedDuration))
+	}
+
+	return v
+}
+
+func actualVolume(data FinalSurgeWorkoutData) Volume {
+	var v Volume
+	for _, a := range data.Activities {
+		v = v.Add(newVolume(a.Amount, a.AmountType, a.Duration))
+	}
+
+	return v
+}
+
+// newVolume converts the amount and the duration in seconds. Unknown amount types are ignored.
+func newVolume(amount *float64, amountType *string, duration *float64) Volume {
+	var v Volume
+
+	if amount != nil && amountType != nil {
+		v.Distance = *amount * unitMeters[strings.ToLower(*amountType)]
+	}
+
+	if duration != nil {
+		v.Duration = time.Duration(*duration * float64(time.Second))
+	}
+
+	return v
+}
+
+// FinalSurgeError is the error reported in the status of a FinalSurge response.
+type FinalSurgeError struct {
+	Number      int
+	Description string
+}
+
+func (e *FinalSurgeError) Error() string {
+	return fmt.Sprintf("final surge error: number=%d desc=%s", e.Number, e.Description)
+}
+
 func newFinalSurgeError(status FinalSurgeStatus) error {
 	if !status.Success && status.ErrorNumber != nil && status.ErrorDescription != nil {
-		return fmt.Errorf("final surge error: number=%d desc=%s", *status.ErrorNumber,
-			*status.ErrorDescription)
+		return &FinalSurgeError{Number: *status.ErrorNumber, Description: *status.ErrorDescription}
 	}
 
 	return nil
@@ -2,8 +2,11 @@ package bot
 
 import (
 	"context"
+	"errors"
+	"io"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -51,6 +54,85 @@ func TestFinalSurgeAPI_Workouts(t *testing.T) {
 	}
 }
 
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFinalSurgeAPI_Workouts_volume(t *testing.T) {
+	fs := NewFinalSurgeAPI(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{
+  "success": true,
+  "data": [
+    {
+      "workout_date": "2020-12-14T00:00:00",
+      "description": "Easy",
+      "workout_completion": 1,
+      "activities": [
+        {
+          "activity_type_name": "Run",
+          "planned_amount": 10,
+          "planned_amount_type": "km",
+          "planned_duration": 3600,
+          "amount": 6.5,
+          "amount_type": "mi",
+          "duration": 3300
+        }
+      ]
+    },
+    {
+      "workout_date": "2020-12-15T00:00:00",
+      "workout_completion": 0,
+      "activities": [{"activity_type_name": "Rest Day"}]
+    }
+  ]
+}`))}, nil
+	})})
+
+	workouts, err := fs.Workouts(context.Background(), UserToken{}, time.Now(), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(workouts) != 2 {
+		t.Fatalf("actual=%d workouts, expected=2", len(workouts))
+	}
+
+	expected := Workout{
+		Date:        time.Date(2020, time.December, 14, 0, 0, 0, 0, time.UTC),
+		Description: "Easy",
+		Activity:    "Run",
+		Completed:   true,
+		Planned:     Volume{Distance: 10000, Duration: time.Hour},
+		Actual:      Volume{Distance: 6.5 * 1609.344, Duration: 55 * time.Minute},
+	}
+	if workouts[0] != expected {
+		t.Errorf("actual=%+v, expected=%+v", workouts[0], expected)
+	}
+
+	if !workouts[1].RestDay || workouts[1].Completed {
+		t.Errorf("actual=%+v, expected not completed rest day", workouts[1])
+	}
+}
+
+func TestFinalSurgeAPI_Login_error(t *testing.T) {
+	fs := NewFinalSurgeAPI(&http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(`{
+  "success": false,
+  "error_number": 3,
+  "error_description": "Invalid email or password"
+}`))}, nil
+	})})
+
+	_, err := fs.Login(context.Background(), "runner@example.com", "wrong")
+
+	var fsErr *FinalSurgeError
+	if !errors.As(err, &fsErr) || fsErr.Number != 3 || fsErr.Description != "Invalid email or password" {
+		t.Errorf("actual err=%v, expected FinalSurgeError", err)
+	}
+}
+
 func finalSurgeCred() (email, password string) {
 	email = os.Getenv("FINAL_SURGE_EMAIL")
 	password = os.Getenv("FINAL_SURGE_PASSWORD")
@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	LanguageEnglish   = "en"
+	LanguageUkrainian = "uk"
+	LanguageGerman    = "de"
+
+	// LanguageAuto detects the language from the Telegram client.
+	LanguageAuto = "auto"
+)
+
+// Locale holds translated messages and date formatting of a language.
+type Locale struct {
+	Language   string
+	Name       string
+	DateLayout string
+	Weekdays   [7]string
+
+	messages map[string]string
+}
+
+// Languages lists supported languages in the order they are shown to users.
+var Languages = []string{LanguageEnglish, LanguageUkrainian, LanguageGerman}
+
+// LocaleFor returns the locale for the IETF language tag such as "de" or "de-AT".
+// English is returned for unsupported languages.
+func LocaleFor(languageCode string) *Locale {
+	language, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+	if l, ok := locales[language]; ok {
+		return l
+	}
+
+	return locales[LanguageEnglish]
+}
+
+// UserLocale returns the locale chosen by the user with /language,
+// or the one detected from languageCode of the Telegram client otherwise.
+func UserLocale(ctx context.Context, db SettingsStore, userName, languageCode string) (*Locale, error) {
+	settings, err := UserSettings(ctx, db, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings.Locale(languageCode), nil
+}
+
+// IsSupportedLanguage reports whether the language has its own locale.
+func IsSupportedLanguage(language string) bool {
+	_, ok := locales[language]
+
+	return ok
+}
+
+// T returns the message translated to the locale language and formatted with args.
+// Missing translations fall back to English.
+func (l *Locale) T(key string, args ...interface{}) string {
+	format, ok := l.messages[key]
+	if !ok {
+		format, ok = locales[LanguageEnglish].messages[key]
+	}
+
+	if !ok {
+		format = key
+	}
+
+	if len(args) == 0 {
+		return format
+	}
+
+	return fmt.Sprintf(format, args...)
+}
+
+// Date formats the day of month and month, for example "20.12".
+func (l *Locale) Date(t time.Time) string {
+	return t.Format(l.DateLayout)
+}
+
+// Weekday returns the localized day of the week.
+func (l *Locale) Weekday(t time.Time) string {
+	return l.Weekdays[t.Weekday()]
+}
@@ -0,0 +1,109 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// inlineCacheTime is in seconds. Plans rarely change within a minute.
+const inlineCacheTime = 60
+
+// inlineQuery answers "@final_surge_bot <plan args>" with the plan that can be sent to any chat.
+// Arguments are the same as of /plan. Empty and incomplete ones, typed so far, show today and tomorrow.
+func (b *Bot) inlineQuery(ctx context.Context, query *tgbotapi.InlineQuery) error {
+	config, err := b.inlineConfig(ctx, query)
+	if err != nil {
+		return fmt.Errorf("get inline answer: %w", err)
+	}
+
+	if _, err := b.bot.AnswerInlineQuery(config); err != nil {
+		return fmt.Errorf("answer inline query %s: %w", query.ID, err)
+	}
+
+	return nil
+}
+
+func (b *Bot) inlineConfig(ctx context.Context, query *tgbotapi.InlineQuery) (tgbotapi.InlineConfig, error) {
+	userName := query.From.UserName
+	config := tgbotapi.InlineConfig{
+		InlineQueryID: query.ID,
+		Results:       []interface{}{},
+		CacheTime:     inlineCacheTime,
+		IsPersonal:    true,
+	}
+
+	loc, err := UserLocale(ctx, b.db, userName, query.From.LanguageCode)
+	if err != nil {
+		return config, fmt.Errorf("get locale: %w", err)
+	}
+
+	userTokens, err := b.db.UserTokens(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		// Opens the private chat with "/start default" that asks for FinalSurge credentials.
+		config.SwitchPMText = loc.T(msgInlineLogin)
+		config.SwitchPMParameter = DefaultAccountLabel
+
+		return config, nil
+	}
+
+	if err != nil {
+		return config, fmt.Errorf("get usertokens: %w", err)
+	}
+
+	today, err := b.userToday(ctx, userName)
+	if err != nil {
+		return config, err
+	}
+
+	planRange := PlanRange{Start: today, End: today.AddDate(0, 0, 1)}
+	if strings.TrimSpace(query.Query) != "" {
+		if r, errParse := ParsePlanArgs(query.Query, today); errParse == nil {
+			planRange = r
+		}
+	}
+
+	var workouts []Workout
+
+	for _, userToken := range userTokens {
+		accountWorkouts, err := b.fs.Workouts(ctx, userToken, planRange.Start, planRange.End)
+		if err != nil {
+			return config, fmt.Errorf("get workouts: %w", err)
+		}
+
+		workouts = append(workouts, accountWorkouts...)
+	}
+
+	task, parseMode, err := b.renderTask(ctx, userName, NewPlanView(loc, workouts, planRange, today))
+	if err != nil {
+		return config, err
+	}
+
+	title := loc.T(msgInlineTitle, loc.Date(planRange.Start))
+	if !planRange.End.Equal(planRange.Start) {
+		title = loc.T(msgInlineTitle, loc.Date(planRange.Start)+"-"+loc.Date(planRange.End))
+	}
+
+	article := tgbotapi.NewInlineQueryResultArticle(
+		planRange.Start.Format(exportDateLayout)+"_"+planRange.End.Format(exportDateLayout), title, task)
+	article.InputMessageContent = tgbotapi.InputTextMessageContent{Text: task, ParseMode: parseMode}
+	article.Description = inlineDescription(loc, workouts)
+
+	config.Results = append(config.Results, article)
+
+	return config, nil
+}
+
+// inlineDescription previews the first workout under the title of the result.
+func inlineDescription(loc *Locale, workouts []Workout) string {
+	for _, w := range workouts {
+		if line, _, _ := strings.Cut(w.Description, "\n"); line != "" {
+			return line
+		}
+	}
+
+	return loc.T(msgNotSet)
+}
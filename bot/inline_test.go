@@ -0,0 +1,89 @@
+package bot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestBot_ProcessUpdate_InlineQuery(t *testing.T) {
+	const userName = "alexandear"
+
+	t.Run("plan", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		fsMock := mock.NewMockFinalSurge(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		clockMock := mock.NewMockClock(ctrl)
+		bot := NewBot(senderMock, storageMock, fsMock, clockMock, &Config{}, NewTaskTemplates())
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{TaskFormat: TaskFormatCompact}, nil).AnyTimes()
+
+		userToken := UserToken{
+			UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+			Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+		}
+		tuesday := time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)
+		clockMock.EXPECT().Now().Return(time.Date(2026, time.October, 20, 8, 0, 0, 0, time.UTC)).Times(1)
+		storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return([]UserToken{userToken}, nil).Times(1)
+		fsMock.EXPECT().Workouts(gomock.Any(), userToken, tuesday, tuesday).
+			Return([]Workout{{Date: tuesday, Description: "Tempo 8 km\n2 km warm-up", Activity: "Run"}}, nil).Times(1)
+
+		const text = "<b>Tasks:</b>\n<b>Today 20.10:</b> 🏃 Tempo 8 km\n"
+		senderMock.EXPECT().AnswerInlineQuery(tgbotapi.InlineConfig{
+			InlineQueryID: "42",
+			Results: []interface{}{tgbotapi.InlineQueryResultArticle{
+				Type:                "article",
+				ID:                  "2026-10-20_2026-10-20",
+				Title:               "Workouts 20.10",
+				InputMessageContent: tgbotapi.InputTextMessageContent{Text: text, ParseMode: tgbotapi.ModeHTML},
+				Description:         "Tempo 8 km",
+			}},
+			CacheTime:  60,
+			IsPersonal: true,
+		}).Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+			InlineQuery: &tgbotapi.InlineQuery{
+				ID:    "42",
+				From:  &tgbotapi.User{UserName: userName},
+				Query: "today",
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("not authorized", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		bot := NewBot(senderMock, storageMock, nil, nil, &Config{}, NewTaskTemplates())
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+
+		storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return(nil, ErrNotFound).Times(1)
+		senderMock.EXPECT().AnswerInlineQuery(tgbotapi.InlineConfig{
+			InlineQueryID:     "42",
+			Results:           []interface{}{},
+			CacheTime:         60,
+			IsPersonal:        true,
+			SwitchPMText:      "Log in to FinalSurge",
+			SwitchPMParameter: DefaultAccountLabel,
+		}).Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+			InlineQuery: &tgbotapi.InlineQuery{
+				ID:   "42",
+				From: &tgbotapi.User{UserName: userName},
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
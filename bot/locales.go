@@ -0,0 +1,666 @@
+package bot
+
+const (
+	msgEnterEmail            = "enter_email"
+	msgEnterPassword         = "enter_password"
+	msgChooseOption          = "choose_option"
+	msgAuthorizeFirst        = "authorize_first"
+	msgTasks                 = "tasks"
+	msgToday                 = "today"
+	msgTomorrow              = "tomorrow"
+	msgNotSet                = "not_set"
+	msgSubscribed            = "subscribed"
+	msgUnsubscribed          = "unsubscribed"
+	msgCalendarLink          = "calendar_link"
+	msgExportUsage           = "export_usage"
+	msgInvalidAccountLabel   = "invalid_account_label"
+	msgSwitchedAccount       = "switched_account"
+	msgMergedAccounts        = "merged_accounts"
+	msgUnlinkedAccount       = "unlinked_account"
+	msgAccountNotFound       = "account_not_found"
+	msgNoAccounts            = "no_accounts"
+	msgAccounts              = "accounts"
+	msgActiveAccount         = "active_account"
+	msgAccountsUsage         = "accounts_usage"
+	msgLoggedOut             = "logged_out"
+	msgDataDeleted           = "data_deleted"
+	msgNothingStored         = "nothing_stored"
+	msgPlanChanged           = "plan_changed"
+	msgWorkoutAdded          = "workout_added"
+	msgWorkoutRemoved        = "workout_removed"
+	msgWorkoutEdited         = "workout_edited"
+	msgBefore                = "before"
+	msgAfter                 = "after"
+	msgLanguage              = "language"
+	msgLanguageUsage         = "language_usage"
+	msgMarkup                = "markup"
+	msgMarkupUsage           = "markup_usage"
+	msgFormat                = "format"
+	msgFormatUsage           = "format_usage"
+	msgDigest                = "digest"
+	msgDigestSessions        = "digest_sessions"
+	msgDigestRestDays        = "digest_rest_days"
+	msgDigestDistance        = "digest_distance"
+	msgDigestTime            = "digest_time"
+	msgChartPerDay           = "chart_per_day"
+	msgChartPerWeek          = "chart_per_week"
+	msgChartUsage            = "chart_usage"
+	msgRaceCountdown         = "race_countdown"
+	msgRaceToday             = "race_today"
+	msgRaceTomorrow          = "race_tomorrow"
+	msgRaces                 = "races"
+	msgNoRaces               = "no_races"
+	msgDaysLeft              = "days_left"
+	msgRacesUsage            = "races_usage"
+	msgGoalRacePinned        = "goal_race_pinned"
+	msgGoalRaceUnpinned      = "goal_race_unpinned"
+	msgPlanUsage             = "plan_usage"
+	msgTimeZone              = "timezone"
+	msgTimeZoneUsage         = "timezone_usage"
+	msgInlineTitle           = "inline_title"
+	msgInlineLogin           = "inline_login"
+	msgStats                 = "stats"
+	msgBroadcastUsage        = "broadcast_usage"
+	msgBroadcastStarted      = "broadcast_started"
+	msgBroadcastDone         = "broadcast_done"
+	msgUserUsage             = "user_usage"
+	msgUserNotFound          = "user_not_found"
+	msgUserInfo              = "user_info"
+	msgUserInfoInactive      = "user_info_inactive"
+	msgUserNotLinked         = "user_not_linked"
+	msgYes                   = "yes"
+	msgNo                    = "no"
+	msgHelp                  = "help"
+	msgUnknownCommand        = "unknown_command"
+	msgCommandTask           = "command_task"
+	msgCommandPlan           = "command_plan"
+	msgCommandRaces          = "command_races"
+	msgCommandChart          = "command_chart"
+	msgCommandExport         = "command_export"
+	msgCommandCalendar       = "command_calendar"
+	msgCommandSubscribe      = "command_subscribe"
+	msgCommandUnsubscribe    = "command_unsubscribe"
+	msgCommandStart          = "command_start"
+	msgCommandAccounts       = "command_accounts"
+	msgCommandLanguage       = "command_language"
+	msgCommandTimeZone       = "command_timezone"
+	msgCommandMarkup         = "command_markup"
+	msgCommandFormat         = "command_format"
+	msgCommandLogout         = "command_logout"
+	msgCommandForgetMe       = "command_forgetme"
+	msgCommandHelp           = "command_help"
+	msgCommandStats          = "command_stats"
+	msgCommandBroadcast      = "command_broadcast"
+	msgCommandUser           = "command_user"
+	msgDialogTimeout         = "dialog_timeout"
+	msgDialogCancelled       = "dialog_cancelled"
+	msgNoDialog              = "no_dialog"
+	msgCommandBack           = "command_back"
+	msgCommandCancel         = "command_cancel"
+	msgInvalidEmail          = "invalid_email"
+	msgLoginWrongPassword    = "login_wrong_password"
+	msgLoginLocked           = "login_locked"
+	msgLoginRejected         = "login_rejected"
+	msgFinalSurgeUnavailable = "final_surge_unavailable"
+	msgFinalSurgeError       = "final_surge_error"
+	msgInternalError         = "internal_error"
+	msgCommandSettings       = "command_settings"
+	msgSettings              = "settings"
+	msgSettingsLanguage      = "settings_language"
+	msgSettingsTimeZone      = "settings_timezone"
+	msgSettingsTimeZoneHint  = "settings_timezone_hint"
+	msgSettingsNotifyHour    = "settings_notify_hour"
+	msgSettingsFormat        = "settings_format"
+	msgSettingsMarkup        = "settings_markup"
+	msgSettingsUnits         = "settings_units"
+	msgSettingsDays          = "settings_days"
+	msgSettingsChoose        = "settings_choose"
+	msgSettingsAuto          = "settings_auto"
+	msgSettingsDefault       = "settings_default"
+	msgSettingsBack          = "settings_back"
+	msgSettingsSaved         = "settings_saved"
+	msgSettingsOutdated      = "settings_outdated"
+	msgCommandSteps          = "command_steps"
+	msgSteps                 = "steps"
+	msgStepsUsage            = "steps_usage"
+	msgStepsTotal            = "steps_total"
+	msgNoSteps               = "no_steps"
+	msgStepWarmup            = "step_warmup"
+	msgStepSteady            = "step_steady"
+	msgStepInterval          = "step_interval"
+	msgStepRecovery          = "step_recovery"
+	msgStepCooldown          = "step_cooldown"
+	msgStepRepeat            = "step_repeat"
+	msgCommandExportWorkout  = "command_export_workout"
+	msgExportWorkoutUsage    = "export_workout_usage"
+	msgExportWorkoutNone     = "export_workout_none"
+	msgExportWorkoutSteps    = "export_workout_steps"
+	msgCommandYesterday      = "command_yesterday"
+	msgYesterday             = "yesterday"
+	msgWorkoutDone           = "workout_done"
+	msgWorkoutDoneVolume     = "workout_done_volume"
+	msgWorkoutDonePlanned    = "workout_done_planned"
+	msgWorkoutNotLogged      = "workout_not_logged"
+	msgCompliance            = "compliance"
+	msgReminder              = "reminder"
+	msgSettingsRemindHour    = "settings_remind_hour"
+	msgSettingsOff           = "settings_off"
+	msgTablePrefix           = "table_"
+	msgTableAccounts         = msgTablePrefix + "accounts"
+	msgTableSubscriptions    = msgTablePrefix + "subscriptions"
+	msgTableSnapshots        = msgTablePrefix + "workout_snapshots"
+	msgTableCalendarTokens   = msgTablePrefix + "calendar_tokens"
+	msgTableUserSettings     = msgTablePrefix + "user_settings"
+	msgTableGoalRaces        = msgTablePrefix + "goal_races"
+	msgTableUserActivity     = msgTablePrefix + "user_activity"
+)
+
+var locales = map[string]*Locale{
+	LanguageEnglish: {
+		Language:   LanguageEnglish,
+		Name:       "English",
+		DateLayout: "02.01",
+		Weekdays:   [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		messages: map[string]string{
+			msgEnterEmail:     "Enter FinalSurge email:",
+			msgEnterPassword:  "Enter FinalSurge password:",
+			msgChooseOption:   "Choose option:",
+			msgAuthorizeFirst: "Please authorize first by entering /start",
+			msgTasks:          "Tasks:",
+			msgToday:          "Today",
+			msgTomorrow:       "Tomorrow",
+			msgNotSet:         "not set",
+			msgSubscribed:     "You will be notified when upcoming workouts change",
+			msgUnsubscribed:   "Notifications about workout changes are turned off",
+			msgCalendarLink: "Subscribe to your workouts in Google or Apple Calendar:\n%s\n\n" +
+				"Keep the link secret. Enter /calendar reset to get a new one.",
+			msgExportUsage: "Usage: /export 2020-01-01 2020-12-31 [csv|json]\n" +
+				"The range must not exceed one year.",
+			msgInvalidAccountLabel: "Account label must be up to 20 letters, digits, '-' or '_'. " +
+				"For example: /start club",
+			msgSwitchedAccount: "Switched to account %s",
+			msgMergedAccounts:  "Plans from all accounts will be merged",
+			msgUnlinkedAccount: "Unlinked account %s",
+			msgAccountNotFound: "Account %s not found",
+			msgNoAccounts:      "No linked accounts. Link one by entering /start",
+			msgAccounts:        "Accounts:",
+			msgActiveAccount:   " (active)",
+			msgAccountsUsage: "/start <label> - link another account\n" +
+				"/accounts use <label> - switch the active account\n" +
+				"/accounts all - merge plans from all accounts\n" +
+				"/accounts unlink <label> - unlink the account\n",
+			msgLoggedOut:      "Logged out. The bot no longer has access to your FinalSurge accounts.",
+			msgDataDeleted:    "All your data is deleted:",
+			msgNothingStored:  "The bot no longer stores anything about you.",
+			msgPlanChanged:    "Plan changed:",
+			msgWorkoutAdded:   "Added %s:",
+			msgWorkoutRemoved: "Removed %s:",
+			msgWorkoutEdited:  "Edited %s:",
+			msgBefore:         "Before:",
+			msgAfter:          "After:",
+			msgLanguage:       "Language: %s",
+			msgLanguageUsage:  "Usage: /language en|uk|de|auto",
+			msgMarkup:         "Task messages are formatted as %s",
+			msgMarkupUsage:    "Usage: /markup html|plain",
+			msgFormat:         "Task format: %s",
+			msgFormatUsage:    "Usage: /format %s",
+			msgDigest:         "Weekly summary %s-%s:",
+			msgDigestSessions: "Sessions: %d planned, %d completed, %d missed",
+			msgDigestRestDays: "Rest days: %d",
+			msgDigestDistance: "Distance: %.1f km planned, %.1f km completed",
+			msgDigestTime:     "Time: %s planned, %s completed",
+			msgChartPerDay:    "Planned distance per day, km, %s-%s",
+			msgChartPerWeek:   "Planned distance per week, km, %s-%s",
+			msgChartUsage:     "Usage: /chart [day|week] [weeks]\nUp to 26 weeks, 8 by default.",
+			msgRaceCountdown:  "Race in %d days: %s",
+			msgRaceToday:      "Race today: %s",
+			msgRaceTomorrow:   "Race tomorrow: %s",
+			msgRaces:          "Upcoming races:",
+			msgNoRaces:        "No upcoming races in the next 6 months.",
+			msgDaysLeft:       "%d days left",
+			msgRacesUsage: "/races pin 2021-04-18 City Marathon - pin the goal race\n" +
+				"/races unpin - unpin the goal race",
+			msgGoalRacePinned:   "Goal race: %s %s",
+			msgGoalRaceUnpinned: "Goal race is unpinned",
+			msgPlanUsage: "Usage: /plan today|tomorrow|sat|2026-10-20|20.10-26.10|week|next week|3d\n" +
+				"Up to 31 days.",
+			msgTimeZone:              "Time zone: %s",
+			msgTimeZoneUsage:         "Usage: /timezone Europe/Kyiv",
+			msgInlineTitle:           "Workouts %s",
+			msgInlineLogin:           "Log in to FinalSurge",
+			msgStats:                 "Authorized users: %d\nActive in the last day: %d\nActive in the last week: %d\nFinalSurge requests: %d, errors: %d (%.1f%%)",
+			msgBroadcastUsage:        "Usage: /broadcast <text>",
+			msgBroadcastStarted:      "Sending to %d chats...",
+			msgBroadcastDone:         "Broadcast done: %d sent, %d failed",
+			msgUserUsage:             "Usage: /user <Telegram ID or @username>",
+			msgUserNotFound:          "User not found",
+			msgUserInfo:              "@%s, ID %d, chat %d\nLast active: %s\nAccounts: %s\nSubscribed: %s",
+			msgUserInfoInactive:      "@%s, no messages since activity tracking started\nAccounts: %s\nSubscribed: %s",
+			msgUserNotLinked:         "not linked",
+			msgYes:                   "yes",
+			msgNo:                    "no",
+			msgHelp:                  "Commands:",
+			msgUnknownCommand:        "Unknown command /%s. Enter /help to see all commands.",
+			msgCommandTask:           "Workouts for today and tomorrow",
+			msgCommandPlan:           "Workouts for the dates",
+			msgCommandRaces:          "Upcoming races and the goal race",
+			msgCommandChart:          "Chart of planned distance",
+			msgCommandExport:         "Export workouts to a file",
+			msgCommandCalendar:       "Link to subscribe in a calendar app",
+			msgCommandSubscribe:      "Notify about plan changes and send the weekly summary",
+			msgCommandUnsubscribe:    "Stop notifications",
+			msgCommandStart:          "Link a FinalSurge account",
+			msgCommandAccounts:       "Manage linked accounts",
+			msgCommandLanguage:       "Language of messages",
+			msgCommandTimeZone:       "Time zone for today and tomorrow",
+			msgCommandMarkup:         "Formatting of task messages",
+			msgCommandFormat:         "Template of task messages",
+			msgCommandLogout:         "Unlink all FinalSurge accounts",
+			msgCommandForgetMe:       "Delete all my data",
+			msgCommandHelp:           "List of commands",
+			msgCommandStats:          "Usage statistics",
+			msgCommandBroadcast:      "Send a message to all users",
+			msgCommandUser:           "Link status of the user",
+			msgDialogTimeout:         "The dialog is cancelled because there was no answer for too long. Start again.",
+			msgDialogCancelled:       "Cancelled",
+			msgNoDialog:              "There is no question to answer",
+			msgCommandBack:           "Go back to the previous question",
+			msgCommandCancel:         "Cancel the current question",
+			msgInvalidEmail:          "This does not look like an email. Enter FinalSurge email, for example runner@example.com:",
+			msgLoginWrongPassword:    "Wrong email or password. Enter /back to change the email.",
+			msgLoginLocked:           "The FinalSurge account is locked. Unlock it on finalsurge.com and enter /start again.",
+			msgLoginRejected:         "FinalSurge rejected the login: %s. Enter /start to try again.",
+			msgFinalSurgeUnavailable: "FinalSurge does not respond. Try again later.",
+			msgFinalSurgeError:       "FinalSurge returned an error: %s. Try again later.",
+			msgInternalError:         "Something went wrong. Try again later.",
+			msgCommandSettings:       "Change settings with buttons",
+			msgSettings:              "Settings:",
+			msgSettingsLanguage:      "Language",
+			msgSettingsTimeZone:      "Time zone",
+			msgSettingsTimeZoneHint:  "Other zones can be set with /timezone Area/City.",
+			msgSettingsNotifyHour:    "Weekly summary time",
+			msgSettingsFormat:        "Message format",
+			msgSettingsMarkup:        "Markup",
+			msgSettingsUnits:         "Units",
+			msgSettingsDays:          "Days to show",
+			msgSettingsChoose:        "Choose: %s",
+			msgSettingsAuto:          "Telegram language",
+			msgSettingsDefault:       "default (%s)",
+			msgSettingsBack:          "« Back",
+			msgSettingsSaved:         "Saved",
+			msgSettingsOutdated:      "This option is no longer available",
+			msgCommandSteps:          "Workout steps with distance and time estimates",
+			msgSteps:                 "Steps for %s %s:",
+			msgStepsUsage:            "Usage: /steps today|tomorrow|sat|2026-10-20|20.10",
+			msgStepsTotal:            "Total: %s, ~%s",
+			msgNoSteps:               "No distances or durations to split into steps",
+			msgStepWarmup:            "Warm-up",
+			msgStepSteady:            "Steady",
+			msgStepInterval:          "Interval",
+			msgStepRecovery:          "Recovery",
+			msgStepCooldown:          "Cool-down",
+			msgStepRepeat:            "%d ×",
+			msgCommandExportWorkout:  "Send today's workout to a watch or Zwift",
+			msgExportWorkoutUsage: "Usage: /export_workout [fit|tcx|zwo]\n" +
+				"Sends today's workout as a file: FIT for Garmin and other watches, TCX for Garmin Connect, " +
+				"ZWO for Zwift. FIT is the default.",
+			msgExportWorkoutNone:   "No workout with distances or durations today to export",
+			msgExportWorkoutSteps:  "Today's workout has more steps than a %s file holds. Try /export_workout fit",
+			msgCommandYesterday:    "Check whether yesterday's workouts are logged",
+			msgYesterday:           "Yesterday %s:",
+			msgWorkoutDone:         "✅ Done",
+			msgWorkoutDoneVolume:   "✅ Done: %s",
+			msgWorkoutDonePlanned:  "✅ Done: %s of %s planned",
+			msgWorkoutNotLogged:    "❌ Not logged",
+			msgCompliance:          "Compliance: %d%%",
+			msgReminder:            "⏰ Not logged yet today:",
+			msgSettingsRemindHour:  "Workout reminder",
+			msgSettingsOff:         "off",
+			msgTableAccounts:       "accounts",
+			msgTableSubscriptions:  "subscriptions",
+			msgTableSnapshots:      "workout snapshots",
+			msgTableCalendarTokens: "calendar tokens",
+			msgTableUserSettings:   "user settings",
+			msgTableGoalRaces:      "goal races",
+			msgTableUserActivity:   "activity",
+		},
+	},
+	LanguageUkrainian: {
+		Language:   LanguageUkrainian,
+		Name:       "Українська",
+		DateLayout: "02.01",
+		Weekdays:   [7]string{"Неділя", "Понеділок", "Вівторок", "Середа", "Четвер", "П'ятниця", "Субота"},
+		messages: map[string]string{
+			msgEnterEmail:     "Введіть email FinalSurge:",
+			msgEnterPassword:  "Введіть пароль FinalSurge:",
+			msgChooseOption:   "Оберіть дію:",
+			msgAuthorizeFirst: "Спочатку авторизуйтеся, ввівши /start",
+			msgTasks:          "Завдання:",
+			msgToday:          "Сьогодні",
+			msgTomorrow:       "Завтра",
+			msgNotSet:         "не заплановано",
+			msgSubscribed:     "Ви отримуватимете сповіщення, коли зміняться найближчі тренування",
+			msgUnsubscribed:   "Сповіщення про зміни тренувань вимкнено",
+			msgCalendarLink: "Підпишіться на свої тренування в Google або Apple Календарі:\n%s\n\n" +
+				"Нікому не передавайте це посилання. Введіть /calendar reset, щоб отримати нове.",
+			msgExportUsage: "Використання: /export 2020-01-01 2020-12-31 [csv|json]\n" +
+				"Проміжок не може перевищувати один рік.",
+			msgInvalidAccountLabel: "Назва акаунта може містити до 20 латинських літер, цифр, '-' або '_'. " +
+				"Наприклад: /start club",
+			msgSwitchedAccount: "Перемкнено на акаунт %s",
+			msgMergedAccounts:  "Плани з усіх акаунтів буде об'єднано",
+			msgUnlinkedAccount: "Акаунт %s від'єднано",
+			msgAccountNotFound: "Акаунт %s не знайдено",
+			msgNoAccounts:      "Немає під'єднаних акаунтів. Під'єднайте, ввівши /start",
+			msgAccounts:        "Акаунти:",
+			msgActiveAccount:   " (активний)",
+			msgAccountsUsage: "/start <label> - під'єднати ще один акаунт\n" +
+				"/accounts use <label> - змінити активний акаунт\n" +
+				"/accounts all - об'єднати плани з усіх акаунтів\n" +
+				"/accounts unlink <label> - від'єднати акаунт\n",
+			msgLoggedOut:      "Ви вийшли. Бот більше не має доступу до ваших акаунтів FinalSurge.",
+			msgDataDeleted:    "Усі ваші дані видалено:",
+			msgNothingStored:  "Бот більше нічого про вас не зберігає.",
+			msgPlanChanged:    "План змінено:",
+			msgWorkoutAdded:   "Додано %s:",
+			msgWorkoutRemoved: "Видалено %s:",
+			msgWorkoutEdited:  "Змінено %s:",
+			msgBefore:         "Було:",
+			msgAfter:          "Стало:",
+			msgLanguage:       "Мова: %s",
+			msgLanguageUsage:  "Використання: /language en|uk|de|auto",
+			msgMarkup:         "Формат повідомлень із завданнями: %s",
+			msgMarkupUsage:    "Використання: /markup html|plain",
+			msgFormat:         "Вигляд завдань: %s",
+			msgFormatUsage:    "Використання: /format %s",
+			msgDigest:         "Підсумок тижня %s-%s:",
+			msgDigestSessions: "Тренування: заплановано %d, виконано %d, пропущено %d",
+			msgDigestRestDays: "Днів відпочинку: %d",
+			msgDigestDistance: "Відстань: заплановано %.1f км, виконано %.1f км",
+			msgDigestTime:     "Час: заплановано %s, виконано %s",
+			msgChartPerDay:    "Запланована відстань за день, км, %s-%s",
+			msgChartPerWeek:   "Запланована відстань за тиждень, км, %s-%s",
+			msgChartUsage:     "Використання: /chart [day|week] [тижні]\nДо 26 тижнів, типово 8.",
+			msgRaceCountdown:  "Старт через %d дн.: %s",
+			msgRaceToday:      "Старт сьогодні: %s",
+			msgRaceTomorrow:   "Старт завтра: %s",
+			msgRaces:          "Найближчі старти:",
+			msgNoRaces:        "Немає стартів у найближчі 6 місяців.",
+			msgDaysLeft:       "залишилось днів: %d",
+			msgRacesUsage: "/races pin 2021-04-18 City Marathon - закріпити цільовий старт\n" +
+				"/races unpin - відкріпити цільовий старт",
+			msgGoalRacePinned:   "Цільовий старт: %s %s",
+			msgGoalRaceUnpinned: "Цільовий старт відкріплено",
+			msgPlanUsage: "Використання: /plan today|tomorrow|sat|2026-10-20|20.10-26.10|week|next week|3d\n" +
+				"Не більше 31 дня.",
+			msgTimeZone:              "Часовий пояс: %s",
+			msgTimeZoneUsage:         "Використання: /timezone Europe/Kyiv",
+			msgInlineTitle:           "Тренування %s",
+			msgInlineLogin:           "Увійти у FinalSurge",
+			msgStats:                 "Авторизовані користувачі: %d\nАктивні за останню добу: %d\nАктивні за останній тиждень: %d\nЗапити до FinalSurge: %d, помилки: %d (%.1f%%)",
+			msgBroadcastUsage:        "Використання: /broadcast <текст>",
+			msgBroadcastStarted:      "Надсилаю у %d чатів...",
+			msgBroadcastDone:         "Розсилку завершено: надіслано %d, помилок %d",
+			msgUserUsage:             "Використання: /user <Telegram ID або @username>",
+			msgUserNotFound:          "Користувача не знайдено",
+			msgUserInfo:              "@%s, ID %d, чат %d\nОстання активність: %s\nАкаунти: %s\nПідписка: %s",
+			msgUserInfoInactive:      "@%s, без повідомлень від початку відстеження активності\nАкаунти: %s\nПідписка: %s",
+			msgUserNotLinked:         "не під'єднано",
+			msgYes:                   "так",
+			msgNo:                    "ні",
+			msgHelp:                  "Команди:",
+			msgUnknownCommand:        "Невідома команда /%s. Введіть /help, щоб побачити всі команди.",
+			msgCommandTask:           "Тренування на сьогодні й завтра",
+			msgCommandPlan:           "Тренування на дати",
+			msgCommandRaces:          "Найближчі старти й цільовий старт",
+			msgCommandChart:          "Графік запланованої дистанції",
+			msgCommandExport:         "Експорт тренувань у файл",
+			msgCommandCalendar:       "Посилання для підписки в календарі",
+			msgCommandSubscribe:      "Сповіщати про зміни плану й надсилати тижневий підсумок",
+			msgCommandUnsubscribe:    "Зупинити сповіщення",
+			msgCommandStart:          "Під'єднати акаунт FinalSurge",
+			msgCommandAccounts:       "Керувати під'єднаними акаунтами",
+			msgCommandLanguage:       "Мова повідомлень",
+			msgCommandTimeZone:       "Часовий пояс для сьогодні й завтра",
+			msgCommandMarkup:         "Форматування повідомлень із завданнями",
+			msgCommandFormat:         "Шаблон повідомлень із завданнями",
+			msgCommandLogout:         "Від'єднати всі акаунти FinalSurge",
+			msgCommandForgetMe:       "Видалити всі мої дані",
+			msgCommandHelp:           "Список команд",
+			msgCommandStats:          "Статистика використання",
+			msgCommandBroadcast:      "Надіслати повідомлення всім користувачам",
+			msgCommandUser:           "Стан під'єднання користувача",
+			msgDialogTimeout:         "Діалог скасовано, бо відповіді не було надто довго. Почніть знову.",
+			msgDialogCancelled:       "Скасовано",
+			msgNoDialog:              "Немає питання, на яке треба відповісти",
+			msgCommandBack:           "Повернутися до попереднього питання",
+			msgCommandCancel:         "Скасувати поточне питання",
+			msgInvalidEmail:          "Це не схоже на email. Введіть email FinalSurge, наприклад runner@example.com:",
+			msgLoginWrongPassword:    "Неправильний email або пароль. Введіть /back, щоб змінити email.",
+			msgLoginLocked:           "Акаунт FinalSurge заблоковано. Розблокуйте його на finalsurge.com і введіть /start знову.",
+			msgLoginRejected:         "FinalSurge відхилив вхід: %s. Введіть /start, щоб спробувати знову.",
+			msgFinalSurgeUnavailable: "FinalSurge не відповідає. Спробуйте пізніше.",
+			msgFinalSurgeError:       "FinalSurge повернув помилку: %s. Спробуйте пізніше.",
+			msgInternalError:         "Щось пішло не так. Спробуйте пізніше.",
+			msgCommandSettings:       "Змінити налаштування кнопками",
+			msgSettings:              "Налаштування:",
+			msgSettingsLanguage:      "Мова",
+			msgSettingsTimeZone:      "Часовий пояс",
+			msgSettingsTimeZoneHint:  "Інші пояси можна встановити командою /timezone Area/City.",
+			msgSettingsNotifyHour:    "Час тижневого підсумку",
+			msgSettingsFormat:        "Формат повідомлень",
+			msgSettingsMarkup:        "Розмітка",
+			msgSettingsUnits:         "Одиниці",
+			msgSettingsDays:          "Скільки днів показувати",
+			msgSettingsChoose:        "Оберіть: %s",
+			msgSettingsAuto:          "Мова Telegram",
+			msgSettingsDefault:       "типово (%s)",
+			msgSettingsBack:          "« Назад",
+			msgSettingsSaved:         "Збережено",
+			msgSettingsOutdated:      "Цей варіант більше недоступний",
+			msgCommandSteps:          "Етапи тренування з оцінкою відстані та часу",
+			msgSteps:                 "Етапи на %s %s:",
+			msgStepsUsage:            "Використання: /steps today|tomorrow|sat|2026-10-20|20.10",
+			msgStepsTotal:            "Разом: %s, ~%s",
+			msgNoSteps:               "Немає відстаней чи тривалостей, щоб розбити на етапи",
+			msgStepWarmup:            "Розминка",
+			msgStepSteady:            "Рівномірно",
+			msgStepInterval:          "Інтервал",
+			msgStepRecovery:          "Відновлення",
+			msgStepCooldown:          "Заминка",
+			msgStepRepeat:            "%d ×",
+			msgCommandExportWorkout:  "Надіслати сьогоднішнє тренування на годинник чи в Zwift",
+			msgExportWorkoutUsage: "Використання: /export_workout [fit|tcx|zwo]\n" +
+				"Надсилає сьогоднішнє тренування файлом: FIT для Garmin та інших годинників, TCX для Garmin Connect, " +
+				"ZWO для Zwift. Типово FIT.",
+			msgExportWorkoutNone:   "Сьогодні немає тренування з відстанями чи тривалостями для експорту",
+			msgExportWorkoutSteps:  "Сьогоднішнє тренування має більше кроків, ніж вміщує файл %s. Спробуйте /export_workout fit",
+			msgCommandYesterday:    "Перевірити, чи внесені вчорашні тренування",
+			msgYesterday:           "Вчора %s:",
+			msgWorkoutDone:         "✅ Виконано",
+			msgWorkoutDoneVolume:   "✅ Виконано: %s",
+			msgWorkoutDonePlanned:  "✅ Виконано: %s із запланованих %s",
+			msgWorkoutNotLogged:    "❌ Не внесено",
+			msgCompliance:          "Виконання плану: %d%%",
+			msgReminder:            "⏰ Сьогодні ще не внесено:",
+			msgSettingsRemindHour:  "Нагадування про тренування",
+			msgSettingsOff:         "вимкнено",
+			msgTableAccounts:       "акаунти",
+			msgTableSubscriptions:  "підписки",
+			msgTableSnapshots:      "збережені тренування",
+			msgTableCalendarTokens: "посилання на календар",
+			msgTableUserSettings:   "налаштування",
+			msgTableGoalRaces:      "цільові старти",
+			msgTableUserActivity:   "активність",
+		},
+	},
+	LanguageGerman: {
+		Language:   LanguageGerman,
+		Name:       "Deutsch",
+		DateLayout: "02.01.",
+		Weekdays:   [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		messages: map[string]string{
+			msgEnterEmail:     "FinalSurge-E-Mail eingeben:",
+			msgEnterPassword:  "FinalSurge-Passwort eingeben:",
+			msgChooseOption:   "Option wählen:",
+			msgAuthorizeFirst: "Bitte melde dich zuerst mit /start an",
+			msgTasks:          "Trainings:",
+			msgToday:          "Heute",
+			msgTomorrow:       "Morgen",
+			msgNotSet:         "nicht geplant",
+			msgSubscribed:     "Du wirst benachrichtigt, wenn sich bevorstehende Trainings ändern",
+			msgUnsubscribed:   "Benachrichtigungen über Trainingsänderungen sind ausgeschaltet",
+			msgCalendarLink: "Abonniere deine Trainings in Google oder Apple Kalender:\n%s\n\n" +
+				"Halte den Link geheim. Gib /calendar reset ein, um einen neuen zu erhalten.",
+			msgExportUsage: "Verwendung: /export 2020-01-01 2020-12-31 [csv|json]\n" +
+				"Der Zeitraum darf ein Jahr nicht überschreiten.",
+			msgInvalidAccountLabel: "Die Kontobezeichnung darf bis zu 20 Buchstaben, Ziffern, '-' oder '_' enthalten. " +
+				"Zum Beispiel: /start club",
+			msgSwitchedAccount: "Zum Konto %s gewechselt",
+			msgMergedAccounts:  "Pläne aller Konten werden zusammengeführt",
+			msgUnlinkedAccount: "Konto %s getrennt",
+			msgAccountNotFound: "Konto %s nicht gefunden",
+			msgNoAccounts:      "Keine verknüpften Konten. Verknüpfe eines mit /start",
+			msgAccounts:        "Konten:",
+			msgActiveAccount:   " (aktiv)",
+			msgAccountsUsage: "/start <label> - weiteres Konto verknüpfen\n" +
+				"/accounts use <label> - aktives Konto wechseln\n" +
+				"/accounts all - Pläne aller Konten zusammenführen\n" +
+				"/accounts unlink <label> - Konto trennen\n",
+			msgLoggedOut:      "Abgemeldet. Der Bot hat keinen Zugriff mehr auf deine FinalSurge-Konten.",
+			msgDataDeleted:    "Alle deine Daten wurden gelöscht:",
+			msgNothingStored:  "Der Bot speichert nichts mehr über dich.",
+			msgPlanChanged:    "Plan geändert:",
+			msgWorkoutAdded:   "Hinzugefügt %s:",
+			msgWorkoutRemoved: "Entfernt %s:",
+			msgWorkoutEdited:  "Geändert %s:",
+			msgBefore:         "Vorher:",
+			msgAfter:          "Nachher:",
+			msgLanguage:       "Sprache: %s",
+			msgLanguageUsage:  "Verwendung: /language en|uk|de|auto",
+			msgMarkup:         "Trainingsnachrichten werden als %s formatiert",
+			msgMarkupUsage:    "Verwendung: /markup html|plain",
+			msgFormat:         "Trainingsformat: %s",
+			msgFormatUsage:    "Verwendung: /format %s",
+			msgDigest:         "Wochenübersicht %s-%s:",
+			msgDigestSessions: "Einheiten: %d geplant, %d absolviert, %d verpasst",
+			msgDigestRestDays: "Ruhetage: %d",
+			msgDigestDistance: "Distanz: %.1f km geplant, %.1f km absolviert",
+			msgDigestTime:     "Zeit: %s geplant, %s absolviert",
+			msgChartPerDay:    "Geplante Distanz pro Tag, km, %s-%s",
+			msgChartPerWeek:   "Geplante Distanz pro Woche, km, %s-%s",
+			msgChartUsage:     "Verwendung: /chart [day|week] [Wochen]\nBis zu 26 Wochen, standardmäßig 8.",
+			msgRaceCountdown:  "Wettkampf in %d Tagen: %s",
+			msgRaceToday:      "Wettkampf heute: %s",
+			msgRaceTomorrow:   "Wettkampf morgen: %s",
+			msgRaces:          "Bevorstehende Wettkämpfe:",
+			msgNoRaces:        "Keine Wettkämpfe in den nächsten 6 Monaten.",
+			msgDaysLeft:       "noch %d Tage",
+			msgRacesUsage: "/races pin 2021-04-18 City Marathon - Zielwettkampf festlegen\n" +
+				"/races unpin - Zielwettkampf entfernen",
+			msgGoalRacePinned:   "Zielwettkampf: %s %s",
+			msgGoalRaceUnpinned: "Zielwettkampf entfernt",
+			msgPlanUsage: "Verwendung: /plan today|tomorrow|sat|2026-10-20|20.10-26.10|week|next week|3d\n" +
+				"Bis zu 31 Tage.",
+			msgTimeZone:              "Zeitzone: %s",
+			msgTimeZoneUsage:         "Verwendung: /timezone Europe/Berlin",
+			msgInlineTitle:           "Trainings %s",
+			msgInlineLogin:           "Bei FinalSurge anmelden",
+			msgStats:                 "Autorisierte Benutzer: %d\nAktiv am letzten Tag: %d\nAktiv in der letzten Woche: %d\nFinalSurge-Anfragen: %d, Fehler: %d (%.1f%%)",
+			msgBroadcastUsage:        "Verwendung: /broadcast <Text>",
+			msgBroadcastStarted:      "Sende an %d Chats...",
+			msgBroadcastDone:         "Rundsendung fertig: %d gesendet, %d fehlgeschlagen",
+			msgUserUsage:             "Verwendung: /user <Telegram-ID oder @Benutzername>",
+			msgUserNotFound:          "Benutzer nicht gefunden",
+			msgUserInfo:              "@%s, ID %d, Chat %d\nZuletzt aktiv: %s\nKonten: %s\nAbonniert: %s",
+			msgUserInfoInactive:      "@%s, keine Nachrichten seit Beginn der Aktivitätserfassung\nKonten: %s\nAbonniert: %s",
+			msgUserNotLinked:         "nicht verknüpft",
+			msgYes:                   "ja",
+			msgNo:                    "nein",
+			msgHelp:                  "Befehle:",
+			msgUnknownCommand:        "Unbekannter Befehl /%s. Gib /help ein, um alle Befehle zu sehen.",
+			msgCommandTask:           "Trainings für heute und morgen",
+			msgCommandPlan:           "Trainings für die Daten",
+			msgCommandRaces:          "Kommende Wettkämpfe und der Zielwettkampf",
+			msgCommandChart:          "Diagramm der geplanten Distanz",
+			msgCommandExport:         "Trainings in eine Datei exportieren",
+			msgCommandCalendar:       "Link zum Abonnieren in einer Kalender-App",
+			msgCommandSubscribe:      "Über Planänderungen benachrichtigen und die Wochenübersicht senden",
+			msgCommandUnsubscribe:    "Benachrichtigungen beenden",
+			msgCommandStart:          "FinalSurge-Konto verknüpfen",
+			msgCommandAccounts:       "Verknüpfte Konten verwalten",
+			msgCommandLanguage:       "Sprache der Nachrichten",
+			msgCommandTimeZone:       "Zeitzone für heute und morgen",
+			msgCommandMarkup:         "Formatierung der Aufgabennachrichten",
+			msgCommandFormat:         "Vorlage der Aufgabennachrichten",
+			msgCommandLogout:         "Alle FinalSurge-Konten trennen",
+			msgCommandForgetMe:       "Alle meine Daten löschen",
+			msgCommandHelp:           "Liste der Befehle",
+			msgCommandStats:          "Nutzungsstatistik",
+			msgCommandBroadcast:      "Nachricht an alle Benutzer senden",
+			msgCommandUser:           "Verknüpfungsstatus des Benutzers",
+			msgDialogTimeout:         "Der Dialog wurde abgebrochen, weil zu lange keine Antwort kam. Beginne erneut.",
+			msgDialogCancelled:       "Abgebrochen",
+			msgNoDialog:              "Es gibt keine offene Frage",
+			msgCommandBack:           "Zurück zur vorherigen Frage",
+			msgCommandCancel:         "Die aktuelle Frage abbrechen",
+			msgInvalidEmail:          "Das sieht nicht wie eine E-Mail aus. Gib die FinalSurge-E-Mail ein, zum Beispiel runner@example.com:",
+			msgLoginWrongPassword:    "Falsche E-Mail oder falsches Passwort. Gib /back ein, um die E-Mail zu ändern.",
+			msgLoginLocked:           "Das FinalSurge-Konto ist gesperrt. Entsperre es auf finalsurge.com und gib /start erneut ein.",
+			msgLoginRejected:         "FinalSurge hat die Anmeldung abgelehnt: %s. Gib /start ein, um es erneut zu versuchen.",
+			msgFinalSurgeUnavailable: "FinalSurge antwortet nicht. Versuche es später erneut.",
+			msgFinalSurgeError:       "FinalSurge hat einen Fehler gemeldet: %s. Versuche es später erneut.",
+			msgInternalError:         "Etwas ist schiefgelaufen. Versuche es später erneut.",
+			msgCommandSettings:       "Einstellungen mit Schaltflächen ändern",
+			msgSettings:              "Einstellungen:",
+			msgSettingsLanguage:      "Sprache",
+			msgSettingsTimeZone:      "Zeitzone",
+			msgSettingsTimeZoneHint:  "Andere Zonen lassen sich mit /timezone Area/City festlegen.",
+			msgSettingsNotifyHour:    "Zeit der Wochenübersicht",
+			msgSettingsFormat:        "Nachrichtenformat",
+			msgSettingsMarkup:        "Auszeichnung",
+			msgSettingsUnits:         "Einheiten",
+			msgSettingsDays:          "Angezeigte Tage",
+			msgSettingsChoose:        "Wähle: %s",
+			msgSettingsAuto:          "Telegram-Sprache",
+			msgSettingsDefault:       "Standard (%s)",
+			msgSettingsBack:          "« Zurück",
+			msgSettingsSaved:         "Gespeichert",
+			msgSettingsOutdated:      "Diese Option ist nicht mehr verfügbar",
+			msgCommandSteps:          "Trainingsabschnitte mit geschätzter Distanz und Zeit",
+			msgSteps:                 "Abschnitte für %s %s:",
+			msgStepsUsage:            "Verwendung: /steps today|tomorrow|sat|2026-10-20|20.10",
+			msgStepsTotal:            "Gesamt: %s, ~%s",
+			msgNoSteps:               "Keine Distanzen oder Dauern zum Aufteilen in Abschnitte",
+			msgStepWarmup:            "Aufwärmen",
+			msgStepSteady:            "Gleichmäßig",
+			msgStepInterval:          "Intervall",
+			msgStepRecovery:          "Erholung",
+			msgStepCooldown:          "Auslaufen",
+			msgStepRepeat:            "%d ×",
+			msgCommandExportWorkout:  "Heutiges Training an eine Uhr oder Zwift senden",
+			msgExportWorkoutUsage: "Verwendung: /export_workout [fit|tcx|zwo]\n" +
+				"Sendet das heutige Training als Datei: FIT für Garmin und andere Uhren, TCX für Garmin Connect, " +
+				"ZWO für Zwift. Standard ist FIT.",
+			msgExportWorkoutNone:   "Heute gibt es kein Training mit Distanzen oder Dauern zum Exportieren",
+			msgExportWorkoutSteps:  "Das heutige Training hat mehr Schritte, als eine %s-Datei fasst. Versuche /export_workout fit",
+			msgCommandYesterday:    "Prüfen, ob die gestrigen Trainings eingetragen sind",
+			msgYesterday:           "Gestern %s:",
+			msgWorkoutDone:         "✅ Erledigt",
+			msgWorkoutDoneVolume:   "✅ Erledigt: %s",
+			msgWorkoutDonePlanned:  "✅ Erledigt: %s von geplanten %s",
+			msgWorkoutNotLogged:    "❌ Nicht eingetragen",
+			msgCompliance:          "Planerfüllung: %d%%",
+			msgReminder:            "⏰ Heute noch nicht eingetragen:",
+			msgSettingsRemindHour:  "Trainingserinnerung",
+			msgSettingsOff:         "aus",
+			msgTableAccounts:       "Konten",
+			msgTableSubscriptions:  "Abonnements",
+			msgTableSnapshots:      "gespeicherte Trainings",
+			msgTableCalendarTokens: "Kalenderlinks",
+			msgTableUserSettings:   "Einstellungen",
+			msgTableGoalRaces:      "Zielwettkämpfe",
+			msgTableUserActivity:   "Aktivität",
+		},
+	},
+}
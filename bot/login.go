@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	loginStepEmail    = "email"
+	loginStepPassword = "password"
+	loginParamLabel   = "label"
+)
+
+// newLoginDialog asks FinalSurge credentials and links the account with the label from /start.
+func (b *Bot) newLoginDialog() *Dialog {
+	return &Dialog{
+		Name: "login",
+		Steps: []DialogStep{
+			{Name: loginStepEmail, Prompt: msgEnterEmail, Validate: validateEmail},
+			{Name: loginStepPassword, Prompt: msgEnterPassword},
+		},
+		Done: b.login,
+	}
+}
+
+// validateEmail accepts only a bare address such as "runner@example.com".
+func validateEmail(answer string) string {
+	answer = strings.TrimSpace(answer)
+
+	address, err := mail.ParseAddress(answer)
+	if err != nil || address.Address != answer {
+		return msgInvalidEmail
+	}
+
+	return ""
+}
+
+// login asks the password again when FinalSurge rejects it or does not respond.
+func (b *Bot) login(ctx context.Context, session *DialogSession, loc *Locale) (tgbotapi.Chattable, error) {
+	email := strings.TrimSpace(session.Answers[loginStepEmail])
+
+	userToken, err := b.fs.Login(ctx, email, session.Answers[loginStepPassword])
+
+	var fsErr *FinalSurgeError
+
+	switch {
+	case errors.As(err, &fsErr):
+		reason, retry := loginFailure(fsErr)
+		if !retry {
+			return tgbotapi.NewMessage(session.ChatID, reason(loc)), nil
+		}
+
+		session.Retry(loginStepPassword)
+
+		return tgbotapi.NewMessage(session.ChatID, reason(loc)+"\n"+loc.T(msgEnterPassword)), nil
+	case err != nil:
+		log.Printf("login %s: %v", session.UserName, err)
+
+		session.Retry(loginStepPassword)
+
+		return tgbotapi.NewMessage(session.ChatID, loc.T(msgFinalSurgeUnavailable)+"\n"+loc.T(msgEnterPassword)), nil
+	}
+
+	if err := b.db.UpdateUserToken(ctx, session.UserName, session.Params[loginParamLabel], userToken); err != nil {
+		return nil, fmt.Errorf("update user token: %w", err)
+	}
+
+	return b.newChooseOptionMsg(session.ChatID, loc), nil
+}
+
+// loginFailure returns the reply to the rejected login and whether another password may help.
+// FinalSurge does not document error numbers, so the description is matched.
+func loginFailure(fsErr *FinalSurgeError) (reason func(loc *Locale) string, retry bool) {
+	description := strings.ToLower(fsErr.Description)
+
+	containsAny := func(words ...string) bool {
+		for _, w := range words {
+			if strings.Contains(description, w) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	switch {
+	case containsAny("lock", "disabled", "suspend"):
+		return func(loc *Locale) string { return loc.T(msgLoginLocked) }, false
+	case containsAny("password", "credential", "invalid", "incorrect"):
+		return func(loc *Locale) string { return loc.T(msgLoginWrongPassword) }, true
+	}
+
+	return func(loc *Locale) string { return loc.T(msgLoginRejected, fsErr.Description) }, false
+}
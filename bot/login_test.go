@@ -0,0 +1,93 @@
+package bot_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestBot_ProcessUpdate_Login(t *testing.T) {
+	const email = "runner@example.com"
+	start := tgbotapi.Update{Message: &tgbotapi.Message{
+		Chat:     &tgbotapi.Chat{ID: testChatID},
+		From:     &tgbotapi.User{UserName: testUserName},
+		Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/start")}},
+		Text:     "/start",
+	}}
+	text := func(text string) tgbotapi.Update {
+		return tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat: &tgbotapi.Chat{ID: testChatID},
+			From: &tgbotapi.User{UserName: testUserName},
+			Text: text,
+		}}
+	}
+
+	t.Run("invalid email", func(t *testing.T) {
+		bot := newTestBot(t, &Config{}, Settings{})
+
+		bot.expectReply("Enter FinalSurge email:")
+		bot.expectReply("This does not look like an email. Enter FinalSurge email, for example runner@example.com:")
+		bot.expectReply("Enter FinalSurge password:")
+
+		bot.process(t, start, text("Runner <runner@example.com>"), text(" "+email+" "))
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		bot := newTestBot(t, &Config{}, Settings{})
+
+		bot.expectReply("Enter FinalSurge email:")
+		bot.expectReply("Enter FinalSurge password:")
+		bot.fs.EXPECT().Login(gomock.Any(), email, "wrong").
+			Return(UserToken{}, &FinalSurgeError{Number: 3, Description: "Invalid email or password"}).Times(1)
+		bot.expectReply("Wrong email or password. Enter /back to change the email.\nEnter FinalSurge password:")
+		bot.fs.EXPECT().Login(gomock.Any(), email, "timeout").Return(UserToken{}, errors.New("timeout")).Times(1)
+		bot.expectReply("FinalSurge does not respond. Try again later.\nEnter FinalSurge password:")
+		bot.fs.EXPECT().Login(gomock.Any(), email, "password").Return(UserToken{Token: "token"}, nil).Times(1)
+		bot.storage.EXPECT().UpdateUserToken(gomock.Any(), testUserName, DefaultAccountLabel, UserToken{Token: "token"}).
+			Return(nil).Times(1)
+		bot.sender.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: testChatID, ReplyMarkup: bot.Keyboard()},
+			Text:     "Choose option:",
+		}).Times(1)
+
+		bot.process(t, start, text(email), text("wrong"), text("timeout"), text("password"))
+	})
+
+	t.Run("account locked", func(t *testing.T) {
+		bot := newTestBot(t, &Config{}, Settings{})
+
+		bot.expectReply("Enter FinalSurge email:")
+		bot.expectReply("Enter FinalSurge password:")
+		bot.fs.EXPECT().Login(gomock.Any(), email, "password").
+			Return(UserToken{}, &FinalSurgeError{Number: 7, Description: "Account is locked"}).Times(1)
+		bot.expectReply(
+			"The FinalSurge account is locked. Unlock it on finalsurge.com and enter /start again.")
+		bot.sender.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: testChatID, ReplyMarkup: bot.Keyboard()},
+			Text:     "Choose option:",
+		}).Times(1)
+
+		bot.process(t, start, text(email), text("password"), text("password"))
+	})
+
+	t.Run("error reply", func(t *testing.T) {
+		bot := newTestBot(t, &Config{}, Settings{})
+
+		bot.expectReply("Enter FinalSurge email:")
+		bot.expectReply("Enter FinalSurge password:")
+		bot.fs.EXPECT().Login(gomock.Any(), email, "password").Return(UserToken{}, nil).Times(1)
+		bot.storage.EXPECT().UpdateUserToken(gomock.Any(), testUserName, DefaultAccountLabel, UserToken{}).
+			Return(errors.New("connection refused")).Times(1)
+		bot.expectReply("Something went wrong. Try again later.")
+
+		bot.process(t, start, text(email))
+
+		if err := bot.ProcessUpdate(context.Background(), text("password")); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	MarkupHTML  = "html"
+	MarkupPlain = "plain"
+)
+
+// Markup formats text of messages for the Telegram parse mode.
+type Markup interface {
+	ParseMode() string
+	Bold(s string) string
+	Escape(s string) string
+}
+
+type HTMLMarkup struct{}
+
+func (HTMLMarkup) ParseMode() string {
+	return tgbotapi.ModeHTML
+}
+
+func (m HTMLMarkup) Bold(s string) string {
+	return "<b>" + m.Escape(s) + "</b>"
+}
+
+func (HTMLMarkup) Escape(s string) string {
+	return html.EscapeString(s)
+}
+
+// PlainMarkup is a fallback for clients that render HTML poorly.
+type PlainMarkup struct{}
+
+func (PlainMarkup) ParseMode() string {
+	return ""
+}
+
+func (PlainMarkup) Bold(s string) string {
+	return s
+}
+
+func (PlainMarkup) Escape(s string) string {
+	return s
+}
+
+// MarkupFor returns the markup by name, HTMLMarkup is the default.
+func MarkupFor(name string) Markup {
+	if name == MarkupPlain {
+		return PlainMarkup{}
+	}
+
+	return HTMLMarkup{}
+}
+
+var activityEmojis = []struct {
+	keyword string
+	emoji   string
+}{
+	{keyword: "rest", emoji: "😴"},
+	{keyword: "run", emoji: "🏃"},
+	{keyword: "bike", emoji: "🚴"},
+	{keyword: "cycl", emoji: "🚴"},
+	{keyword: "swim", emoji: "🏊"},
+	{keyword: "strength", emoji: "🏋️"},
+}
+
+// ActivityEmoji returns the emoji for the workout activity type or an empty string for unknown ones.
+func ActivityEmoji(workout Workout) string {
+	activity := strings.ToLower(workout.Activity)
+	if workout.RestDay {
+		activity = "rest"
+	}
+
+	for _, a := range activityEmojis {
+		if strings.Contains(activity, a.keyword) {
+			return a.emoji
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,386 @@
+package bot
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Memory is a Storage that keeps data in memory and loses it on restart. Useful for tests and demos.
+type Memory struct {
+	mu sync.Mutex
+
+	accounts       map[string][]Account
+	subscriptions  map[string]Subscription
+	snapshots      map[string]WorkoutSnapshot
+	calendarTokens map[string]string
+	settings       map[string]Settings
+	goalRaces      map[string]Race
+	activities     map[string]UserActivity
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		accounts:       make(map[string][]Account),
+		subscriptions:  make(map[string]Subscription),
+		snapshots:      make(map[string]WorkoutSnapshot),
+		calendarTokens: make(map[string]string),
+		settings:       make(map[string]Settings),
+		goalRaces:      make(map[string]Race),
+		activities:     make(map[string]UserActivity),
+	}
+}
+
+func (m *Memory) UserToken(ctx context.Context, userName string) (UserToken, error) {
+	userTokens, err := m.UserTokens(ctx, userName)
+	if err != nil {
+		return UserToken{}, err
+	}
+
+	return userTokens[0], nil
+}
+
+func (m *Memory) UserTokens(_ context.Context, userName string) ([]UserToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var userTokens []UserToken
+
+	for _, a := range m.accounts[userName] {
+		if a.Active {
+			userTokens = append(userTokens, a.UserToken)
+		}
+	}
+
+	if len(userTokens) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return userTokens, nil
+}
+
+func (m *Memory) UpdateUserToken(_ context.Context, userName, label string, userToken UserToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	accounts := m.accounts[userName]
+	found := false
+
+	for i := range accounts {
+		accounts[i].Active = accounts[i].Label == label
+		if accounts[i].Label == label {
+			accounts[i].UserToken = userToken
+			found = true
+		}
+	}
+
+	if !found {
+		accounts = append(accounts, Account{Label: label, UserToken: userToken, Active: true})
+	}
+
+	m.accounts[userName] = accounts
+
+	return nil
+}
+
+func (m *Memory) Accounts(_ context.Context, userName string) ([]Account, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Account(nil), m.accounts[userName]...), nil
+}
+
+func (m *Memory) ActivateAccount(_ context.Context, userName, label string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	accounts := m.accounts[userName]
+	if m.accountIndex(userName, label) < 0 {
+		return ErrNotFound
+	}
+
+	for i := range accounts {
+		accounts[i].Active = accounts[i].Label == label
+	}
+
+	return nil
+}
+
+func (m *Memory) ActivateAllAccounts(_ context.Context, userName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	accounts := m.accounts[userName]
+	for i := range accounts {
+		accounts[i].Active = true
+	}
+
+	return nil
+}
+
+func (m *Memory) DeleteAccount(_ context.Context, userName, label string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.accountIndex(userName, label)
+	if i < 0 {
+		return ErrNotFound
+	}
+
+	accounts := append(m.accounts[userName][:i:i], m.accounts[userName][i+1:]...)
+	if len(accounts) == 0 {
+		delete(m.accounts, userName)
+
+		return nil
+	}
+
+	active := false
+	for _, a := range accounts {
+		active = active || a.Active
+	}
+
+	if !active {
+		accounts[0].Active = true
+	}
+
+	m.accounts[userName] = accounts
+
+	return nil
+}
+
+func (m *Memory) DeleteAccounts(_ context.Context, userName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.accounts, userName)
+
+	return nil
+}
+
+func (m *Memory) Subscriptions(_ context.Context) ([]Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	subscriptions := make([]Subscription, 0, len(m.subscriptions))
+	for _, s := range m.subscriptions {
+		subscriptions = append(subscriptions, s)
+	}
+
+	return subscriptions, nil
+}
+
+func (m *Memory) Subscribe(_ context.Context, subscription Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.subscriptions[subscription.UserName] = subscription
+
+	return nil
+}
+
+func (m *Memory) Unsubscribe(_ context.Context, userName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.subscriptions, userName)
+	delete(m.snapshots, userName)
+
+	return nil
+}
+
+func (m *Memory) WorkoutSnapshot(_ context.Context, userName string) (WorkoutSnapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot, ok := m.snapshots[userName]
+	if _, subscribed := m.subscriptions[userName]; !ok || !subscribed {
+		return WorkoutSnapshot{}, ErrNotFound
+	}
+
+	snapshot.Workouts = append([]Workout(nil), snapshot.Workouts...)
+
+	return snapshot, nil
+}
+
+func (m *Memory) UpdateWorkoutSnapshot(_ context.Context, userName string, snapshot WorkoutSnapshot) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.subscriptions[userName]; !ok {
+		return nil
+	}
+
+	// Only fields stored by the other storages are kept.
+	workouts := make([]Workout, 0, len(snapshot.Workouts))
+	for _, w := range snapshot.Workouts {
+		workouts = append(workouts, Workout{Date: w.Date, Description: w.Description})
+	}
+
+	m.snapshots[userName] = WorkoutSnapshot{End: snapshot.End, Workouts: workouts}
+
+	return nil
+}
+
+func (m *Memory) CalendarToken(_ context.Context, userName string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	token, ok := m.calendarTokens[userName]
+	if !ok {
+		return "", ErrNotFound
+	}
+
+	return token, nil
+}
+
+func (m *Memory) UpdateCalendarToken(_ context.Context, userName, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calendarTokens[userName] = token
+
+	return nil
+}
+
+func (m *Memory) CalendarUserName(_ context.Context, token string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for userName, t := range m.calendarTokens {
+		if t == token {
+			return userName, nil
+		}
+	}
+
+	return "", ErrNotFound
+}
+
+func (m *Memory) Settings(_ context.Context, userName string) (Settings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	settings, ok := m.settings[userName]
+	if !ok {
+		return Settings{}, ErrNotFound
+	}
+
+	return settings, nil
+}
+
+func (m *Memory) UpdateSettings(_ context.Context, userName string, settings Settings) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.settings[userName] = settings
+
+	return nil
+}
+
+func (m *Memory) GoalRace(_ context.Context, userName string) (Race, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	race, ok := m.goalRaces[userName]
+	if !ok {
+		return Race{}, ErrNotFound
+	}
+
+	return race, nil
+}
+
+func (m *Memory) UpdateGoalRace(_ context.Context, userName string, race Race) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.goalRaces[userName] = race
+
+	return nil
+}
+
+func (m *Memory) DeleteGoalRace(_ context.Context, userName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.goalRaces, userName)
+
+	return nil
+}
+
+func (m *Memory) UpdateUserActivity(_ context.Context, activity UserActivity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.activities[activity.UserName] = activity
+
+	return nil
+}
+
+func (m *Memory) UserActivities(_ context.Context) ([]UserActivity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	activities := make([]UserActivity, 0, len(m.activities))
+	for _, a := range m.activities {
+		activities = append(activities, a)
+	}
+
+	sort.Slice(activities, func(i, j int) bool { return activities[i].UserName < activities[j].UserName })
+
+	return activities, nil
+}
+
+func (m *Memory) AuthorizedUsers(_ context.Context) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.accounts), nil
+}
+
+func (m *Memory) DeleteUser(_ context.Context, userName string) ([]DeletedRows, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := func(ok bool) int64 {
+		if ok {
+			return 1
+		}
+
+		return 0
+	}
+
+	_, subscribed := m.subscriptions[userName]
+	_, hasCalendarToken := m.calendarTokens[userName]
+	_, hasSettings := m.settings[userName]
+	_, hasGoalRace := m.goalRaces[userName]
+	_, hasActivity := m.activities[userName]
+
+	deleted := []DeletedRows{
+		{Table: "accounts", Rows: int64(len(m.accounts[userName]))},
+		{Table: "subscriptions", Rows: count(subscribed)},
+		{Table: "workout_snapshots", Rows: int64(len(m.snapshots[userName].Workouts))},
+		{Table: "calendar_tokens", Rows: count(hasCalendarToken)},
+		{Table: "user_settings", Rows: count(hasSettings)},
+		{Table: "goal_races", Rows: count(hasGoalRace)},
+		{Table: "user_activity", Rows: count(hasActivity)},
+	}
+
+	delete(m.accounts, userName)
+	delete(m.subscriptions, userName)
+	delete(m.snapshots, userName)
+	delete(m.calendarTokens, userName)
+	delete(m.settings, userName)
+	delete(m.goalRaces, userName)
+	delete(m.activities, userName)
+
+	return deleted, nil
+}
+
+func (m *Memory) accountIndex(userName, label string) int {
+	for i, a := range m.accounts[userName] {
+		if a.Label == label {
+			return i
+		}
+	}
+
+	return -1
+}
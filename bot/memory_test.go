@@ -0,0 +1,14 @@
+package bot_test
+
+import (
+	"testing"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/storagetest"
+)
+
+func TestMemory(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) Storage {
+		return NewMemory()
+	})
+}
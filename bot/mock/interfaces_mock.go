@@ -9,6 +9,7 @@ import (
 	bot "github.com/alexandear/final-surge-bot/bot"
 	telegram_bot_api "github.com/go-telegram-bot-api/telegram-bot-api"
 	gomock "github.com/golang/mock/gomock"
+	url "net/url"
 	reflect "reflect"
 	time "time"
 )
@@ -51,6 +52,51 @@ func (mr *MockSenderMockRecorder) Send(msg interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockSender)(nil).Send), msg)
 }
 
+// AnswerInlineQuery mocks base method
+func (m *MockSender) AnswerInlineQuery(config telegram_bot_api.InlineConfig) (telegram_bot_api.APIResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnswerInlineQuery", config)
+	ret0, _ := ret[0].(telegram_bot_api.APIResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnswerInlineQuery indicates an expected call of AnswerInlineQuery
+func (mr *MockSenderMockRecorder) AnswerInlineQuery(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnswerInlineQuery", reflect.TypeOf((*MockSender)(nil).AnswerInlineQuery), config)
+}
+
+// AnswerCallbackQuery mocks base method
+func (m *MockSender) AnswerCallbackQuery(config telegram_bot_api.CallbackConfig) (telegram_bot_api.APIResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnswerCallbackQuery", config)
+	ret0, _ := ret[0].(telegram_bot_api.APIResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AnswerCallbackQuery indicates an expected call of AnswerCallbackQuery
+func (mr *MockSenderMockRecorder) AnswerCallbackQuery(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnswerCallbackQuery", reflect.TypeOf((*MockSender)(nil).AnswerCallbackQuery), config)
+}
+
+// MakeRequest mocks base method
+func (m *MockSender) MakeRequest(endpoint string, params url.Values) (telegram_bot_api.APIResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MakeRequest", endpoint, params)
+	ret0, _ := ret[0].(telegram_bot_api.APIResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MakeRequest indicates an expected call of MakeRequest
+func (mr *MockSenderMockRecorder) MakeRequest(endpoint, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeRequest", reflect.TypeOf((*MockSender)(nil).MakeRequest), endpoint, params)
+}
+
 // MockStorage is a mock of Storage interface
 type MockStorage struct {
 	ctrl     *gomock.Controller
@@ -74,6 +120,35 @@ func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
 	return m.recorder
 }
 
+// Settings mocks base method
+func (m *MockStorage) Settings(ctx context.Context, userName string) (bot.Settings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Settings", ctx, userName)
+	ret0, _ := ret[0].(bot.Settings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Settings indicates an expected call of Settings
+func (mr *MockStorageMockRecorder) Settings(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Settings", reflect.TypeOf((*MockStorage)(nil).Settings), ctx, userName)
+}
+
+// UpdateSettings mocks base method
+func (m *MockStorage) UpdateSettings(ctx context.Context, userName string, settings bot.Settings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", ctx, userName, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings
+func (mr *MockStorageMockRecorder) UpdateSettings(ctx, userName, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockStorage)(nil).UpdateSettings), ctx, userName, settings)
+}
+
 // UserToken mocks base method
 func (m *MockStorage) UserToken(ctx context.Context, userName string) (bot.UserToken, error) {
 	m.ctrl.T.Helper()
@@ -89,18 +164,374 @@ func (mr *MockStorageMockRecorder) UserToken(ctx, userName interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserToken", reflect.TypeOf((*MockStorage)(nil).UserToken), ctx, userName)
 }
 
+// UserTokens mocks base method
+func (m *MockStorage) UserTokens(ctx context.Context, userName string) ([]bot.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserTokens", ctx, userName)
+	ret0, _ := ret[0].([]bot.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserTokens indicates an expected call of UserTokens
+func (mr *MockStorageMockRecorder) UserTokens(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserTokens", reflect.TypeOf((*MockStorage)(nil).UserTokens), ctx, userName)
+}
+
 // UpdateUserToken mocks base method
-func (m *MockStorage) UpdateUserToken(ctx context.Context, userName string, userToken bot.UserToken) error {
+func (m *MockStorage) UpdateUserToken(ctx context.Context, userName, label string, userToken bot.UserToken) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUserToken", ctx, userName, userToken)
+	ret := m.ctrl.Call(m, "UpdateUserToken", ctx, userName, label, userToken)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateUserToken indicates an expected call of UpdateUserToken
-func (mr *MockStorageMockRecorder) UpdateUserToken(ctx, userName, userToken interface{}) *gomock.Call {
+func (mr *MockStorageMockRecorder) UpdateUserToken(ctx, userName, label, userToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserToken", reflect.TypeOf((*MockStorage)(nil).UpdateUserToken), ctx, userName, label, userToken)
+}
+
+// Accounts mocks base method
+func (m *MockStorage) Accounts(ctx context.Context, userName string) ([]bot.Account, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accounts", ctx, userName)
+	ret0, _ := ret[0].([]bot.Account)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Accounts indicates an expected call of Accounts
+func (mr *MockStorageMockRecorder) Accounts(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accounts", reflect.TypeOf((*MockStorage)(nil).Accounts), ctx, userName)
+}
+
+// ActivateAccount mocks base method
+func (m *MockStorage) ActivateAccount(ctx context.Context, userName, label string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivateAccount", ctx, userName, label)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ActivateAccount indicates an expected call of ActivateAccount
+func (mr *MockStorageMockRecorder) ActivateAccount(ctx, userName, label interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateAccount", reflect.TypeOf((*MockStorage)(nil).ActivateAccount), ctx, userName, label)
+}
+
+// ActivateAllAccounts mocks base method
+func (m *MockStorage) ActivateAllAccounts(ctx context.Context, userName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivateAllAccounts", ctx, userName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ActivateAllAccounts indicates an expected call of ActivateAllAccounts
+func (mr *MockStorageMockRecorder) ActivateAllAccounts(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateAllAccounts", reflect.TypeOf((*MockStorage)(nil).ActivateAllAccounts), ctx, userName)
+}
+
+// DeleteAccount mocks base method
+func (m *MockStorage) DeleteAccount(ctx context.Context, userName, label string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccount", ctx, userName, label)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccount indicates an expected call of DeleteAccount
+func (mr *MockStorageMockRecorder) DeleteAccount(ctx, userName, label interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccount", reflect.TypeOf((*MockStorage)(nil).DeleteAccount), ctx, userName, label)
+}
+
+// DeleteAccounts mocks base method
+func (m *MockStorage) DeleteAccounts(ctx context.Context, userName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAccounts", ctx, userName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAccounts indicates an expected call of DeleteAccounts
+func (mr *MockStorageMockRecorder) DeleteAccounts(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAccounts", reflect.TypeOf((*MockStorage)(nil).DeleteAccounts), ctx, userName)
+}
+
+// Subscriptions mocks base method
+func (m *MockStorage) Subscriptions(ctx context.Context) ([]bot.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscriptions", ctx)
+	ret0, _ := ret[0].([]bot.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscriptions indicates an expected call of Subscriptions
+func (mr *MockStorageMockRecorder) Subscriptions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscriptions", reflect.TypeOf((*MockStorage)(nil).Subscriptions), ctx)
+}
+
+// Subscribe mocks base method
+func (m *MockStorage) Subscribe(ctx context.Context, subscription bot.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx, subscription)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe
+func (mr *MockStorageMockRecorder) Subscribe(ctx, subscription interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockStorage)(nil).Subscribe), ctx, subscription)
+}
+
+// Unsubscribe mocks base method
+func (m *MockStorage) Unsubscribe(ctx context.Context, userName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", ctx, userName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe
+func (mr *MockStorageMockRecorder) Unsubscribe(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockStorage)(nil).Unsubscribe), ctx, userName)
+}
+
+// WorkoutSnapshot mocks base method
+func (m *MockStorage) WorkoutSnapshot(ctx context.Context, userName string) (bot.WorkoutSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkoutSnapshot", ctx, userName)
+	ret0, _ := ret[0].(bot.WorkoutSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkoutSnapshot indicates an expected call of WorkoutSnapshot
+func (mr *MockStorageMockRecorder) WorkoutSnapshot(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkoutSnapshot", reflect.TypeOf((*MockStorage)(nil).WorkoutSnapshot), ctx, userName)
+}
+
+// UpdateWorkoutSnapshot mocks base method
+func (m *MockStorage) UpdateWorkoutSnapshot(ctx context.Context, userName string, snapshot bot.WorkoutSnapshot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWorkoutSnapshot", ctx, userName, snapshot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWorkoutSnapshot indicates an expected call of UpdateWorkoutSnapshot
+func (mr *MockStorageMockRecorder) UpdateWorkoutSnapshot(ctx, userName, snapshot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWorkoutSnapshot", reflect.TypeOf((*MockStorage)(nil).UpdateWorkoutSnapshot), ctx, userName, snapshot)
+}
+
+// CalendarToken mocks base method
+func (m *MockStorage) CalendarToken(ctx context.Context, userName string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalendarToken", ctx, userName)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalendarToken indicates an expected call of CalendarToken
+func (mr *MockStorageMockRecorder) CalendarToken(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalendarToken", reflect.TypeOf((*MockStorage)(nil).CalendarToken), ctx, userName)
+}
+
+// UpdateCalendarToken mocks base method
+func (m *MockStorage) UpdateCalendarToken(ctx context.Context, userName, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCalendarToken", ctx, userName, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCalendarToken indicates an expected call of UpdateCalendarToken
+func (mr *MockStorageMockRecorder) UpdateCalendarToken(ctx, userName, token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserToken", reflect.TypeOf((*MockStorage)(nil).UpdateUserToken), ctx, userName, userToken)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCalendarToken", reflect.TypeOf((*MockStorage)(nil).UpdateCalendarToken), ctx, userName, token)
+}
+
+// CalendarUserName mocks base method
+func (m *MockStorage) CalendarUserName(ctx context.Context, token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CalendarUserName", ctx, token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CalendarUserName indicates an expected call of CalendarUserName
+func (mr *MockStorageMockRecorder) CalendarUserName(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CalendarUserName", reflect.TypeOf((*MockStorage)(nil).CalendarUserName), ctx, token)
+}
+
+// GoalRace mocks base method
+func (m *MockStorage) GoalRace(ctx context.Context, userName string) (bot.Race, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GoalRace", ctx, userName)
+	ret0, _ := ret[0].(bot.Race)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GoalRace indicates an expected call of GoalRace
+func (mr *MockStorageMockRecorder) GoalRace(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GoalRace", reflect.TypeOf((*MockStorage)(nil).GoalRace), ctx, userName)
+}
+
+// UpdateGoalRace mocks base method
+func (m *MockStorage) UpdateGoalRace(ctx context.Context, userName string, race bot.Race) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGoalRace", ctx, userName, race)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGoalRace indicates an expected call of UpdateGoalRace
+func (mr *MockStorageMockRecorder) UpdateGoalRace(ctx, userName, race interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGoalRace", reflect.TypeOf((*MockStorage)(nil).UpdateGoalRace), ctx, userName, race)
+}
+
+// DeleteGoalRace mocks base method
+func (m *MockStorage) DeleteGoalRace(ctx context.Context, userName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteGoalRace", ctx, userName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteGoalRace indicates an expected call of DeleteGoalRace
+func (mr *MockStorageMockRecorder) DeleteGoalRace(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteGoalRace", reflect.TypeOf((*MockStorage)(nil).DeleteGoalRace), ctx, userName)
+}
+
+// UpdateUserActivity mocks base method
+func (m *MockStorage) UpdateUserActivity(ctx context.Context, activity bot.UserActivity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserActivity", ctx, activity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserActivity indicates an expected call of UpdateUserActivity
+func (mr *MockStorageMockRecorder) UpdateUserActivity(ctx, activity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserActivity", reflect.TypeOf((*MockStorage)(nil).UpdateUserActivity), ctx, activity)
+}
+
+// UserActivities mocks base method
+func (m *MockStorage) UserActivities(ctx context.Context) ([]bot.UserActivity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserActivities", ctx)
+	ret0, _ := ret[0].([]bot.UserActivity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserActivities indicates an expected call of UserActivities
+func (mr *MockStorageMockRecorder) UserActivities(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserActivities", reflect.TypeOf((*MockStorage)(nil).UserActivities), ctx)
+}
+
+// AuthorizedUsers mocks base method
+func (m *MockStorage) AuthorizedUsers(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthorizedUsers", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthorizedUsers indicates an expected call of AuthorizedUsers
+func (mr *MockStorageMockRecorder) AuthorizedUsers(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthorizedUsers", reflect.TypeOf((*MockStorage)(nil).AuthorizedUsers), ctx)
+}
+
+// DeleteUser mocks base method
+func (m *MockStorage) DeleteUser(ctx context.Context, userName string) ([]bot.DeletedRows, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, userName)
+	ret0, _ := ret[0].([]bot.DeletedRows)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUser indicates an expected call of DeleteUser
+func (mr *MockStorageMockRecorder) DeleteUser(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockStorage)(nil).DeleteUser), ctx, userName)
+}
+
+// MockSettingsStore is a mock of SettingsStore interface
+type MockSettingsStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettingsStoreMockRecorder
+}
+
+// MockSettingsStoreMockRecorder is the mock recorder for MockSettingsStore
+type MockSettingsStoreMockRecorder struct {
+	mock *MockSettingsStore
+}
+
+// NewMockSettingsStore creates a new mock instance
+func NewMockSettingsStore(ctrl *gomock.Controller) *MockSettingsStore {
+	mock := &MockSettingsStore{ctrl: ctrl}
+	mock.recorder = &MockSettingsStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockSettingsStore) EXPECT() *MockSettingsStoreMockRecorder {
+	return m.recorder
+}
+
+// Settings mocks base method
+func (m *MockSettingsStore) Settings(ctx context.Context, userName string) (bot.Settings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Settings", ctx, userName)
+	ret0, _ := ret[0].(bot.Settings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Settings indicates an expected call of Settings
+func (mr *MockSettingsStoreMockRecorder) Settings(ctx, userName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Settings", reflect.TypeOf((*MockSettingsStore)(nil).Settings), ctx, userName)
+}
+
+// UpdateSettings mocks base method
+func (m *MockSettingsStore) UpdateSettings(ctx context.Context, userName string, settings bot.Settings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", ctx, userName, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings
+func (mr *MockSettingsStoreMockRecorder) UpdateSettings(ctx, userName, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockSettingsStore)(nil).UpdateSettings), ctx, userName, settings)
 }
 
 // MockFinalSurge is a mock of FinalSurge interface
@@ -192,3 +623,100 @@ func (mr *MockClockMockRecorder) Now() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Now", reflect.TypeOf((*MockClock)(nil).Now))
 }
+
+// After mocks base method
+func (m *MockClock) After(d time.Duration) <-chan time.Time {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "After", d)
+	ret0, _ := ret[0].(<-chan time.Time)
+	return ret0
+}
+
+// After indicates an expected call of After
+func (mr *MockClockMockRecorder) After(d interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "After", reflect.TypeOf((*MockClock)(nil).After), d)
+}
+
+// MockUpdatesAPI is a mock of UpdatesAPI interface
+type MockUpdatesAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockUpdatesAPIMockRecorder
+}
+
+// MockUpdatesAPIMockRecorder is the mock recorder for MockUpdatesAPI
+type MockUpdatesAPIMockRecorder struct {
+	mock *MockUpdatesAPI
+}
+
+// NewMockUpdatesAPI creates a new mock instance
+func NewMockUpdatesAPI(ctrl *gomock.Controller) *MockUpdatesAPI {
+	mock := &MockUpdatesAPI{ctrl: ctrl}
+	mock.recorder = &MockUpdatesAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockUpdatesAPI) EXPECT() *MockUpdatesAPIMockRecorder {
+	return m.recorder
+}
+
+// SetWebhook mocks base method
+func (m *MockUpdatesAPI) SetWebhook(config telegram_bot_api.WebhookConfig) (telegram_bot_api.APIResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWebhook", config)
+	ret0, _ := ret[0].(telegram_bot_api.APIResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWebhook indicates an expected call of SetWebhook
+func (mr *MockUpdatesAPIMockRecorder) SetWebhook(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWebhook", reflect.TypeOf((*MockUpdatesAPI)(nil).SetWebhook), config)
+}
+
+// GetWebhookInfo mocks base method
+func (m *MockUpdatesAPI) GetWebhookInfo() (telegram_bot_api.WebhookInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhookInfo")
+	ret0, _ := ret[0].(telegram_bot_api.WebhookInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhookInfo indicates an expected call of GetWebhookInfo
+func (mr *MockUpdatesAPIMockRecorder) GetWebhookInfo() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhookInfo", reflect.TypeOf((*MockUpdatesAPI)(nil).GetWebhookInfo))
+}
+
+// GetUpdatesChan mocks base method
+func (m *MockUpdatesAPI) GetUpdatesChan(config telegram_bot_api.UpdateConfig) (telegram_bot_api.UpdatesChannel, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUpdatesChan", config)
+	ret0, _ := ret[0].(telegram_bot_api.UpdatesChannel)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUpdatesChan indicates an expected call of GetUpdatesChan
+func (mr *MockUpdatesAPIMockRecorder) GetUpdatesChan(config interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpdatesChan", reflect.TypeOf((*MockUpdatesAPI)(nil).GetUpdatesChan), config)
+}
+
+// MakeRequest mocks base method
+func (m *MockUpdatesAPI) MakeRequest(endpoint string, params url.Values) (telegram_bot_api.APIResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MakeRequest", endpoint, params)
+	ret0, _ := ret[0].(telegram_bot_api.APIResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MakeRequest indicates an expected call of MakeRequest
+func (mr *MockUpdatesAPIMockRecorder) MakeRequest(endpoint, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeRequest", reflect.TypeOf((*MockUpdatesAPI)(nil).MakeRequest), endpoint, params)
+}
@@ -0,0 +1,134 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const planMaxDays = 31
+
+var ErrPlanArgs = errors.New("invalid plan arguments")
+
+var planDaysRe = regexp.MustCompile(`^(\d+)([dw])$`)
+
+type PlanRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ParsePlanArgs resolves arguments of /plan against today:
+//
+//	today, tomorrow
+//	mon, saturday      the nearest such day from today on
+//	2026-10-20, 20.10  the date, the current year by default
+//	20.10-26.10        the range of dates
+//	week, next week    the rest of this week or the whole next week from Monday
+//	3d, 2w             days or weeks from today on
+func ParsePlanArgs(args string, today time.Time) (PlanRange, error) {
+	args = strings.ToLower(strings.Join(strings.Fields(args), " "))
+
+	r, err := parsePlanRange(args, today)
+	if err != nil {
+		return PlanRange{}, err
+	}
+
+	if r.End.Before(r.Start) || r.End.Sub(r.Start) >= planMaxDays*24*time.Hour {
+		return PlanRange{}, fmt.Errorf("%w: range %s", ErrPlanArgs, args)
+	}
+
+	return r, nil
+}
+
+func parsePlanRange(args string, today time.Time) (PlanRange, error) {
+	day := func(d time.Time) PlanRange {
+		return PlanRange{Start: d, End: d}
+	}
+
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+
+	switch args {
+	case "", "today":
+		return day(today), nil
+	case "tomorrow":
+		return day(today.AddDate(0, 0, 1)), nil
+	case "week", "this week":
+		return PlanRange{Start: today, End: monday.AddDate(0, 0, 6)}, nil
+	case "next week":
+		return PlanRange{Start: monday.AddDate(0, 0, 7), End: monday.AddDate(0, 0, 13)}, nil
+	}
+
+	if weekday, err := ParseWeekday(args); err == nil {
+		return day(today.AddDate(0, 0, (int(weekday)-int(today.Weekday())+7)%7)), nil
+	}
+
+	if m := planDaysRe.FindStringSubmatch(args); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 {
+			return PlanRange{}, fmt.Errorf("%w: %s", ErrPlanArgs, args)
+		}
+
+		if m[2] == "w" {
+			n *= 7
+		}
+
+		return PlanRange{Start: today, End: today.AddDate(0, 0, n-1)}, nil
+	}
+
+	if date, err := parsePlanDate(args, today); err == nil {
+		return day(date), nil
+	}
+
+	// ISO dates contain '-' too, so a range is split only when both parts are dates.
+	for i := strings.Index(args, "-"); i >= 0; i = nextIndex(args, "-", i) {
+		start, errStart := parsePlanDate(strings.TrimSpace(args[:i]), today)
+		end, errEnd := parsePlanDate(strings.TrimSpace(args[i+1:]), today)
+
+		if errStart == nil && errEnd == nil {
+			// 28.12-03.01 ends next year.
+			if end.Before(start) {
+				end = end.AddDate(1, 0, 0)
+			}
+
+			return PlanRange{Start: start, End: end}, nil
+		}
+	}
+
+	return PlanRange{}, fmt.Errorf("%w: %s", ErrPlanArgs, args)
+}
+
+func parsePlanDate(s string, today time.Time) (time.Time, error) {
+	if date, err := time.Parse(exportDateLayout, s); err == nil {
+		return date, nil
+	}
+
+	date, err := time.Parse("02.01", strings.TrimSuffix(s, "."))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse date: %w", err)
+	}
+
+	return time.Date(today.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+func nextIndex(s, substr string, after int) int {
+	i := strings.Index(s[after+1:], substr)
+	if i < 0 {
+		return -1
+	}
+
+	return after + 1 + i
+}
+
+// UserTimeZone returns the time zone chosen by the user with /timezone or the zone of now otherwise.
+func UserTimeZone(ctx context.Context, db SettingsStore, userName string, now time.Time) (*time.Location, error) {
+	settings, err := UserSettings(ctx, db, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings.Location(now.Location())
+}
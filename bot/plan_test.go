@@ -0,0 +1,112 @@
+package bot_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestParsePlanArgs(t *testing.T) {
+	// Monday.
+	today := time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	for args, tc := range map[string]struct {
+		expected PlanRange
+		err      error
+	}{
+		"":                      {expected: PlanRange{Start: today, End: today}},
+		"tomorrow":              {expected: PlanRange{Start: date(time.October, 20), End: date(time.October, 20)}},
+		"mon":                   {expected: PlanRange{Start: today, End: today}},
+		"Saturday":              {expected: PlanRange{Start: date(time.October, 24), End: date(time.October, 24)}},
+		"2026-10-20":            {expected: PlanRange{Start: date(time.October, 20), End: date(time.October, 20)}},
+		"22.10":                 {expected: PlanRange{Start: date(time.October, 22), End: date(time.October, 22)}},
+		"20.10-26.10":           {expected: PlanRange{Start: date(time.October, 20), End: date(time.October, 26)}},
+		"2026-10-20-2026-10-22": {expected: PlanRange{Start: date(time.October, 20), End: date(time.October, 22)}},
+		"28.12-03.01": {expected: PlanRange{
+			Start: date(time.December, 28),
+			End:   time.Date(2027, time.January, 3, 0, 0, 0, 0, time.UTC),
+		}},
+		"week":        {expected: PlanRange{Start: today, End: date(time.October, 25)}},
+		"next  week":  {expected: PlanRange{Start: date(time.October, 26), End: date(time.November, 1)}},
+		"3d":          {expected: PlanRange{Start: today, End: date(time.October, 21)}},
+		"2w":          {expected: PlanRange{Start: today, End: date(time.November, 1)}},
+		"0d":          {err: ErrPlanArgs},
+		"5w":          {err: ErrPlanArgs},
+		"26.10-20.10": {err: ErrPlanArgs},
+		"someday":     {err: ErrPlanArgs},
+	} {
+		args, tc := args, tc
+
+		t.Run(args, func(t *testing.T) {
+			actual, err := ParsePlanArgs(args, today)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("actual err=%v, expected=%v", err, tc.err)
+			}
+
+			if actual != tc.expected {
+				t.Errorf("actual=%+v, expected=%+v", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestBot_ProcessUpdate_Plan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	senderMock := mock.NewMockSender(ctrl)
+	fsMock := mock.NewMockFinalSurge(ctrl)
+	storageMock := mock.NewMockStorage(ctrl)
+	clockMock := mock.NewMockClock(ctrl)
+	bot := NewBot(senderMock, storageMock, fsMock, clockMock, &Config{}, NewTaskTemplates())
+	const userName = "alexandear"
+	const chatID = int64(20)
+	storageMock.EXPECT().Settings(gomock.Any(), userName).
+		Return(Settings{TimeZone: "Europe/Kyiv", Markup: MarkupPlain}, nil).AnyTimes()
+	storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	// It is already Tuesday in Kyiv.
+	clockMock.EXPECT().Now().Return(time.Date(2026, time.October, 19, 22, 30, 0, 0, time.UTC)).Times(1)
+	storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return([]UserToken{userToken}, nil).Times(1)
+	tuesday := time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)
+	wednesday := time.Date(2026, time.October, 21, 0, 0, 0, 0, time.UTC)
+	fsMock.EXPECT().Workouts(gomock.Any(), userToken, wednesday, wednesday).
+		Return([]Workout{{Date: wednesday, Description: "Tempo 8 km"}}, nil).Times(1)
+	senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+		BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+		Text: `Tasks:
+Tomorrow 21.10:
+Tempo 8 km
+`,
+	}).Times(1)
+
+	const command = "/plan tomorrow"
+	if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: chatID},
+			From:     &tgbotapi.User{UserName: userName},
+			Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/plan")}},
+			Text:     command,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual := NewPlanView(LocaleFor(LanguageEnglish), nil, PlanRange{Start: tuesday, End: tuesday.AddDate(0, 0, 2)},
+		tuesday); actual.Days[0].Name != "Today" || actual.Days[1].Name != "Tomorrow" ||
+		actual.Days[2].Name != "Thursday" {
+		t.Errorf("actual=%+v, expected Today, Tomorrow, Thursday", actual.Days)
+	}
+}
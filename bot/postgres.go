@@ -2,11 +2,26 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
+// userTables lists all tables with user data. Every new table keyed by user_name must be added here.
+var userTables = []string{
+	"accounts",
+	"subscriptions",
+	"workout_snapshots",
+	"calendar_tokens",
+	"user_settings",
+	"goal_races",
+	"user_activity",
+}
+
 type Postgres struct {
 	dbPool *pgxpool.Pool
 }
@@ -17,49 +32,667 @@ func NewPostgres(pool *pgxpool.Pool) *Postgres {
 
 func (p *Postgres) Init(ctx context.Context) error {
 	if _, err := p.dbPool.Exec(ctx, `
-CREATE TABLE IF NOT EXISTS user_tokens (
+CREATE TABLE IF NOT EXISTS accounts (
+    user_name char(40) not null,
+    label text not null,
+    user_key text not null,
+    token text not null,
+    active boolean not null,
+    created_at timestamp not null default now(),
+    primary key (user_name, label)
+);`); err != nil {
+		return fmt.Errorf("create table accounts: %w", err)
+	}
+
+	if err := p.migrateUserTokens(ctx); err != nil {
+		return fmt.Errorf("migrate user_tokens: %w", err)
+	}
+
+	if _, err := p.dbPool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS subscriptions (
+    user_name char(40) primary key,
+    chat_id bigint not null,
+    snapshot_at timestamp,
+    snapshot_end date
+);`); err != nil {
+		return fmt.Errorf("create table subscriptions: %w", err)
+	}
+
+	// Tables created before the snapshot window was stored have no snapshot_end.
+	if _, err := p.dbPool.Exec(ctx, `ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS snapshot_end date`); err != nil {
+		return fmt.Errorf("add column subscriptions.snapshot_end: %w", err)
+	}
+
+	if _, err := p.dbPool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS workout_snapshots (
+    user_name char(40) not null,
+    position int not null,
+    workout_date date not null,
+    description text not null,
+    primary key (user_name, position)
+);`); err != nil {
+		return fmt.Errorf("create table workout_snapshots: %w", err)
+	}
+
+	if _, err := p.dbPool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    user_name char(40) primary key,
+    token text not null unique
+);`); err != nil {
+		return fmt.Errorf("create table calendar_tokens: %w", err)
+	}
+
+	if _, err := p.dbPool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_name char(40) primary key,
+    language text not null default '',
+    time_zone text not null default '',
+    markup text not null default '',
+    task_format text not null default '',
+    units text not null default '',
+    task_days int not null default 0,
+    notify_hour int,
+    remind_hour int
+);`); err != nil {
+		return fmt.Errorf("create table user_settings: %w", err)
+	}
+
+	// Tables created before the workout reminder have no remind_hour.
+	if _, err := p.dbPool.Exec(ctx, `ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS remind_hour int`); err != nil {
+		return fmt.Errorf("add column user_settings.remind_hour: %w", err)
+	}
+
+	if err := p.migrateUserPreferences(ctx); err != nil {
+		return fmt.Errorf("migrate user_preferences: %w", err)
+	}
+
+	if _, err := p.dbPool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS goal_races (
+    user_name char(40) primary key,
+    race_date date not null,
+    name text not null
+);`); err != nil {
+		return fmt.Errorf("create table goal_races: %w", err)
+	}
+
+	if _, err := p.dbPool.Exec(ctx, `
+CREATE TABLE IF NOT EXISTS user_activity (
     user_name char(40) primary key,
-    user_key char(40) not null,
-    token char(40) not null
+    user_id bigint not null,
+    chat_id bigint not null,
+    last_active timestamptz not null
 );`); err != nil {
-		return fmt.Errorf("create table user_tokens")
+		return fmt.Errorf("create table user_activity: %w", err)
+	}
+
+	return nil
+}
+
+// migrateUserTokens moves tokens from the table used before multiple accounts were supported.
+func (p *Postgres) migrateUserTokens(ctx context.Context) error {
+	var exists bool
+	if err := p.dbPool.QueryRow(ctx, `SELECT to_regclass('user_tokens') IS NOT NULL`).Scan(&exists); err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	if !exists {
+		return nil
+	}
+
+	tx, err := p.dbPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if _, err := tx.Exec(ctx, `
+INSERT INTO accounts(user_name, label, user_key, token, active)
+	SELECT user_name, $1, trim(user_key), trim(token), true FROM user_tokens
+	ON CONFLICT DO NOTHING`, DefaultAccountLabel); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DROP TABLE user_tokens`); err != nil {
+		return fmt.Errorf("drop: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// migrateUserPreferences moves preferences from the key-value table used before /settings.
+func (p *Postgres) migrateUserPreferences(ctx context.Context) error {
+	var exists bool
+	if err := p.dbPool.QueryRow(ctx, `SELECT to_regclass('user_preferences') IS NOT NULL`).Scan(&exists); err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	if !exists {
+		return nil
+	}
+
+	tx, err := p.dbPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if _, err := tx.Exec(ctx, migrateUserPreferencesQuery); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DROP TABLE user_preferences`); err != nil {
+		return fmt.Errorf("drop: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
 	}
 
 	return nil
 }
 
 func (p *Postgres) UserToken(ctx context.Context, userName string) (UserToken, error) {
-	var userToken UserToken
+	userTokens, err := p.UserTokens(ctx, userName)
+	if err != nil {
+		return UserToken{}, err
+	}
+
+	return userTokens[0], nil
+}
+
+func (p *Postgres) UserTokens(ctx context.Context, userName string) ([]UserToken, error) {
+	accounts, err := p.Accounts(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
 
-	rows, err := p.dbPool.Query(ctx, `SELECT user_key, token FROM user_tokens WHERE user_name=$1`, userName)
+	var userTokens []UserToken
+
+	for _, a := range accounts {
+		if a.Active {
+			userTokens = append(userTokens, a.UserToken)
+		}
+	}
+
+	if len(userTokens) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return userTokens, nil
+}
+
+func (p *Postgres) UpdateUserToken(ctx context.Context, userName, label string, userToken UserToken) error {
+	tx, err := p.dbPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if err := lockUser(ctx, tx, userName); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE accounts SET active=false WHERE user_name=$1`, userName); err != nil {
+		return fmt.Errorf("deactivate: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+INSERT INTO accounts(user_name, label, user_key, token, active) VALUES ($1, $2, $3, $4, true)
+	ON CONFLICT (user_name, label) DO UPDATE SET user_key=excluded.user_key, token=excluded.token, active=true`,
+		userName, label, userToken.UserKey, userToken.Token); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) Accounts(ctx context.Context, userName string) ([]Account, error) {
+	rows, err := p.dbPool.Query(ctx, `
+SELECT label, user_key, token, active FROM accounts WHERE user_name=$1 ORDER BY created_at, label`, userName)
 	if err != nil {
-		return UserToken{}, fmt.Errorf("query: %w", err)
+		return nil, fmt.Errorf("query: %w", err)
 	}
 
+	var accounts []Account
+
 	for rows.Next() {
-		if errScan := rows.Scan(&userToken.UserKey, &userToken.Token); errScan != nil {
-			return UserToken{}, fmt.Errorf("failed during scan: %w", errScan)
+		var a Account
+		if errScan := rows.Scan(&a.Label, &a.UserToken.UserKey, &a.UserToken.Token, &a.Active); errScan != nil {
+			return nil, fmt.Errorf("failed during scan: %w", errScan)
 		}
+
+		accounts = append(accounts, a)
 	}
 
 	if rows.Err() != nil {
-		return UserToken{}, fmt.Errorf("failed rows: %w", rows.Err())
+		return nil, fmt.Errorf("failed rows: %w", rows.Err())
+	}
+
+	return accounts, nil
+}
+
+func (p *Postgres) ActivateAccount(ctx context.Context, userName, label string) error {
+	tx, err := p.dbPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if err := lockUser(ctx, tx, userName); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `UPDATE accounts SET active=true WHERE user_name=$1 AND label=$2`, userName, label)
+	if err != nil {
+		return fmt.Errorf("activate: %w", err)
 	}
 
-	if userToken.UserKey == "" {
-		return UserToken{}, ErrNotFound
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
 	}
 
-	return userToken, nil
+	if _, err := tx.Exec(ctx, `UPDATE accounts SET active=false WHERE user_name=$1 AND label<>$2`,
+		userName, label); err != nil {
+		return fmt.Errorf("deactivate: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) ActivateAllAccounts(ctx context.Context, userName string) error {
+	if _, err := p.dbPool.Exec(ctx, `UPDATE accounts SET active=true WHERE user_name=$1`, userName); err != nil {
+		return fmt.Errorf("activate: %w", err)
+	}
+
+	return nil
 }
 
-func (p *Postgres) UpdateUserToken(ctx context.Context, userName string, userToken UserToken) error {
+func (p *Postgres) DeleteAccount(ctx context.Context, userName, label string) error {
+	tx, err := p.dbPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if err := lockUser(ctx, tx, userName); err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM accounts WHERE user_name=$1 AND label=$2`, userName, label)
+	if err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, `
+UPDATE accounts SET active=true WHERE user_name=$1 AND NOT EXISTS (
+    SELECT 1 FROM accounts WHERE user_name=$1 AND active
+) AND label=(
+    SELECT label FROM accounts WHERE user_name=$1 ORDER BY created_at, label LIMIT 1
+)`, userName); err != nil {
+		return fmt.Errorf("activate remaining: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) DeleteAccounts(ctx context.Context, userName string) error {
+	if _, err := p.dbPool.Exec(ctx, `DELETE FROM accounts WHERE user_name=$1`, userName); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	rows, err := p.dbPool.Query(ctx, `SELECT user_name, chat_id FROM subscriptions`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	var subscriptions []Subscription
+
+	for rows.Next() {
+		var s Subscription
+		if errScan := rows.Scan(&s.UserName, &s.ChatID); errScan != nil {
+			return nil, fmt.Errorf("failed during scan: %w", errScan)
+		}
+
+		s.UserName = strings.TrimSpace(s.UserName)
+		subscriptions = append(subscriptions, s)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed rows: %w", rows.Err())
+	}
+
+	return subscriptions, nil
+}
+
+func (p *Postgres) Subscribe(ctx context.Context, subscription Subscription) error {
 	if _, err := p.dbPool.Exec(ctx, `
-INSERT INTO user_tokens(user_name, user_key, token) VALUES ($1, $2, $3) ON CONFLICT (user_name)
-	DO UPDATE SET user_key=excluded.user_key, token=excluded.token`,
-		userName, userToken.UserKey, userToken.Token); err != nil {
+INSERT INTO subscriptions(user_name, chat_id) VALUES ($1, $2) ON CONFLICT (user_name)
+	DO UPDATE SET chat_id=excluded.chat_id`,
+		subscription.UserName, subscription.ChatID); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) Unsubscribe(ctx context.Context, userName string) error {
+	tx, err := p.dbPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if _, err := tx.Exec(ctx, `DELETE FROM subscriptions WHERE user_name=$1`, userName); err != nil {
+		return fmt.Errorf("delete subscription: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM workout_snapshots WHERE user_name=$1`, userName); err != nil {
+		return fmt.Errorf("delete workout snapshot: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) WorkoutSnapshot(ctx context.Context, userName string) (WorkoutSnapshot, error) {
+	var snapshotAt, snapshotEnd *time.Time
+
+	err := p.dbPool.QueryRow(ctx, `SELECT snapshot_at, snapshot_end FROM subscriptions WHERE user_name=$1`,
+		userName).Scan(&snapshotAt, &snapshotEnd)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return WorkoutSnapshot{}, ErrNotFound
+	}
+
+	if err != nil {
+		return WorkoutSnapshot{}, fmt.Errorf("query snapshot time: %w", err)
+	}
+
+	if snapshotAt == nil {
+		return WorkoutSnapshot{}, ErrNotFound
+	}
+
+	var snapshot WorkoutSnapshot
+	if snapshotEnd != nil {
+		snapshot.End = *snapshotEnd
+	}
+
+	rows, err := p.dbPool.Query(ctx, `
+SELECT workout_date, description FROM workout_snapshots WHERE user_name=$1 ORDER BY position`, userName)
+	if err != nil {
+		return WorkoutSnapshot{}, fmt.Errorf("query: %w", err)
+	}
+
+	for rows.Next() {
+		var w Workout
+		if errScan := rows.Scan(&w.Date, &w.Description); errScan != nil {
+			return WorkoutSnapshot{}, fmt.Errorf("failed during scan: %w", errScan)
+		}
+
+		snapshot.Workouts = append(snapshot.Workouts, w)
+	}
+
+	if rows.Err() != nil {
+		return WorkoutSnapshot{}, fmt.Errorf("failed rows: %w", rows.Err())
+	}
+
+	return snapshot, nil
+}
+
+func (p *Postgres) UpdateWorkoutSnapshot(ctx context.Context, userName string, snapshot WorkoutSnapshot) error {
+	tx, err := p.dbPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if _, err := tx.Exec(ctx, `DELETE FROM workout_snapshots WHERE user_name=$1`, userName); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	for i, w := range snapshot.Workouts {
+		if _, err := tx.Exec(ctx, `
+INSERT INTO workout_snapshots(user_name, position, workout_date, description) VALUES ($1, $2, $3, $4)`,
+			userName, i, w.Date, w.Description); err != nil {
+			return fmt.Errorf("insert: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE subscriptions SET snapshot_at=now(), snapshot_end=$2 WHERE user_name=$1`,
+		userName, snapshot.End); err != nil {
+		return fmt.Errorf("update snapshot time: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) CalendarToken(ctx context.Context, userName string) (string, error) {
+	var token string
+
+	err := p.dbPool.QueryRow(ctx, `SELECT token FROM calendar_tokens WHERE user_name=$1`, userName).Scan(&token)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("query: %w", err)
+	}
+
+	return token, nil
+}
+
+func (p *Postgres) UpdateCalendarToken(ctx context.Context, userName, token string) error {
+	if _, err := p.dbPool.Exec(ctx, `
+INSERT INTO calendar_tokens(user_name, token) VALUES ($1, $2) ON CONFLICT (user_name)
+	DO UPDATE SET token=excluded.token`,
+		userName, token); err != nil {
 		return fmt.Errorf("update: %w", err)
 	}
 
 	return nil
 }
+
+func (p *Postgres) CalendarUserName(ctx context.Context, token string) (string, error) {
+	var userName string
+
+	err := p.dbPool.QueryRow(ctx, `SELECT user_name FROM calendar_tokens WHERE token=$1`, token).Scan(&userName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrNotFound
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("query: %w", err)
+	}
+
+	return strings.TrimSpace(userName), nil
+}
+
+func (p *Postgres) Settings(ctx context.Context, userName string) (Settings, error) {
+	var settings Settings
+
+	err := p.dbPool.QueryRow(ctx, `
+SELECT language, time_zone, markup, task_format, units, task_days, notify_hour, remind_hour
+	FROM user_settings
+	WHERE user_name=$1`, userName).Scan(&settings.Language, &settings.TimeZone, &settings.Markup,
+		&settings.TaskFormat, &settings.Units, &settings.TaskDays, &settings.NotifyHour,
+		&settings.RemindHour)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Settings{}, ErrNotFound
+	}
+
+	if err != nil {
+		return Settings{}, fmt.Errorf("query: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (p *Postgres) UpdateSettings(ctx context.Context, userName string, settings Settings) error {
+	if _, err := p.dbPool.Exec(ctx, `
+INSERT INTO user_settings(user_name, language, time_zone, markup, task_format, units, task_days, notify_hour,
+	remind_hour)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	ON CONFLICT (user_name) DO UPDATE SET language=excluded.language, time_zone=excluded.time_zone,
+		markup=excluded.markup, task_format=excluded.task_format, units=excluded.units,
+		task_days=excluded.task_days, notify_hour=excluded.notify_hour, remind_hour=excluded.remind_hour`,
+		userName, settings.Language, settings.TimeZone, settings.Markup, settings.TaskFormat, settings.Units,
+		settings.TaskDays, settings.NotifyHour, settings.RemindHour); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) GoalRace(ctx context.Context, userName string) (Race, error) {
+	var race Race
+
+	err := p.dbPool.QueryRow(ctx, `SELECT race_date, name FROM goal_races WHERE user_name=$1`,
+		userName).Scan(&race.Date, &race.Name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Race{}, ErrNotFound
+	}
+
+	if err != nil {
+		return Race{}, fmt.Errorf("query: %w", err)
+	}
+
+	return race, nil
+}
+
+func (p *Postgres) UpdateGoalRace(ctx context.Context, userName string, race Race) error {
+	if _, err := p.dbPool.Exec(ctx, `
+INSERT INTO goal_races(user_name, race_date, name) VALUES ($1, $2, $3) ON CONFLICT (user_name)
+	DO UPDATE SET race_date=excluded.race_date, name=excluded.name`,
+		userName, race.Date, race.Name); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) DeleteGoalRace(ctx context.Context, userName string) error {
+	if _, err := p.dbPool.Exec(ctx, `DELETE FROM goal_races WHERE user_name=$1`, userName); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) UpdateUserActivity(ctx context.Context, activity UserActivity) error {
+	if _, err := p.dbPool.Exec(ctx, `
+INSERT INTO user_activity(user_name, user_id, chat_id, last_active) VALUES ($1, $2, $3, $4) ON CONFLICT (user_name)
+	DO UPDATE SET user_id=excluded.user_id, chat_id=excluded.chat_id, last_active=excluded.last_active`,
+		activity.UserName, activity.UserID, activity.ChatID, activity.LastActive); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+func (p *Postgres) UserActivities(ctx context.Context) ([]UserActivity, error) {
+	rows, err := p.dbPool.Query(ctx, `
+SELECT user_name, user_id, chat_id, last_active FROM user_activity ORDER BY user_name`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	var activities []UserActivity
+
+	for rows.Next() {
+		var a UserActivity
+		if errScan := rows.Scan(&a.UserName, &a.UserID, &a.ChatID, &a.LastActive); errScan != nil {
+			return nil, fmt.Errorf("failed during scan: %w", errScan)
+		}
+
+		a.UserName = strings.TrimSpace(a.UserName)
+		activities = append(activities, a)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed rows: %w", rows.Err())
+	}
+
+	return activities, nil
+}
+
+func (p *Postgres) AuthorizedUsers(ctx context.Context) (int, error) {
+	var count int
+	if err := p.dbPool.QueryRow(ctx, `SELECT count(DISTINCT user_name) FROM accounts`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("query: %w", err)
+	}
+
+	return count, nil
+}
+
+func (p *Postgres) DeleteUser(ctx context.Context, userName string) ([]DeletedRows, error) {
+	tx, err := p.dbPool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	deleted := make([]DeletedRows, 0, len(userTables))
+
+	for _, table := range userTables {
+		tag, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE user_name=$1`, userName)
+		if err != nil {
+			return nil, fmt.Errorf("delete from %s: %w", table, err)
+		}
+
+		deleted = append(deleted, DeletedRows{Table: table, Rows: tag.RowsAffected()})
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+
+	return deleted, nil
+}
+
+// lockUser serializes concurrent transactions changing accounts of the same user
+// so that exactly one account stays active.
+func lockUser(ctx context.Context, tx pgx.Tx, userName string) error {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, userName); err != nil {
+		return fmt.Errorf("lock user: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package bot_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/storagetest"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TestPostgres runs against the database from TEST_DATABASE_URL, for example:
+//
+//	TEST_DATABASE_URL=postgresql://postgres:@localhost:5432/postgres go test ./bot -run TestPostgres
+//
+// Every test uses its own schema that is dropped afterwards.
+func TestPostgres(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) Storage {
+		pg := NewPostgres(postgresPool(t))
+		if err := pg.Init(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		return pg
+	})
+}
+
+func TestPostgres_Init_migrateUserTokens(t *testing.T) {
+	ctx := context.Background()
+	pool := postgresPool(t)
+
+	if _, err := pool.Exec(ctx, `
+CREATE TABLE user_tokens (
+    user_name char(40) primary key,
+    user_key char(40) not null,
+    token char(40) not null
+);
+INSERT INTO user_tokens VALUES
+    ('alexandear', 'a0acc35a-c910-4f80-b410-b616d03cf917', 'd174c652-b12f-4aad-b730-a43a2c74fa9f');
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	pg := NewPostgres(pool)
+	if err := pg.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Init must be idempotent after the migration.
+	if err := pg.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	userToken, err := pg.UserToken(ctx, "alexandear")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}); userToken != expected {
+		t.Errorf("actual=%q, expected=%q", userToken, expected)
+	}
+}
+
+func postgresPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	databaseURL := os.Getenv("TEST_DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	ctx := context.Background()
+
+	admin, err := pgxpool.Connect(ctx, databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := fmt.Sprintf("storagetest_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(ctx, `CREATE SCHEMA `+schema); err != nil {
+		admin.Close()
+		t.Fatal(err)
+	}
+
+	config, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+
+	pool, err := pgxpool.ConnectConfig(ctx, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		pool.Close()
+
+		if _, err := admin.Exec(ctx, `DROP SCHEMA `+schema+` CASCADE`); err != nil {
+			t.Error(err)
+		}
+
+		admin.Close()
+	})
+
+	return pool
+}
@@ -0,0 +1,153 @@
+package bot
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	RaceArgPin   = "pin"
+	RaceArgUnpin = "unpin"
+
+	// racesDays is how far ahead /races looks for races.
+	racesDays = 183
+	// countdownDays is how far ahead the task message looks for races.
+	countdownDays = 28
+)
+
+var ErrRaceArgs = errors.New("invalid race arguments")
+
+type Race struct {
+	Date time.Time
+	Name string
+}
+
+// UpcomingRaces returns races from today on sorted by date.
+// The goal race replaces a detected race on the same date because the user named it.
+func UpcomingRaces(workouts []Workout, goal *Race, today time.Time) []Race {
+	var races []Race
+
+	if goal != nil && !goal.Date.Before(today) {
+		races = append(races, *goal)
+	}
+
+	for _, w := range workouts {
+		if !w.Race || w.Date.Before(today) || (goal != nil && w.Date.Equal(goal.Date)) {
+			continue
+		}
+
+		races = append(races, Race{Date: w.Date, Name: raceName(w)})
+	}
+
+	sort.SliceStable(races, func(i, j int) bool {
+		return races[i].Date.Before(races[j].Date)
+	})
+
+	return races
+}
+
+// CountdownRace returns the goal race if it is upcoming, or the nearest race otherwise.
+func CountdownRace(races []Race, goal *Race) (Race, bool) {
+	for _, r := range races {
+		if goal != nil && r == *goal {
+			return r, true
+		}
+	}
+
+	if len(races) == 0 {
+		return Race{}, false
+	}
+
+	return races[0], true
+}
+
+// ParseGoalRace parses arguments of /races pin of the form "2021-04-18 City Marathon".
+func ParseGoalRace(args string, today time.Time) (Race, error) {
+	date, name, _ := strings.Cut(strings.TrimSpace(args), " ")
+	name = strings.TrimSpace(name)
+
+	if name == "" {
+		return Race{}, fmt.Errorf("%w: empty name", ErrRaceArgs)
+	}
+
+	d, err := time.Parse(exportDateLayout, date)
+	if err != nil {
+		return Race{}, fmt.Errorf("%w: date: %v", ErrRaceArgs, err)
+	}
+
+	if d.Before(today) {
+		return Race{}, fmt.Errorf("%w: date %s is in the past", ErrRaceArgs, date)
+	}
+
+	return Race{Date: d, Name: name}, nil
+}
+
+func MessageCountdown(loc *Locale, race Race, today time.Time) string {
+	switch days := daysBetween(today, race.Date); days {
+	case 0:
+		return loc.T(msgRaceToday, race.Name)
+	case 1:
+		return loc.T(msgRaceTomorrow, race.Name)
+	default:
+		return loc.T(msgRaceCountdown, days, race.Name)
+	}
+}
+
+func MessageRaces(loc *Locale, races []Race, goal *Race, today time.Time) string {
+	if len(races) == 0 {
+		return loc.T(msgNoRaces) + "\n\n" + loc.T(msgRacesUsage)
+	}
+
+	msg := strings.Builder{}
+	msg.WriteString(loc.T(msgRaces))
+	msg.WriteByte('\n')
+
+	for _, r := range races {
+		msg.WriteString(loc.Date(r.Date))
+		msg.WriteByte(' ')
+		msg.WriteString(r.Name)
+		msg.WriteString(" - ")
+		msg.WriteString(daysLeft(loc, daysBetween(today, r.Date)))
+
+		if goal != nil && r == *goal {
+			msg.WriteString(" ⭐")
+		}
+
+		msg.WriteByte('\n')
+	}
+
+	msg.WriteByte('\n')
+	msg.WriteString(loc.T(msgRacesUsage))
+
+	return msg.String()
+}
+
+func raceName(w Workout) string {
+	if w.Name != "" {
+		return w.Name
+	}
+
+	if line, _, _ := strings.Cut(w.Description, "\n"); line != "" {
+		return line
+	}
+
+	return w.Activity
+}
+
+func daysLeft(loc *Locale, days int) string {
+	switch days {
+	case 0:
+		return loc.T(msgToday)
+	case 1:
+		return loc.T(msgTomorrow)
+	default:
+		return loc.T(msgDaysLeft, days)
+	}
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
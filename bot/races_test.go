@@ -0,0 +1,158 @@
+package bot_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestUpcomingRaces(t *testing.T) {
+	today := time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)
+	half := time.Date(2021, time.April, 4, 0, 0, 0, 0, time.UTC)
+	marathon := time.Date(2021, time.April, 18, 0, 0, 0, 0, time.UTC)
+	workouts := []Workout{
+		{Date: marathon, Description: "Marathon\nGoal pace 4:15", Race: true},
+		{Date: today.AddDate(0, 0, -3), Name: "Past 10K", Race: true},
+		{Date: half, Name: "Spring Half", Race: true},
+		{Date: today, Description: "Easy 5 km"},
+	}
+
+	t.Run("detected", func(t *testing.T) {
+		races := UpcomingRaces(workouts, nil, today)
+
+		if expected := []Race{
+			{Date: half, Name: "Spring Half"},
+			{Date: marathon, Name: "Marathon"},
+		}; !reflect.DeepEqual(races, expected) {
+			t.Errorf("actual=%+v, expected=%+v", races, expected)
+		}
+
+		race, ok := CountdownRace(races, nil)
+		if !ok || race.Name != "Spring Half" {
+			t.Errorf("actual=%+v, expected nearest race", race)
+		}
+	})
+
+	t.Run("goal", func(t *testing.T) {
+		goal := &Race{Date: marathon, Name: "City Marathon"}
+		races := UpcomingRaces(workouts, goal, today)
+
+		if expected := []Race{
+			{Date: half, Name: "Spring Half"},
+			*goal,
+		}; !reflect.DeepEqual(races, expected) {
+			t.Errorf("actual=%+v, expected=%+v", races, expected)
+		}
+
+		race, ok := CountdownRace(races, goal)
+		if !ok || race != *goal {
+			t.Errorf("actual=%+v, expected goal race", race)
+		}
+
+		if actual, expected := MessageCountdown(LocaleFor(LanguageEnglish), race, today),
+			"Race in 17 days: City Marathon"; actual != expected {
+			t.Errorf("actual=%s, expected=%s", actual, expected)
+		}
+
+		if actual, expected := MessageRaces(LocaleFor(LanguageEnglish), races, goal, today), `Upcoming races:
+04.04 Spring Half - 3 days left
+18.04 City Marathon - 17 days left ⭐
+
+/races pin 2021-04-18 City Marathon - pin the goal race
+/races unpin - unpin the goal race`; actual != expected {
+			t.Errorf("actual=%s, expected=%s", actual, expected)
+		}
+	})
+}
+
+func TestParseGoalRace(t *testing.T) {
+	today := time.Date(2021, time.April, 1, 0, 0, 0, 0, time.UTC)
+
+	race, err := ParseGoalRace("2021-04-18  City Marathon ", today)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (Race{Date: time.Date(2021, time.April, 18, 0, 0, 0, 0, time.UTC), Name: "City Marathon"}); race != expected {
+		t.Errorf("actual=%+v, expected=%+v", race, expected)
+	}
+
+	for _, args := range []string{"", "2021-04-18", "18.04 City Marathon", "2021-03-31 Past"} {
+		if _, err := ParseGoalRace(args, today); !errors.Is(err, ErrRaceArgs) {
+			t.Errorf("args=%q: actual err=%v, expected=%v", args, err, ErrRaceArgs)
+		}
+	}
+}
+
+func TestBot_ProcessUpdate_RacesPin(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	senderMock := mock.NewMockSender(ctrl)
+	storageMock := mock.NewMockStorage(ctrl)
+	clockMock := mock.NewMockClock(ctrl)
+	bot := NewBot(senderMock, storageMock, nil, clockMock, &Config{}, NewTaskTemplates())
+	const userName = "alexandear"
+	const chatID = int64(20)
+	storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+	storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	clockMock.EXPECT().Now().Return(time.Date(2021, time.April, 1, 10, 0, 0, 0, time.UTC)).Times(1)
+	storageMock.EXPECT().UpdateGoalRace(gomock.Any(), userName, Race{
+		Date: time.Date(2021, time.April, 18, 0, 0, 0, 0, time.UTC),
+		Name: "City Marathon",
+	}).Return(nil).Times(1)
+	senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+		BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+		Text:     "Goal race: 18.04 City Marathon",
+	}).Times(1)
+
+	const command = "/races pin 2021-04-18 City Marathon"
+	if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: chatID},
+			From:     &tgbotapi.User{UserName: userName},
+			Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/races")}},
+			Text:     command,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBot_ProcessUpdate_RacesTimeZone(t *testing.T) {
+	bot := newTestBot(t, &Config{}, Settings{TimeZone: "Europe/Kyiv"})
+
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	// It is already race day in Kyiv.
+	raceDay := time.Date(2021, time.April, 18, 0, 0, 0, 0, time.UTC)
+	bot.clock.EXPECT().Now().Return(time.Date(2021, time.April, 17, 22, 30, 0, 0, time.UTC)).Times(1)
+	bot.storage.EXPECT().UserTokens(gomock.Any(), testUserName).Return([]UserToken{userToken}, nil).Times(1)
+	bot.fs.EXPECT().Workouts(gomock.Any(), userToken, raceDay, raceDay.AddDate(0, 0, 30)).Return(nil, nil).Times(1)
+	bot.fs.EXPECT().Workouts(gomock.Any(), userToken, gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	bot.storage.EXPECT().GoalRace(gomock.Any(), testUserName).Return(Race{Date: raceDay, Name: "City Marathon"}, nil).
+		Times(1)
+	bot.expectReply(`Upcoming races:
+18.04 City Marathon - Today ⭐
+
+/races pin 2021-04-18 City Marathon - pin the goal race
+/races unpin - unpin the goal race`)
+
+	bot.process(t, tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: testChatID},
+			From:     &tgbotapi.User{UserName: testUserName},
+			Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/races")}},
+			Text:     "/races",
+		},
+	})
+}
@@ -0,0 +1,477 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	// DefaultTaskDays are today and tomorrow.
+	DefaultTaskDays = 2
+
+	// settingsCallback prefixes callback data of the /settings keyboard:
+	// "settings" shows the menu, "settings:<field>" shows options, "settings:<field>:<value>" saves the option.
+	settingsCallback = "settings"
+	// settingsFormatField is the field of the task template, the longest option values.
+	settingsFormatField = "format"
+	// settingsDefault is the option that resets a setting.
+	settingsDefault = "default"
+	// settingsOff is the option that turns off the workout reminder.
+	settingsOff = "off"
+
+	// ReminderOff in Settings.RemindHour turns off the workout reminder.
+	ReminderOff = -1
+)
+
+// settingsReminderHours are offered for the workout reminder in the evening.
+var settingsReminderHours = []int{17, 18, 19, 20, 21, 22}
+
+// settingsTimeZones are offered in /settings, other zones are set with /timezone.
+var settingsTimeZones = []string{
+	"UTC", "Europe/London", "Europe/Berlin", "Europe/Kyiv", "America/New_York", "America/Chicago",
+	"America/Denver", "America/Los_Angeles", "Asia/Tokyo", "Australia/Sydney",
+}
+
+// migrateUserPreferencesQuery copies the key-value preferences used before /settings into user_settings.
+const migrateUserPreferencesQuery = `
+INSERT INTO user_settings(user_name, language, time_zone, markup, task_format)
+	SELECT user_name,
+		coalesce(max(CASE WHEN key='language' THEN value END), ''),
+		coalesce(max(CASE WHEN key='timezone' THEN value END), ''),
+		coalesce(max(CASE WHEN key='markup' THEN value END), ''),
+		coalesce(max(CASE WHEN key='format' THEN value END), '')
+	FROM user_preferences GROUP BY user_name
+	ON CONFLICT (user_name) DO NOTHING`
+
+// Settings are preferences of the user. Zero values mean defaults.
+type Settings struct {
+	// Language is a supported language or LanguageAuto.
+	Language string
+	// TimeZone is the IANA name such as "Europe/Kyiv".
+	TimeZone string
+	Markup   string
+	// TaskFormat is the template name of task messages.
+	TaskFormat string
+	// Units are UnitsMetric or UnitsImperial.
+	Units string
+	// TaskDays is the number of days from today shown by the task button.
+	TaskDays int
+	// NotifyHour is the local hour of the weekly summary, DIGEST_HOUR is used when it is nil.
+	NotifyHour *int
+	// RemindHour is the local hour of the reminder about a workout not logged yet, REMINDER_HOUR is used
+	// when it is nil. ReminderOff turns the reminder off.
+	RemindHour *int
+}
+
+// UserSettings returns settings of the user or the zero Settings when the user has not changed any.
+func UserSettings(ctx context.Context, db SettingsStore, userName string) (Settings, error) {
+	settings, err := db.Settings(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		return Settings{}, nil
+	}
+
+	if err != nil {
+		return Settings{}, fmt.Errorf("get settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+// Locale returns the chosen locale or the one detected from languageCode of the Telegram client.
+func (s Settings) Locale(languageCode string) *Locale {
+	if s.Language == "" || s.Language == LanguageAuto {
+		return LocaleFor(languageCode)
+	}
+
+	return LocaleFor(s.Language)
+}
+
+// Location returns the chosen time zone or fallback.
+func (s Settings) Location(fallback *time.Location) (*time.Location, error) {
+	if s.TimeZone == "" {
+		return fallback, nil
+	}
+
+	zone, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("load location %s: %w", s.TimeZone, err)
+	}
+
+	return zone, nil
+}
+
+// Days returns the number of days shown by the task button.
+func (s Settings) Days() int {
+	if s.TaskDays < 1 {
+		return DefaultTaskDays
+	}
+
+	return s.TaskDays
+}
+
+// DigestHour returns the chosen hour of the weekly summary or fallback.
+func (s Settings) DigestHour(fallback int) int {
+	if s.NotifyHour == nil {
+		return fallback
+	}
+
+	return *s.NotifyHour
+}
+
+// ReminderHour returns the chosen hour of the workout reminder or fallback and false when it is off.
+func (s Settings) ReminderHour(fallback int) (int, bool) {
+	switch {
+	case s.RemindHour == nil:
+		return fallback, true
+	case *s.RemindHour == ReminderOff:
+		return 0, false
+	default:
+		return *s.RemindHour, true
+	}
+}
+
+// updateSettings changes settings of the user with update and saves them.
+func (b *Bot) updateSettings(ctx context.Context, userName string, update func(settings *Settings)) error {
+	settings, err := UserSettings(ctx, b.db, userName)
+	if err != nil {
+		return err
+	}
+
+	update(&settings)
+
+	if err := b.db.UpdateSettings(ctx, userName, settings); err != nil {
+		return fmt.Errorf("update settings: %w", err)
+	}
+
+	return nil
+}
+
+// settingsField is a row of the /settings menu.
+type settingsField struct {
+	Name string
+	// Label is the locale key of the button and of the line in the menu.
+	Label string
+	// Hint is the locale key of the text shown under the options or "".
+	Hint    string
+	Columns int
+	Options func(loc *Locale) []settingsOption
+	Value   func(settings Settings) string
+	Set     func(settings *Settings, value string)
+}
+
+type settingsOption struct {
+	Value string
+	Text  string
+}
+
+func (b *Bot) settingsFields() []settingsField {
+	return []settingsField{
+		{
+			Name: "language", Label: msgSettingsLanguage, Columns: 2,
+			Options: func(loc *Locale) []settingsOption {
+				options := make([]settingsOption, 0, len(Languages)+1)
+				for _, language := range Languages {
+					options = append(options, settingsOption{Value: language, Text: LocaleFor(language).Name})
+				}
+
+				return append(options, settingsOption{Value: LanguageAuto, Text: loc.T(msgSettingsAuto)})
+			},
+			Value: func(s Settings) string {
+				if s.Language == "" {
+					return LanguageAuto
+				}
+
+				return s.Language
+			},
+			Set: func(s *Settings, value string) { s.Language = value },
+		},
+		{
+			Name: "timezone", Label: msgSettingsTimeZone, Hint: msgSettingsTimeZoneHint, Columns: 2,
+			Options: func(*Locale) []settingsOption {
+				return textOptions(settingsTimeZones...)
+			},
+			Value: func(s Settings) string {
+				if s.TimeZone == "" {
+					return b.clock.Now().Location().String()
+				}
+
+				return s.TimeZone
+			},
+			Set: func(s *Settings, value string) { s.TimeZone = value },
+		},
+		{
+			Name: "notify", Label: msgSettingsNotifyHour, Columns: 6,
+			Options: func(loc *Locale) []settingsOption {
+				options := make([]settingsOption, 0, 25)
+				for hour := 0; hour < 24; hour++ {
+					options = append(options, settingsOption{Value: strconv.Itoa(hour), Text: formatHour(hour)})
+				}
+
+				return append(options, settingsOption{
+					Value: settingsDefault,
+					Text:  loc.T(msgSettingsDefault, formatHour(b.config.DigestHour)),
+				})
+			},
+			Value: func(s Settings) string {
+				if s.NotifyHour == nil {
+					return settingsDefault
+				}
+
+				return strconv.Itoa(*s.NotifyHour)
+			},
+			Set: func(s *Settings, value string) {
+				s.NotifyHour = nil
+
+				if hour, err := strconv.Atoi(value); err == nil {
+					s.NotifyHour = &hour
+				}
+			},
+		},
+		{
+			Name: "reminder", Label: msgSettingsRemindHour, Columns: 3,
+			Options: func(loc *Locale) []settingsOption {
+				options := make([]settingsOption, 0, len(settingsReminderHours)+2)
+				for _, hour := range settingsReminderHours {
+					options = append(options, settingsOption{Value: strconv.Itoa(hour), Text: formatHour(hour)})
+				}
+
+				return append(options,
+					settingsOption{Value: settingsOff, Text: loc.T(msgSettingsOff)},
+					settingsOption{Value: settingsDefault, Text: loc.T(msgSettingsDefault, formatHour(b.config.ReminderHour))},
+				)
+			},
+			Value: func(s Settings) string {
+				switch {
+				case s.RemindHour == nil:
+					return settingsDefault
+				case *s.RemindHour == ReminderOff:
+					return settingsOff
+				default:
+					return strconv.Itoa(*s.RemindHour)
+				}
+			},
+			Set: func(s *Settings, value string) {
+				s.RemindHour = nil
+
+				if value == settingsOff {
+					off := ReminderOff
+					s.RemindHour = &off
+				} else if hour, err := strconv.Atoi(value); err == nil {
+					s.RemindHour = &hour
+				}
+			},
+		},
+		{
+			Name: settingsFormatField, Label: msgSettingsFormat, Columns: 3,
+			Options: func(*Locale) []settingsOption {
+				return textOptions(b.templates.Names()...)
+			},
+			Value: b.templates.Format,
+			Set:   func(s *Settings, value string) { s.TaskFormat = value },
+		},
+		{
+			Name: "markup", Label: msgSettingsMarkup, Columns: 2,
+			Options: func(*Locale) []settingsOption {
+				return textOptions(MarkupHTML, MarkupPlain)
+			},
+			Value: func(s Settings) string {
+				if s.Markup == MarkupPlain {
+					return MarkupPlain
+				}
+
+				return MarkupHTML
+			},
+			Set: func(s *Settings, value string) { s.Markup = value },
+		},
+		{
+			Name: "units", Label: msgSettingsUnits, Columns: 2,
+			Options: func(*Locale) []settingsOption {
+				return textOptions(UnitsMetric, UnitsImperial)
+			},
+			Value: func(s Settings) string {
+				if s.Units == UnitsImperial {
+					return UnitsImperial
+				}
+
+				return UnitsMetric
+			},
+			Set: func(s *Settings, value string) { s.Units = value },
+		},
+		{
+			Name: "days", Label: msgSettingsDays, Columns: 4,
+			Options: func(*Locale) []settingsOption {
+				return textOptions("1", "2", "3", "7")
+			},
+			Value: func(s Settings) string { return strconv.Itoa(s.Days()) },
+			Set: func(s *Settings, value string) {
+				s.TaskDays, _ = strconv.Atoi(value)
+			},
+		},
+	}
+}
+
+func textOptions(values ...string) []settingsOption {
+	options := make([]settingsOption, 0, len(values))
+	for _, v := range values {
+		options = append(options, settingsOption{Value: v, Text: v})
+	}
+
+	return options
+}
+
+func formatHour(hour int) string {
+	return fmt.Sprintf("%02d:00", hour)
+}
+
+func (b *Bot) commandSettings(ctx context.Context, userName string, chatID int64, loc *Locale,
+) (tgbotapi.Chattable, error) {
+	settings, err := UserSettings(ctx, b.db, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	text, keyboard := b.settingsMenu(settings, loc)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ReplyMarkup = keyboard
+
+	return msg, nil
+}
+
+// settingsMenu lists current settings with a button for every field.
+func (b *Bot) settingsMenu(settings Settings, loc *Locale) (string, tgbotapi.InlineKeyboardMarkup) {
+	text := strings.Builder{}
+	text.WriteString(loc.T(msgSettings))
+
+	var buttons []tgbotapi.InlineKeyboardButton
+
+	for _, f := range b.settingsFields() {
+		value := f.Value(settings)
+
+		for _, o := range f.Options(loc) {
+			if o.Value == value {
+				value = o.Text
+			}
+		}
+
+		text.WriteString("\n" + loc.T(f.Label) + ": " + value)
+
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(loc.T(f.Label), settingsCallback+":"+f.Name))
+	}
+
+	return text.String(), inlineKeyboard(buttons, 2)
+}
+
+// settingsOptions shows options of the field. The current one is checked.
+func (b *Bot) settingsOptions(field settingsField, settings Settings, loc *Locale,
+) (string, tgbotapi.InlineKeyboardMarkup) {
+	text := loc.T(msgSettingsChoose, loc.T(field.Label))
+	if field.Hint != "" {
+		text += "\n" + loc.T(field.Hint)
+	}
+
+	current := field.Value(settings)
+
+	var buttons []tgbotapi.InlineKeyboardButton
+
+	for _, o := range field.Options(loc) {
+		label := o.Text
+		if o.Value == current {
+			label = "✓ " + label
+		}
+
+		buttons = append(buttons, tgbotapi.NewInlineKeyboardButtonData(label,
+			settingsCallback+":"+field.Name+":"+o.Value))
+	}
+
+	keyboard := inlineKeyboard(buttons, field.Columns)
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(loc.T(msgSettingsBack), settingsCallback)))
+
+	return text, keyboard
+}
+
+func inlineKeyboard(buttons []tgbotapi.InlineKeyboardButton, columns int) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	for len(buttons) > columns {
+		rows = append(rows, buttons[:columns])
+		buttons = buttons[columns:]
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(append(rows, buttons)...)
+}
+
+// settingsCallbackQuery edits the /settings message for a pressed button.
+// It returns the text of the notification shown to the user, "" when nothing was saved.
+func (b *Bot) settingsCallbackQuery(ctx context.Context, query *tgbotapi.CallbackQuery, loc *Locale,
+) (tgbotapi.Chattable, string, error) {
+	userName := query.From.UserName
+
+	settings, err := UserSettings(ctx, b.db, userName)
+	if err != nil {
+		return nil, "", err
+	}
+
+	parts := strings.SplitN(query.Data, ":", 3)
+
+	var field *settingsField
+
+	if len(parts) > 1 {
+		for _, f := range b.settingsFields() {
+			if f.Name == parts[1] {
+				f := f
+				field = &f
+			}
+		}
+	}
+
+	var (
+		text     string
+		keyboard tgbotapi.InlineKeyboardMarkup
+		notice   string
+	)
+
+	switch {
+	case field == nil:
+		text, keyboard = b.settingsMenu(settings, loc)
+	case len(parts) == 2:
+		text, keyboard = b.settingsOptions(*field, settings, loc)
+	case !hasOption(field.Options(loc), parts[2]):
+		// The menu was sent before the option was removed, for example a deleted template.
+		text, keyboard = b.settingsOptions(*field, settings, loc)
+		notice = loc.T(msgSettingsOutdated)
+	default:
+		field.Set(&settings, parts[2])
+
+		if err := b.db.UpdateSettings(ctx, userName, settings); err != nil {
+			return nil, "", fmt.Errorf("update settings: %w", err)
+		}
+
+		// The menu is shown in the new language right away.
+		loc = settings.Locale(query.From.LanguageCode)
+		text, keyboard = b.settingsMenu(settings, loc)
+		notice = loc.T(msgSettingsSaved)
+	}
+
+	edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+	edit.ReplyMarkup = &keyboard
+
+	return edit, notice, nil
+}
+
+func hasOption(options []settingsOption, value string) bool {
+	for _, o := range options {
+		if o.Value == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,181 @@
+package bot_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestBot_ProcessUpdate_Settings(t *testing.T) {
+	const messageID = 30
+
+	callback := func(data string) tgbotapi.Update {
+		return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+			ID:      "42",
+			From:    &tgbotapi.User{UserName: testUserName},
+			Message: &tgbotapi.Message{MessageID: messageID, Chat: &tgbotapi.Chat{ID: testChatID}},
+			Data:    data,
+		}}
+	}
+
+	newBot := func(t *testing.T, settings Settings) *testBot {
+		bot := newTestBot(t, &Config{DigestHour: 19, ReminderHour: 20}, settings)
+		bot.clock.EXPECT().Now().Return(time.Date(2020, time.December, 20, 15, 0, 0, 0, time.UTC)).AnyTimes()
+
+		return bot
+	}
+
+	t.Run("menu", func(t *testing.T) {
+		bot := newBot(t, Settings{TaskFormat: TaskFormatCompact, TaskDays: 3})
+		bot.sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg tgbotapi.Chattable) (tgbotapi.Message, error) {
+			m := msg.(tgbotapi.MessageConfig)
+
+			const text = `Settings:
+Language: Telegram language
+Time zone: UTC
+Weekly summary time: default (19:00)
+Workout reminder: default (20:00)
+Message format: compact
+Markup: html
+Units: km
+Days to show: 3`
+			if m.Text != text {
+				t.Errorf("actual=%q, expected=%q", m.Text, text)
+			}
+
+			keyboard := m.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup)
+			if len(keyboard.InlineKeyboard) != 4 {
+				t.Fatalf("actual rows=%d, expected=4", len(keyboard.InlineKeyboard))
+			}
+
+			if data := *keyboard.InlineKeyboard[3][1].CallbackData; data != "settings:days" {
+				t.Errorf("actual=%s, expected=settings:days", data)
+			}
+
+			return tgbotapi.Message{}, nil
+		}).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+			Message: &tgbotapi.Message{
+				Chat:     &tgbotapi.Chat{ID: testChatID},
+				From:     &tgbotapi.User{UserName: testUserName},
+				Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/settings")}},
+				Text:     "/settings",
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("options", func(t *testing.T) {
+		bot := newBot(t, Settings{Units: UnitsImperial})
+		bot.sender.EXPECT().AnswerCallbackQuery(tgbotapi.NewCallback("42", "")).
+			Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1)
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(
+			tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData("km", "settings:units:km"),
+				tgbotapi.NewInlineKeyboardButtonData("✓ mi", "settings:units:mi"),
+			),
+			tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("« Back", "settings")),
+		)
+		edit := tgbotapi.NewEditMessageText(testChatID, messageID, "Choose: Units")
+		edit.ReplyMarkup = &keyboard
+		bot.sender.EXPECT().Send(edit).Return(tgbotapi.Message{}, nil).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), callback("settings:units")); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("save", func(t *testing.T) {
+		bot := newBot(t, Settings{Units: UnitsImperial})
+		hour := 7
+		bot.storage.EXPECT().UpdateSettings(gomock.Any(), testUserName,
+			Settings{Units: UnitsImperial, NotifyHour: &hour}).Return(nil).Times(1)
+		bot.sender.EXPECT().AnswerCallbackQuery(tgbotapi.NewCallback("42", "Saved")).
+			Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1)
+		bot.sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg tgbotapi.Chattable) (tgbotapi.Message, error) {
+			if m := msg.(tgbotapi.EditMessageTextConfig); m.MessageID != messageID {
+				t.Errorf("actual=%d, expected=%d", m.MessageID, messageID)
+			}
+
+			return tgbotapi.Message{}, nil
+		}).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), callback("settings:notify:7")); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("language is applied to the menu", func(t *testing.T) {
+		bot := newBot(t, Settings{})
+		bot.storage.EXPECT().UpdateSettings(gomock.Any(), testUserName, Settings{Language: LanguageGerman}).
+			Return(nil).Times(1)
+		bot.sender.EXPECT().AnswerCallbackQuery(tgbotapi.NewCallback("42", "Gespeichert")).
+			Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1)
+		bot.sender.EXPECT().Send(gomock.Any()).Return(tgbotapi.Message{}, nil).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), callback("settings:language:de")); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("outdated option", func(t *testing.T) {
+		bot := newBot(t, Settings{})
+		bot.sender.EXPECT().AnswerCallbackQuery(tgbotapi.NewCallback("42", "This option is no longer available")).
+			Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1)
+		bot.sender.EXPECT().Send(gomock.Any()).Return(tgbotapi.Message{}, nil).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), callback("settings:format:removed")); err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("callback data of the longest template name", func(t *testing.T) {
+		dir := t.TempDir()
+		name := strings.Repeat("n", 48)
+		if err := os.WriteFile(filepath.Join(dir, name+".tmpl"), []byte("{{len .Days}}"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		templates := NewTaskTemplates()
+		if err := templates.LoadDir(dir); err != nil {
+			t.Fatal(err)
+		}
+
+		bot := newBot(t, Settings{})
+		bot.Bot = NewBot(bot.sender, bot.storage, bot.fs, bot.clock, &Config{}, templates)
+		bot.sender.EXPECT().AnswerCallbackQuery(tgbotapi.NewCallback("42", "")).
+			Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1)
+		bot.sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg tgbotapi.Chattable) (tgbotapi.Message, error) {
+			found := false
+
+			for _, row := range msg.(tgbotapi.EditMessageTextConfig).ReplyMarkup.InlineKeyboard {
+				for _, button := range row {
+					// Telegram rejects callback data longer than 64 bytes.
+					if data := *button.CallbackData; len(data) > 64 {
+						t.Errorf("actual=%s, expected up to 64 bytes", data)
+					}
+
+					found = found || button.Text == name
+				}
+			}
+
+			if !found {
+				t.Errorf("expected the button of %s", name)
+			}
+
+			return tgbotapi.Message{}, nil
+		}).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), callback("settings:format")); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
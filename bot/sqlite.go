@@ -0,0 +1,651 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3" // registers sqlite3 driver
+)
+
+const sqliteDateLayout = "2006-01-02"
+
+// SQLite is a Storage embedded into the bot process for single-instance self-hosting.
+type SQLite struct {
+	db *sql.DB
+}
+
+func NewSQLite(db *sql.DB) *SQLite {
+	return &SQLite{db: db}
+}
+
+// OpenSQLite opens the database file, creating it when it does not exist.
+func OpenSQLite(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+
+	// SQLite allows only one writer at a time.
+	db.SetMaxOpenConns(1)
+
+	return db, nil
+}
+
+func (s *SQLite) Init(ctx context.Context) error {
+	for _, query := range []string{
+		`
+CREATE TABLE IF NOT EXISTS accounts (
+    user_name text not null,
+    label text not null,
+    user_key text not null,
+    token text not null,
+    active boolean not null,
+    primary key (user_name, label)
+);`,
+		`
+CREATE TABLE IF NOT EXISTS subscriptions (
+    user_name text primary key,
+    chat_id integer not null,
+    snapshot_at timestamp,
+    snapshot_end text
+);`,
+		`
+CREATE TABLE IF NOT EXISTS workout_snapshots (
+    user_name text not null,
+    position integer not null,
+    workout_date text not null,
+    description text not null,
+    primary key (user_name, position)
+);`,
+		`
+CREATE TABLE IF NOT EXISTS calendar_tokens (
+    user_name text primary key,
+    token text not null unique
+);`,
+		`
+CREATE TABLE IF NOT EXISTS user_settings (
+    user_name text primary key,
+    language text not null default '',
+    time_zone text not null default '',
+    markup text not null default '',
+    task_format text not null default '',
+    units text not null default '',
+    task_days integer not null default 0,
+    notify_hour integer,
+    remind_hour integer
+);`,
+		`
+CREATE TABLE IF NOT EXISTS goal_races (
+    user_name text primary key,
+    race_date text not null,
+    name text not null
+);`,
+		`
+CREATE TABLE IF NOT EXISTS user_activity (
+    user_name text primary key,
+    user_id integer not null,
+    chat_id integer not null,
+    last_active timestamp not null
+);`,
+	} {
+		if _, err := s.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("create table: %w", err)
+		}
+	}
+
+	// Tables created before the workout reminder have no remind_hour.
+	if err := s.addColumn(ctx, "user_settings", "remind_hour", "integer"); err != nil {
+		return fmt.Errorf("add column user_settings.remind_hour: %w", err)
+	}
+
+	// Tables created before the snapshot window was stored have no snapshot_end.
+	if err := s.addColumn(ctx, "subscriptions", "snapshot_end", "text"); err != nil {
+		return fmt.Errorf("add column subscriptions.snapshot_end: %w", err)
+	}
+
+	if err := s.migrateUserPreferences(ctx); err != nil {
+		return fmt.Errorf("migrate user_preferences: %w", err)
+	}
+
+	return nil
+}
+
+// addColumn adds the column unless the table has it, since SQLite has no ADD COLUMN IF NOT EXISTS.
+func (s *SQLite) addColumn(ctx context.Context, table, column, columnType string) error {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT count(*) > 0 FROM pragma_table_info(?) WHERE name=?`,
+		table, column).Scan(&exists); err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	if exists {
+		return nil
+	}
+
+	if _, err := s.db.ExecContext(ctx, "ALTER TABLE "+table+" ADD COLUMN "+column+" "+columnType); err != nil {
+		return fmt.Errorf("alter table: %w", err)
+	}
+
+	return nil
+}
+
+// migrateUserPreferences moves preferences from the key-value table used before /settings.
+func (s *SQLite) migrateUserPreferences(ctx context.Context) error {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `
+SELECT count(*) > 0 FROM sqlite_master WHERE type='table' AND name='user_preferences'`).Scan(&exists); err != nil {
+		return fmt.Errorf("query: %w", err)
+	}
+
+	if !exists {
+		return nil
+	}
+
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, migrateUserPreferencesQuery); err != nil {
+			return fmt.Errorf("insert: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DROP TABLE user_preferences`); err != nil {
+			return fmt.Errorf("drop: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLite) UserToken(ctx context.Context, userName string) (UserToken, error) {
+	userTokens, err := s.UserTokens(ctx, userName)
+	if err != nil {
+		return UserToken{}, err
+	}
+
+	return userTokens[0], nil
+}
+
+func (s *SQLite) UserTokens(ctx context.Context, userName string) ([]UserToken, error) {
+	accounts, err := s.Accounts(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	var userTokens []UserToken
+
+	for _, a := range accounts {
+		if a.Active {
+			userTokens = append(userTokens, a.UserToken)
+		}
+	}
+
+	if len(userTokens) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return userTokens, nil
+}
+
+func (s *SQLite) UpdateUserToken(ctx context.Context, userName, label string, userToken UserToken) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `UPDATE accounts SET active=false WHERE user_name=?`, userName); err != nil {
+			return fmt.Errorf("deactivate: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO accounts(user_name, label, user_key, token, active) VALUES (?, ?, ?, ?, true)
+	ON CONFLICT (user_name, label) DO UPDATE SET user_key=excluded.user_key, token=excluded.token, active=true`,
+			userName, label, userToken.UserKey, userToken.Token); err != nil {
+			return fmt.Errorf("update: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLite) Accounts(ctx context.Context, userName string) ([]Account, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT label, user_key, token, active FROM accounts WHERE user_name=? ORDER BY rowid`, userName)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	var accounts []Account
+
+	for rows.Next() {
+		var a Account
+		if errScan := rows.Scan(&a.Label, &a.UserToken.UserKey, &a.UserToken.Token, &a.Active); errScan != nil {
+			return nil, fmt.Errorf("failed during scan: %w", errScan)
+		}
+
+		accounts = append(accounts, a)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed rows: %w", rows.Err())
+	}
+
+	return accounts, nil
+}
+
+func (s *SQLite) ActivateAccount(ctx context.Context, userName, label string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `UPDATE accounts SET active=true WHERE user_name=? AND label=?`,
+			userName, label)
+		if err != nil {
+			return fmt.Errorf("activate: %w", err)
+		}
+
+		if err := checkRowsAffected(res); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE accounts SET active=false WHERE user_name=? AND label<>?`,
+			userName, label); err != nil {
+			return fmt.Errorf("deactivate: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLite) ActivateAllAccounts(ctx context.Context, userName string) error {
+	if _, err := s.db.ExecContext(ctx, `UPDATE accounts SET active=true WHERE user_name=?`, userName); err != nil {
+		return fmt.Errorf("activate: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) DeleteAccount(ctx context.Context, userName, label string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM accounts WHERE user_name=? AND label=?`, userName, label)
+		if err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		if err := checkRowsAffected(res); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+UPDATE accounts SET active=true WHERE user_name=?1 AND NOT EXISTS (
+    SELECT 1 FROM accounts WHERE user_name=?1 AND active
+) AND rowid=(
+    SELECT min(rowid) FROM accounts WHERE user_name=?1
+)`, userName); err != nil {
+			return fmt.Errorf("activate remaining: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLite) DeleteAccounts(ctx context.Context, userName string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM accounts WHERE user_name=?`, userName); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) Subscriptions(ctx context.Context) ([]Subscription, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT user_name, chat_id FROM subscriptions`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	var subscriptions []Subscription
+
+	for rows.Next() {
+		var sub Subscription
+		if errScan := rows.Scan(&sub.UserName, &sub.ChatID); errScan != nil {
+			return nil, fmt.Errorf("failed during scan: %w", errScan)
+		}
+
+		subscriptions = append(subscriptions, sub)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed rows: %w", rows.Err())
+	}
+
+	return subscriptions, nil
+}
+
+func (s *SQLite) Subscribe(ctx context.Context, subscription Subscription) error {
+	if _, err := s.db.ExecContext(ctx, `
+INSERT INTO subscriptions(user_name, chat_id) VALUES (?, ?) ON CONFLICT (user_name)
+	DO UPDATE SET chat_id=excluded.chat_id`,
+		subscription.UserName, subscription.ChatID); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) Unsubscribe(ctx context.Context, userName string) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM subscriptions WHERE user_name=?`, userName); err != nil {
+			return fmt.Errorf("delete subscription: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM workout_snapshots WHERE user_name=?`, userName); err != nil {
+			return fmt.Errorf("delete workout snapshot: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLite) WorkoutSnapshot(ctx context.Context, userName string) (WorkoutSnapshot, error) {
+	var (
+		snapshotAt  sql.NullTime
+		snapshotEnd sql.NullString
+	)
+
+	err := s.db.QueryRowContext(ctx, `SELECT snapshot_at, snapshot_end FROM subscriptions WHERE user_name=?`,
+		userName).Scan(&snapshotAt, &snapshotEnd)
+	if errors.Is(err, sql.ErrNoRows) {
+		return WorkoutSnapshot{}, ErrNotFound
+	}
+
+	if err != nil {
+		return WorkoutSnapshot{}, fmt.Errorf("query snapshot time: %w", err)
+	}
+
+	if !snapshotAt.Valid {
+		return WorkoutSnapshot{}, ErrNotFound
+	}
+
+	var snapshot WorkoutSnapshot
+	if snapshotEnd.Valid {
+		if snapshot.End, err = time.Parse(sqliteDateLayout, snapshotEnd.String); err != nil {
+			return WorkoutSnapshot{}, fmt.Errorf("parse snapshot end %s: %w", snapshotEnd.String, err)
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+SELECT workout_date, description FROM workout_snapshots WHERE user_name=? ORDER BY position`, userName)
+	if err != nil {
+		return WorkoutSnapshot{}, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			w    Workout
+			date string
+		)
+
+		if errScan := rows.Scan(&date, &w.Description); errScan != nil {
+			return WorkoutSnapshot{}, fmt.Errorf("failed during scan: %w", errScan)
+		}
+
+		if w.Date, err = time.Parse(sqliteDateLayout, date); err != nil {
+			return WorkoutSnapshot{}, fmt.Errorf("parse date %s: %w", date, err)
+		}
+
+		snapshot.Workouts = append(snapshot.Workouts, w)
+	}
+
+	if rows.Err() != nil {
+		return WorkoutSnapshot{}, fmt.Errorf("failed rows: %w", rows.Err())
+	}
+
+	return snapshot, nil
+}
+
+func (s *SQLite) UpdateWorkoutSnapshot(ctx context.Context, userName string, snapshot WorkoutSnapshot) error {
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM workout_snapshots WHERE user_name=?`, userName); err != nil {
+			return fmt.Errorf("delete: %w", err)
+		}
+
+		for i, w := range snapshot.Workouts {
+			if _, err := tx.ExecContext(ctx, `
+INSERT INTO workout_snapshots(user_name, position, workout_date, description) VALUES (?, ?, ?, ?)`,
+				userName, i, w.Date.Format(sqliteDateLayout), w.Description); err != nil {
+				return fmt.Errorf("insert: %w", err)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+UPDATE subscriptions SET snapshot_at=CURRENT_TIMESTAMP, snapshot_end=? WHERE user_name=?`,
+			snapshot.End.Format(sqliteDateLayout), userName); err != nil {
+			return fmt.Errorf("update snapshot time: %w", err)
+		}
+
+		return nil
+	})
+}
+
+func (s *SQLite) CalendarToken(ctx context.Context, userName string) (string, error) {
+	var token string
+
+	err := s.db.QueryRowContext(ctx, `SELECT token FROM calendar_tokens WHERE user_name=?`, userName).Scan(&token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("query: %w", err)
+	}
+
+	return token, nil
+}
+
+func (s *SQLite) UpdateCalendarToken(ctx context.Context, userName, token string) error {
+	if _, err := s.db.ExecContext(ctx, `
+INSERT INTO calendar_tokens(user_name, token) VALUES (?, ?) ON CONFLICT (user_name)
+	DO UPDATE SET token=excluded.token`,
+		userName, token); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) CalendarUserName(ctx context.Context, token string) (string, error) {
+	var userName string
+
+	err := s.db.QueryRowContext(ctx, `SELECT user_name FROM calendar_tokens WHERE token=?`, token).Scan(&userName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("query: %w", err)
+	}
+
+	return userName, nil
+}
+
+func (s *SQLite) Settings(ctx context.Context, userName string) (Settings, error) {
+	var settings Settings
+
+	err := s.db.QueryRowContext(ctx, `
+SELECT language, time_zone, markup, task_format, units, task_days, notify_hour, remind_hour
+	FROM user_settings
+	WHERE user_name=?`, userName).Scan(&settings.Language, &settings.TimeZone, &settings.Markup,
+		&settings.TaskFormat, &settings.Units, &settings.TaskDays, &settings.NotifyHour,
+		&settings.RemindHour)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Settings{}, ErrNotFound
+	}
+
+	if err != nil {
+		return Settings{}, fmt.Errorf("query: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (s *SQLite) UpdateSettings(ctx context.Context, userName string, settings Settings) error {
+	if _, err := s.db.ExecContext(ctx, `
+INSERT INTO user_settings(user_name, language, time_zone, markup, task_format, units, task_days, notify_hour,
+	remind_hour)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (user_name) DO UPDATE SET language=excluded.language, time_zone=excluded.time_zone,
+		markup=excluded.markup, task_format=excluded.task_format, units=excluded.units,
+		task_days=excluded.task_days, notify_hour=excluded.notify_hour, remind_hour=excluded.remind_hour`,
+		userName, settings.Language, settings.TimeZone, settings.Markup, settings.TaskFormat, settings.Units,
+		settings.TaskDays, settings.NotifyHour, settings.RemindHour); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) GoalRace(ctx context.Context, userName string) (Race, error) {
+	var (
+		race Race
+		date string
+	)
+
+	err := s.db.QueryRowContext(ctx, `SELECT race_date, name FROM goal_races WHERE user_name=?`,
+		userName).Scan(&date, &race.Name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Race{}, ErrNotFound
+	}
+
+	if err != nil {
+		return Race{}, fmt.Errorf("query: %w", err)
+	}
+
+	if race.Date, err = time.Parse(sqliteDateLayout, date); err != nil {
+		return Race{}, fmt.Errorf("parse date %s: %w", date, err)
+	}
+
+	return race, nil
+}
+
+func (s *SQLite) UpdateGoalRace(ctx context.Context, userName string, race Race) error {
+	if _, err := s.db.ExecContext(ctx, `
+INSERT INTO goal_races(user_name, race_date, name) VALUES (?, ?, ?) ON CONFLICT (user_name)
+	DO UPDATE SET race_date=excluded.race_date, name=excluded.name`,
+		userName, race.Date.Format(sqliteDateLayout), race.Name); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) DeleteGoalRace(ctx context.Context, userName string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM goal_races WHERE user_name=?`, userName); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) UpdateUserActivity(ctx context.Context, activity UserActivity) error {
+	if _, err := s.db.ExecContext(ctx, `
+INSERT INTO user_activity(user_name, user_id, chat_id, last_active) VALUES (?, ?, ?, ?) ON CONFLICT (user_name)
+	DO UPDATE SET user_id=excluded.user_id, chat_id=excluded.chat_id, last_active=excluded.last_active`,
+		activity.UserName, activity.UserID, activity.ChatID, activity.LastActive.UTC()); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) UserActivities(ctx context.Context) ([]UserActivity, error) {
+	rows, err := s.db.QueryContext(ctx, `
+SELECT user_name, user_id, chat_id, last_active FROM user_activity ORDER BY user_name`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer rows.Close()
+
+	var activities []UserActivity
+
+	for rows.Next() {
+		var a UserActivity
+		if errScan := rows.Scan(&a.UserName, &a.UserID, &a.ChatID, &a.LastActive); errScan != nil {
+			return nil, fmt.Errorf("failed during scan: %w", errScan)
+		}
+
+		activities = append(activities, a)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("failed rows: %w", rows.Err())
+	}
+
+	return activities, nil
+}
+
+func (s *SQLite) AuthorizedUsers(ctx context.Context) (int, error) {
+	var count int
+	if err := s.db.QueryRowContext(ctx, `SELECT count(DISTINCT user_name) FROM accounts`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("query: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *SQLite) DeleteUser(ctx context.Context, userName string) ([]DeletedRows, error) {
+	deleted := make([]DeletedRows, 0, len(userTables))
+
+	if err := s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, table := range userTables {
+			res, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE user_name=?`, userName)
+			if err != nil {
+				return fmt.Errorf("delete from %s: %w", table, err)
+			}
+
+			n, err := res.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("rows affected: %w", err)
+			}
+
+			deleted = append(deleted, DeletedRows{Table: table, Rows: n})
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+func (s *SQLite) inTx(ctx context.Context, f func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer tx.Rollback() //nolint:errcheck // no-op after commit
+
+	if err := f(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+func checkRowsAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if n == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package bot_test
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/storagetest"
+)
+
+func TestSQLite(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) Storage {
+		db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() {
+			if err := db.Close(); err != nil {
+				t.Error(err)
+			}
+		})
+
+		sqlite := NewSQLite(db)
+		if err := sqlite.Init(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+
+		return sqlite
+	})
+}
+
+func TestSQLite_Init_migrateUserPreferences(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE user_preferences (user_name text not null, key text not null, value text not null,
+    primary key (user_name, key));
+INSERT INTO user_preferences VALUES ('alexandear', 'language', 'de'), ('alexandear', 'timezone', 'Europe/Kyiv'),
+    ('alexandear', 'format', 'compact'), ('other', 'markup', 'plain');`); err != nil {
+		t.Fatal(err)
+	}
+
+	sqlite := NewSQLite(db)
+	if err := sqlite.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for userName, expected := range map[string]Settings{
+		"alexandear": {Language: LanguageGerman, TimeZone: "Europe/Kyiv", TaskFormat: TaskFormatCompact},
+		"other":      {Markup: MarkupPlain},
+	} {
+		actual, err := sqlite.Settings(ctx, userName)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("actual=%+v, expected=%+v", actual, expected)
+		}
+	}
+
+	// Init is run on every start.
+	if err := sqlite.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQLite_Init_addRemindHour(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE user_settings (user_name text primary key, language text not null default '',
+    time_zone text not null default '', markup text not null default '', task_format text not null default '',
+    units text not null default '', task_days integer not null default 0, notify_hour integer);
+INSERT INTO user_settings(user_name, units) VALUES ('alexandear', 'mi');`); err != nil {
+		t.Fatal(err)
+	}
+
+	sqlite := NewSQLite(db)
+	if err := sqlite.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := sqlite.Settings(ctx, "alexandear")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := (Settings{Units: UnitsImperial}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("actual=%+v, expected=%+v", actual, expected)
+	}
+}
+
+func TestSQLite_Init_addSnapshotEnd(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	if _, err := db.ExecContext(ctx, `
+CREATE TABLE subscriptions (user_name text primary key, chat_id integer not null, snapshot_at timestamp);
+CREATE TABLE workout_snapshots (user_name text not null, position integer not null, workout_date text not null,
+    description text not null, primary key (user_name, position));
+INSERT INTO subscriptions(user_name, chat_id, snapshot_at) VALUES ('alexandear', 20, CURRENT_TIMESTAMP);
+INSERT INTO workout_snapshots VALUES ('alexandear', 0, '2020-12-20', '10 km');`); err != nil {
+		t.Fatal(err)
+	}
+
+	sqlite := NewSQLite(db)
+	if err := sqlite.Init(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := sqlite.WorkoutSnapshot(ctx, "alexandear")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := WorkoutSnapshot{Workouts: []Workout{
+		{Date: time.Date(2020, time.December, 20, 0, 0, 0, 0, time.UTC), Description: "10 km"},
+	}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("actual=%+v, expected=%+v", actual, expected)
+	}
+}
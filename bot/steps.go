@@ -0,0 +1,463 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	StepWarmup   = "warmup"
+	StepSteady   = "steady"
+	StepRepeat   = "repeat"
+	StepInterval = "interval"
+	StepRecovery = "recovery"
+	StepCooldown = "cooldown"
+
+	// defaultStepPace estimates steps without a pace when the workout has no planned distance and duration.
+	defaultStepPace = 6 * time.Minute
+	// minBareMeters is the least number without a unit taken as meters in repeats, as in "6x800 w/ 400 jog".
+	minBareMeters = 100
+)
+
+var (
+	// stepSplitRe separates steps: "WU 2km, 6x800m; CD 2km" or "2km + 5km then 1km".
+	// A comma must be followed by a space, so "1,500 m" is one number.
+	stepSplitRe = regexp.MustCompile(`(?i)\s*(?:,\s+|;|\n|\+|\bthen\b)\s*`)
+	// stepRepeatRe matches "6x800m @ 3:10 w/ 400 jog" or "Swim 10 x 100m".
+	stepRepeatRe    = regexp.MustCompile(`(?i)(?:^|\s)(\d+)\s*[x×]\s*(.+)$`)
+	stepRecoverySep = regexp.MustCompile(`(?i)\s+(?:w/|with)\s*`)
+	stepWarmupRe    = regexp.MustCompile(`(?i)(?:^|\s)(?:wu|w/u|warm[\s-]?up)\b`)
+	stepCooldownRe  = regexp.MustCompile(`(?i)(?:^|\s)(?:cd|c/d|cool[\s-]?down)\b`)
+	stepRecoveryRe  = regexp.MustCompile(`(?i)\b(?:jog|rest|recovery|rec|walk|float)\b`)
+	stepRestRe      = regexp.MustCompile(`(?i)\brest\b`)
+	// stepPaceRe matches "@ 3:10", "@3:10/km" or "@ 5:06 min/mi". A time without a unit is the time of a rep
+	// with a distance in repeats, as "6x800m @ 3:10", and the pace per kilometer otherwise.
+	stepPaceRe = regexp.MustCompile(`(?i)@\s*(\d{1,2}:[0-5]\d)(?:\s*(?:min)?\s*/\s*(km|mi|mile)\b)?`)
+	// stepDurationRe matches "10 min", "45'" or "20''". Double primes come first, so they are seconds.
+	stepDurationRe = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*` +
+		`(?:(hours?|hrs?|h|minutes|mins?|seconds|secs?|s)\b|(''|"|'))`)
+	// stepClockHoursRe matches "1:30:00" before stepClockRe takes its start for minutes and seconds.
+	stepClockHoursRe = regexp.MustCompile(`\b(\d{1,2}):([0-5]\d):([0-5]\d)\b`)
+	stepClockRe      = regexp.MustCompile(`\b(\d{1,2}):([0-5]\d)\b`)
+	stepBareRe       = regexp.MustCompile(`\b(\d{3,5})\b`)
+)
+
+// WorkoutStep is a part of a structured workout. Distance and Duration are estimated from the pace
+// when only one of them is given.
+type WorkoutStep struct {
+	Kind string
+	// Text is the part of the description the step is parsed from.
+	Text string
+	// Distance is in meters.
+	Distance float64
+	Duration time.Duration
+	// Pace is the time per kilometer or 0 when the description gives none.
+	Pace time.Duration
+	// ByTime is true when the step ends after the duration rather than the distance.
+	ByTime bool
+	// Steps are done Repeat times for StepRepeat.
+	Repeat int
+	Steps  []WorkoutStep
+}
+
+// Total returns the distance and the duration of the step with all repeats.
+func (s WorkoutStep) Total() (float64, time.Duration) {
+	if s.Kind != StepRepeat {
+		return s.Distance, s.Duration
+	}
+
+	var (
+		distance float64
+		duration time.Duration
+	)
+
+	for _, step := range s.Steps {
+		d, t := step.Total()
+		distance += d
+		duration += t
+	}
+
+	return distance * float64(s.Repeat), duration * time.Duration(s.Repeat)
+}
+
+// WorkoutSteps is the structured workout with the total distance in meters and the total duration.
+type WorkoutSteps struct {
+	Steps    []WorkoutStep
+	Distance float64
+	Duration time.Duration
+}
+
+// ParseWorkoutSteps parses the description such as "WU 2km, 6x800m @ 3:10 w/ 400 jog, CD 2km" into steps.
+// Parts without a distance or a duration are skipped. A workout without such parts is one steady step
+// of the planned volume from FinalSurge.
+func ParseWorkoutSteps(workout Workout) WorkoutSteps {
+	var steps []WorkoutStep
+
+	swim := strings.Contains(strings.ToLower(workout.Activity), "swim")
+
+	for _, part := range stepSplitRe.Split(strings.TrimSpace(workout.Description), -1) {
+		if step, ok := parseStep(part, swim); ok {
+			steps = append(steps, step)
+		}
+	}
+
+	if len(steps) == 0 && !workout.RestDay && (workout.Planned.Distance > 0 || workout.Planned.Duration > 0) {
+		steps = []WorkoutStep{{
+			Kind:     StepSteady,
+			Text:     workout.Name,
+			Distance: workout.Planned.Distance,
+			Duration: workout.Planned.Duration,
+		}}
+	}
+
+	pace := defaultStepPace
+	if planned := workout.Planned; planned.Distance > 0 && planned.Duration > 0 {
+		pace = time.Duration(float64(planned.Duration) / (planned.Distance / unitMeters[UnitKilometer]))
+	}
+
+	result := WorkoutSteps{Steps: steps}
+
+	for i := range result.Steps {
+		estimateStep(&result.Steps[i], pace)
+
+		distance, duration := result.Steps[i].Total()
+		result.Distance += distance
+		result.Duration += duration
+	}
+
+	return result
+}
+
+func parseStep(text string, swim bool) (WorkoutStep, bool) {
+	text = strings.TrimSpace(text)
+
+	if m := stepRepeatRe.FindStringSubmatch(text); m != nil {
+		repeat, err := strconv.Atoi(m[1])
+		if err != nil || repeat < 1 {
+			return WorkoutStep{}, false
+		}
+
+		step := WorkoutStep{Kind: StepRepeat, Text: text, Repeat: repeat}
+
+		for i, part := range stepRecoverySep.Split(m[2], -1) {
+			kind := StepInterval
+			if i > 0 {
+				kind = StepRecovery
+			}
+
+			if inner, ok := parseStepAmount(kind, part, true, swim); ok {
+				step.Steps = append(step.Steps, inner)
+			}
+		}
+
+		return step, len(step.Steps) > 0
+	}
+
+	kind := StepSteady
+
+	switch {
+	case stepWarmupRe.MatchString(text):
+		kind = StepWarmup
+	case stepCooldownRe.MatchString(text):
+		kind = StepCooldown
+	case stepRecoveryRe.MatchString(text):
+		kind = StepRecovery
+	}
+
+	return parseStepAmount(kind, text, false, swim)
+}
+
+// parseStepAmount parses the distance, the duration and the pace of the step.
+// In repeats a number without a unit is meters, as in "400 jog". Out of repeats of other activities
+// than swimming a short distance in "m" is minutes, as in "45m easy".
+func parseStepAmount(kind, text string, inRepeat, swim bool) (WorkoutStep, bool) {
+	step := WorkoutStep{Kind: kind, Text: strings.TrimSpace(text)}
+
+	pace := stepPaceRe.FindStringSubmatch(text)
+	if pace != nil {
+		text = strings.Replace(text, pace[0], "", 1)
+	}
+
+	if m := unitsDistanceRe.FindStringSubmatch(text); m != nil {
+		n := parseNumber(m[1])
+		if !inRepeat && !swim && strings.EqualFold(m[4], UnitMeter) && n < minBareMeters {
+			step.Duration = time.Duration(n * float64(time.Minute))
+		} else {
+			step.Distance = n * unitMeters[unitAliases[strings.ToLower(m[4])]]
+		}
+
+		text = strings.Replace(text, m[0], "", 1)
+	}
+
+	if step.Duration == 0 {
+		step.Duration = parseStepTime(text)
+	}
+
+	if inRepeat && step.Distance == 0 && step.Duration == 0 {
+		if m := stepBareRe.FindStringSubmatch(text); m != nil {
+			if meters, err := strconv.Atoi(m[1]); err == nil && meters >= minBareMeters {
+				step.Distance = float64(meters)
+			}
+		}
+	}
+
+	switch {
+	case pace == nil:
+	case pace[2] == "" && inRepeat && step.Distance > 0 && step.Duration == 0:
+		step.Duration = parsePace(pace[1])
+		step.Pace = time.Duration(float64(step.Duration) / (step.Distance / unitMeters[UnitKilometer]))
+	case pace[2] == "":
+		step.Pace = parsePace(pace[1])
+	default:
+		step.Pace = ConvertPace(parsePace(pace[1]), unitAliases[strings.ToLower(pace[2])], UnitKilometer)
+	}
+
+	return step, step.Distance > 0 || step.Duration > 0
+}
+
+// parseStepTime parses "10 min", "1:30:00" or "25:00" and returns 0 when the text has no duration.
+func parseStepTime(text string) time.Duration {
+	if m := stepDurationRe.FindStringSubmatch(text); m != nil {
+		return parseStepDuration(parseNumber(m[1]), strings.ToLower(m[2]+m[3]))
+	}
+
+	if m := stepClockHoursRe.FindStringSubmatch(text); m != nil {
+		hours, _ := strconv.Atoi(m[1])
+
+		return time.Duration(hours)*time.Hour + parsePace(m[2]+":"+m[3])
+	}
+
+	if m := stepClockRe.FindString(text); m != "" {
+		return parsePace(m)
+	}
+
+	return 0
+}
+
+func parseStepDuration(n float64, unit string) time.Duration {
+	switch {
+	case strings.HasPrefix(unit, "h"):
+		return time.Duration(n * float64(time.Hour))
+	case strings.HasPrefix(unit, "s"), unit == `"`, unit == "''":
+		return time.Duration(n * float64(time.Second))
+	default:
+		return time.Duration(n * float64(time.Minute))
+	}
+}
+
+// estimateStep fills in the distance or the duration from the pace of the step or the given pace.
+func estimateStep(step *WorkoutStep, pace time.Duration) {
+	for i := range step.Steps {
+		estimateStep(&step.Steps[i], pace)
+	}
+
+	step.ByTime = step.Kind != StepRepeat && step.Distance == 0
+
+	// Repeats are sums of their steps. Standing rest has no distance to estimate.
+	if step.Kind == StepRepeat || standingRest(*step) {
+		return
+	}
+
+	if step.Pace > 0 {
+		pace = step.Pace
+	}
+
+	km := unitMeters[UnitKilometer]
+
+	switch {
+	case step.Duration == 0:
+		step.Duration = time.Duration(step.Distance / km * float64(pace)).Round(time.Second)
+	case step.Distance == 0:
+		step.Distance = float64(step.Duration) / float64(pace) * km
+	}
+}
+
+// standingRest is a recovery without moving, as in "2' rest".
+func standingRest(step WorkoutStep) bool {
+	return step.Kind == StepRecovery && stepRestRe.MatchString(step.Text)
+}
+
+var stepNames = map[string]string{
+	StepWarmup:   msgStepWarmup,
+	StepSteady:   msgStepSteady,
+	StepInterval: msgStepInterval,
+	StepRecovery: msgStepRecovery,
+	StepCooldown: msgStepCooldown,
+}
+
+// MessageSteps lists steps of workouts of the day with estimates in the units.
+func MessageSteps(loc *Locale, units, dayName string, date time.Time, workouts []Workout) string {
+	msg := strings.Builder{}
+	msg.WriteString(loc.T(msgSteps, dayName, loc.Date(date)))
+
+	found := false
+
+	for _, w := range workouts {
+		if !w.Date.Equal(date) || w.RestDay {
+			continue
+		}
+
+		found = true
+		swim := strings.Contains(strings.ToLower(w.Activity), "swim")
+		steps := ParseWorkoutSteps(w)
+
+		title, _, _ := strings.Cut(strings.TrimSpace(w.Description), "\n")
+		if title == "" {
+			title = w.Name
+		}
+
+		msg.WriteString("\n\n")
+
+		if emoji := ActivityEmoji(w); emoji != "" {
+			msg.WriteString(emoji + " ")
+		}
+
+		msg.WriteString(ConvertText(title, units, swim))
+
+		if len(steps.Steps) == 0 {
+			msg.WriteString("\n" + loc.T(msgNoSteps))
+
+			continue
+		}
+
+		for i, step := range steps.Steps {
+			msg.WriteString(fmt.Sprintf("\n%d. ", i+1))
+
+			if step.Kind != StepRepeat {
+				msg.WriteString(messageStep(loc, units, swim, step))
+
+				continue
+			}
+
+			msg.WriteString(loc.T(msgStepRepeat, step.Repeat))
+
+			for _, inner := range step.Steps {
+				msg.WriteString("\n    " + messageStep(loc, units, swim, inner))
+			}
+		}
+
+		msg.WriteString("\n" + loc.T(msgStepsTotal, formatStepDistance(steps.Distance, units, swim),
+			formatStepDuration(steps.Duration)))
+	}
+
+	if !found {
+		msg.WriteString("\n" + loc.T(msgNotSet))
+	}
+
+	return msg.String()
+}
+
+func messageStep(loc *Locale, units string, swim bool, step WorkoutStep) string {
+	parts := make([]string, 0, 3)
+
+	if step.Distance > 0 {
+		parts = append(parts, formatStepDistance(step.Distance, units, swim))
+	}
+
+	if step.Pace > 0 {
+		unit := UnitKilometer
+		if units == UnitsImperial {
+			unit = UnitMile
+		}
+
+		parts = append(parts, "@ "+formatPace(ConvertPace(step.Pace, UnitKilometer, unit))+"/"+unit)
+	}
+
+	if step.Duration > 0 {
+		parts = append(parts, "~"+formatStepDuration(step.Duration))
+	}
+
+	return loc.T(stepNames[step.Kind]) + ": " + strings.Join(parts, " ")
+}
+
+// formatStepDistance shows distances under a kilometer in meters, as track intervals are measured,
+// and swims in yards for imperial units.
+func formatStepDistance(meters float64, units string, swim bool) string {
+	unit := UnitKilometer
+
+	switch {
+	case swim && units == UnitsImperial:
+		unit = UnitYard
+	case swim, meters < unitMeters[UnitKilometer]:
+		unit = UnitMeter
+	case units == UnitsImperial:
+		unit = UnitMile
+	}
+
+	return formatDistance(ConvertDistance(meters, UnitMeter, unit), unit) + " " + unit
+}
+
+// formatStepDuration formats the duration as minutes and seconds, or hours, minutes and seconds.
+func formatStepDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < time.Hour {
+		return formatPace(d)
+	}
+
+	return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// commandSteps shows steps of workouts of the day, today by default.
+func (b *Bot) commandSteps(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	today, err := b.userToday(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	planRange, err := ParsePlanArgs(args, today)
+	if errors.Is(err, ErrPlanArgs) || (err == nil && !planRange.Start.Equal(planRange.End)) {
+		return tgbotapi.NewMessage(chatID, loc.T(msgStepsUsage)), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parse steps args: %w", err)
+	}
+
+	userTokens, err := b.db.UserTokens(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		return tgbotapi.NewMessage(chatID, loc.T(msgAuthorizeFirst)), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get usertokens: %w", err)
+	}
+
+	workouts, err := accountsWorkouts(ctx, b.fs, userTokens, planRange.Start, planRange.End)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := UserSettings(ctx, b.db, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	dayName := DayName(loc, planRange.Start, today)
+
+	return tgbotapi.NewMessage(chatID, MessageSteps(loc, settings.Units, dayName, planRange.Start, workouts)), nil
+}
+
+// accountsWorkouts returns workouts of all linked FinalSurge accounts.
+func accountsWorkouts(ctx context.Context, fs FinalSurge, userTokens []UserToken, start, end time.Time,
+) ([]Workout, error) {
+	var workouts []Workout
+
+	for _, userToken := range userTokens {
+		accountWorkouts, err := fs.Workouts(ctx, userToken, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("get workouts: %w", err)
+		}
+
+		workouts = append(workouts, accountWorkouts...)
+	}
+
+	return workouts, nil
+}
@@ -0,0 +1,189 @@
+package bot_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestParseWorkoutSteps(t *testing.T) {
+	// 20 seconds at the default pace of 6:00/km.
+	strides := float64(20*time.Second) / float64(6*time.Minute) * 1000
+
+	for name, tc := range map[string]struct {
+		workout  Workout
+		expected WorkoutSteps
+	}{
+		"intervals": {
+			workout: Workout{Description: "WU 2km, 6x800m @ 3:10 w/ 400 jog, CD 2km"},
+			expected: WorkoutSteps{
+				Steps: []WorkoutStep{
+					{Kind: StepWarmup, Text: "WU 2km", Distance: 2000, Duration: 12 * time.Minute},
+					{Kind: StepRepeat, Text: "6x800m @ 3:10 w/ 400 jog", Repeat: 6, Steps: []WorkoutStep{
+						// A time without a unit is the time of the rep.
+						{
+							Kind: StepInterval, Text: "800m @ 3:10", Distance: 800,
+							Duration: 3*time.Minute + 10*time.Second, Pace: 3*time.Minute + 57*time.Second + 500*time.Millisecond,
+						},
+						{Kind: StepRecovery, Text: "400 jog", Distance: 400, Duration: 2*time.Minute + 24*time.Second},
+					}},
+					{Kind: StepCooldown, Text: "CD 2km", Distance: 2000, Duration: 12 * time.Minute},
+				},
+				Distance: 11200,
+				Duration: 57*time.Minute + 24*time.Second,
+			},
+		},
+		"intervals with pace": {
+			workout: Workout{Description: "6x800m @ 3:10/km w/ 400 jog"},
+			expected: WorkoutSteps{
+				Steps: []WorkoutStep{
+					{Kind: StepRepeat, Text: "6x800m @ 3:10/km w/ 400 jog", Repeat: 6, Steps: []WorkoutStep{
+						{
+							Kind: StepInterval, Text: "800m @ 3:10/km", Distance: 800,
+							Duration: 2*time.Minute + 32*time.Second, Pace: 3*time.Minute + 10*time.Second,
+						},
+						{Kind: StepRecovery, Text: "400 jog", Distance: 400, Duration: 2*time.Minute + 24*time.Second},
+					}},
+				},
+				Distance: 7200,
+				Duration: 29*time.Minute + 36*time.Second,
+			},
+		},
+		"pace out of repeats": {
+			workout: Workout{Description: "Tempo 5km @ 4:00"},
+			expected: WorkoutSteps{
+				Steps: []WorkoutStep{
+					{Kind: StepSteady, Text: "Tempo 5km @ 4:00", Distance: 5000, Duration: 20 * time.Minute, Pace: 4 * time.Minute},
+				},
+				Distance: 5000,
+				Duration: 20 * time.Minute,
+			},
+		},
+		"standing rest and planned pace": {
+			workout: Workout{
+				Description: "3 x 10 min with 2' rest",
+				Planned:     Volume{Distance: 10000, Duration: 50 * time.Minute},
+			},
+			expected: WorkoutSteps{
+				Steps: []WorkoutStep{
+					{Kind: StepRepeat, Text: "3 x 10 min with 2' rest", Repeat: 3, Steps: []WorkoutStep{
+						{Kind: StepInterval, Text: "10 min", Distance: 2000, Duration: 10 * time.Minute, ByTime: true},
+						{Kind: StepRecovery, Text: "2' rest", Duration: 2 * time.Minute, ByTime: true},
+					}},
+				},
+				Distance: 6000,
+				Duration: 36 * time.Minute,
+			},
+		},
+		"planned volume": {
+			workout: Workout{
+				Name:        "Easy run",
+				Description: "Keep it relaxed",
+				Planned:     Volume{Distance: 8000, Duration: 44 * time.Minute},
+			},
+			expected: WorkoutSteps{
+				Steps:    []WorkoutStep{{Kind: StepSteady, Text: "Easy run", Distance: 8000, Duration: 44 * time.Minute}},
+				Distance: 8000,
+				Duration: 44 * time.Minute,
+			},
+		},
+		"hours": {
+			workout: Workout{Activity: "Run", Description: "Long run 1:30:00"},
+			expected: WorkoutSteps{
+				Steps: []WorkoutStep{
+					{Kind: StepSteady, Text: "Long run 1:30:00", Distance: 15000, Duration: 90 * time.Minute, ByTime: true},
+				},
+				Distance: 15000,
+				Duration: 90 * time.Minute,
+			},
+		},
+		"seconds": {
+			workout: Workout{Activity: "Run", Description: "Easy 45' + 6x20'' strides"},
+			expected: WorkoutSteps{
+				Steps: []WorkoutStep{
+					{Kind: StepSteady, Text: "Easy 45'", Distance: 7500, Duration: 45 * time.Minute, ByTime: true},
+					{Kind: StepRepeat, Text: "6x20'' strides", Repeat: 6, Steps: []WorkoutStep{
+						{Kind: StepInterval, Text: "20'' strides", Distance: strides, Duration: 20 * time.Second, ByTime: true},
+					}},
+				},
+				Distance: 7500 + strides*6,
+				Duration: 47 * time.Minute,
+			},
+		},
+		"minutes as m": {
+			workout: Workout{Activity: "Run", Description: "45m easy"},
+			expected: WorkoutSteps{
+				Steps: []WorkoutStep{
+					{Kind: StepSteady, Text: "45m easy", Distance: 7500, Duration: 45 * time.Minute, ByTime: true},
+				},
+				Distance: 7500,
+				Duration: 45 * time.Minute,
+			},
+		},
+		"rest day": {
+			workout:  Workout{Description: "Rest Day", RestDay: true},
+			expected: WorkoutSteps{},
+		},
+	} {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			if actual := ParseWorkoutSteps(tc.workout); !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("actual=%+v, expected=%+v", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestBot_ProcessUpdate_Steps(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	senderMock := mock.NewMockSender(ctrl)
+	fsMock := mock.NewMockFinalSurge(ctrl)
+	storageMock := mock.NewMockStorage(ctrl)
+	clockMock := mock.NewMockClock(ctrl)
+	bot := NewBot(senderMock, storageMock, fsMock, clockMock, &Config{}, NewTaskTemplates())
+	const userName = "alexandear"
+	const chatID = int64(20)
+	storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{Units: UnitsImperial}, nil).AnyTimes()
+	storageMock.EXPECT().UpdateUserActivity(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	today := time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)
+	clockMock.EXPECT().Now().Return(time.Date(2026, time.October, 20, 8, 0, 0, 0, time.UTC)).Times(1)
+	storageMock.EXPECT().UserTokens(gomock.Any(), userName).Return([]UserToken{userToken}, nil).Times(1)
+	fsMock.EXPECT().Workouts(gomock.Any(), userToken, today, today).Return([]Workout{{
+		Date:        today,
+		Activity:    "Run",
+		Description: "WU 2km, 6x800m @ 3:10 w/ 400 jog, CD 2km",
+	}}, nil).Times(1)
+	senderMock.EXPECT().Send(tgbotapi.NewMessage(chatID, `Steps for Today 20.10:
+
+🏃 WU 1.2mi, 6x800m @ 3:10 w/ 400 jog, CD 1.2mi
+1. Warm-up: 1.2 mi ~12:00
+2. 6 ×
+    Interval: 800 m @ 6:22/mi ~3:10
+    Recovery: 400 m ~2:24
+3. Cool-down: 1.2 mi ~12:00
+Total: 7 mi, ~57:24`)).Times(1)
+
+	if err := bot.ProcessUpdate(context.Background(), tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: chatID},
+			From:     &tgbotapi.User{UserName: userName},
+			Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/steps")}},
+			Text:     "/steps",
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,421 @@
+// Package storagetest provides the conformance test suite for bot.Storage implementations.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+)
+
+// Run checks the contract every Storage implementation must follow.
+// newStorage must return an empty storage for every call.
+func Run(t *testing.T, newStorage func(t *testing.T) Storage) {
+	ctx := context.Background()
+	const userName = "alexandear"
+	personal := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	club := UserToken{
+		UserKey: "b0d1c67e-0d8c-4b67-8faa-c02104ec4f72",
+		Token:   "7f2a5f06-1b20-4dde-ba31-2c0a33be6b69",
+	}
+
+	t.Run("user token not found", func(t *testing.T) {
+		s := newStorage(t)
+
+		if _, err := s.UserToken(ctx, userName); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+
+		if _, err := s.UserTokens(ctx, userName); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+	})
+
+	t.Run("accounts", func(t *testing.T) {
+		s := newStorage(t)
+
+		mustNoErr(t, s.UpdateUserToken(ctx, userName, "personal", personal))
+		mustNoErr(t, s.UpdateUserToken(ctx, userName, "club", club))
+		expectAccounts(t, s, userName, []Account{
+			{Label: "personal", UserToken: personal},
+			{Label: "club", UserToken: club, Active: true},
+		})
+
+		userToken, err := s.UserToken(ctx, userName)
+		mustNoErr(t, err)
+		if userToken != club {
+			t.Errorf("actual=%+v, expected=%+v", userToken, club)
+		}
+
+		updated := UserToken{UserKey: personal.UserKey, Token: "a174c652-b12f-4aad-b730-a43a2c74fa9f"}
+		mustNoErr(t, s.UpdateUserToken(ctx, userName, "personal", updated))
+		expectAccounts(t, s, userName, []Account{
+			{Label: "personal", UserToken: updated, Active: true},
+			{Label: "club", UserToken: club},
+		})
+
+		mustNoErr(t, s.ActivateAllAccounts(ctx, userName))
+		userTokens, err := s.UserTokens(ctx, userName)
+		mustNoErr(t, err)
+		if !reflect.DeepEqual(userTokens, []UserToken{updated, club}) {
+			t.Errorf("actual=%+v", userTokens)
+		}
+
+		mustNoErr(t, s.ActivateAccount(ctx, userName, "club"))
+		expectAccounts(t, s, userName, []Account{
+			{Label: "personal", UserToken: updated},
+			{Label: "club", UserToken: club, Active: true},
+		})
+
+		if err := s.ActivateAccount(ctx, userName, "unknown"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+
+		mustNoErr(t, s.DeleteAccount(ctx, userName, "club"))
+		expectAccounts(t, s, userName, []Account{
+			{Label: "personal", UserToken: updated, Active: true},
+		})
+
+		if err := s.DeleteAccount(ctx, userName, "club"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+
+		mustNoErr(t, s.DeleteAccounts(ctx, userName))
+		expectAccounts(t, s, userName, nil)
+	})
+
+	t.Run("subscriptions", func(t *testing.T) {
+		s := newStorage(t)
+		today := time.Date(2020, time.December, 20, 0, 0, 0, 0, time.UTC)
+		expected := WorkoutSnapshot{
+			End: today.AddDate(0, 0, 2),
+			Workouts: []Workout{
+				{Date: today, Description: "10 km"},
+				{Date: today.AddDate(0, 0, 1), Description: "Rest Day"},
+			},
+		}
+
+		if _, err := s.WorkoutSnapshot(ctx, userName); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+
+		mustNoErr(t, s.Subscribe(ctx, Subscription{UserName: userName, ChatID: 10}))
+		mustNoErr(t, s.Subscribe(ctx, Subscription{UserName: userName, ChatID: 20}))
+
+		subscriptions, err := s.Subscriptions(ctx)
+		mustNoErr(t, err)
+		if !reflect.DeepEqual(subscriptions, []Subscription{{UserName: userName, ChatID: 20}}) {
+			t.Errorf("actual=%+v", subscriptions)
+		}
+
+		if _, err := s.WorkoutSnapshot(ctx, userName); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+
+		mustNoErr(t, s.UpdateWorkoutSnapshot(ctx, userName, WorkoutSnapshot{End: today}))
+		snapshot, err := s.WorkoutSnapshot(ctx, userName)
+		mustNoErr(t, err)
+		if !snapshot.End.Equal(today) || len(snapshot.Workouts) != 0 {
+			t.Errorf("actual=%+v, expected empty until %v", snapshot, today)
+		}
+
+		mustNoErr(t, s.UpdateWorkoutSnapshot(ctx, userName, expected))
+		snapshot, err = s.WorkoutSnapshot(ctx, userName)
+		mustNoErr(t, err)
+		if !reflect.DeepEqual(snapshot, expected) {
+			t.Errorf("actual=%+v, expected=%+v", snapshot, expected)
+		}
+
+		mustNoErr(t, s.Unsubscribe(ctx, userName))
+		if _, err := s.WorkoutSnapshot(ctx, userName); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+
+		subscriptions, err = s.Subscriptions(ctx)
+		mustNoErr(t, err)
+		if len(subscriptions) != 0 {
+			t.Errorf("actual=%+v, expected empty", subscriptions)
+		}
+	})
+
+	t.Run("calendar tokens", func(t *testing.T) {
+		s := newStorage(t)
+
+		if _, err := s.CalendarToken(ctx, userName); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+
+		mustNoErr(t, s.UpdateCalendarToken(ctx, userName, "old"))
+		mustNoErr(t, s.UpdateCalendarToken(ctx, userName, "new"))
+
+		token, err := s.CalendarToken(ctx, userName)
+		mustNoErr(t, err)
+		if token != "new" {
+			t.Errorf("actual=%s, expected=new", token)
+		}
+
+		calendarUserName, err := s.CalendarUserName(ctx, "new")
+		mustNoErr(t, err)
+		if calendarUserName != userName {
+			t.Errorf("actual=%s, expected=%s", calendarUserName, userName)
+		}
+
+		if _, err := s.CalendarUserName(ctx, "old"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+	})
+
+	t.Run("settings", func(t *testing.T) {
+		s := newStorage(t)
+
+		if _, err := s.Settings(ctx, userName); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+
+		hour, remindHour := 7, ReminderOff
+		expected := Settings{
+			Language:   LanguageUkrainian,
+			TimeZone:   "Europe/Kyiv",
+			Markup:     MarkupPlain,
+			TaskFormat: TaskFormatCompact,
+			Units:      UnitsImperial,
+			TaskDays:   3,
+			NotifyHour: &hour,
+			RemindHour: &remindHour,
+		}
+		mustNoErr(t, s.UpdateSettings(ctx, userName, Settings{Language: LanguageGerman}))
+		mustNoErr(t, s.UpdateSettings(ctx, userName, expected))
+		mustNoErr(t, s.UpdateSettings(ctx, "other", Settings{Language: LanguageEnglish}))
+
+		actual, err := s.Settings(ctx, userName)
+		mustNoErr(t, err)
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("actual=%+v, expected=%+v", actual, expected)
+		}
+
+		mustNoErr(t, s.UpdateSettings(ctx, userName, Settings{Language: LanguageGerman}))
+
+		actual, err = s.Settings(ctx, userName)
+		mustNoErr(t, err)
+		if expected := (Settings{Language: LanguageGerman}); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("actual=%+v, expected=%+v", actual, expected)
+		}
+	})
+
+	t.Run("goal race", func(t *testing.T) {
+		s := newStorage(t)
+
+		if _, err := s.GoalRace(ctx, userName); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+
+		race := Race{Date: time.Date(2021, time.April, 18, 0, 0, 0, 0, time.UTC), Name: "City Marathon"}
+		mustNoErr(t, s.UpdateGoalRace(ctx, userName, Race{Date: race.Date.AddDate(0, 0, -7), Name: "Half"}))
+		mustNoErr(t, s.UpdateGoalRace(ctx, userName, race))
+
+		actual, err := s.GoalRace(ctx, userName)
+		mustNoErr(t, err)
+		if !actual.Date.Equal(race.Date) || actual.Name != race.Name {
+			t.Errorf("actual=%+v, expected=%+v", actual, race)
+		}
+
+		mustNoErr(t, s.DeleteGoalRace(ctx, userName))
+
+		if _, err := s.GoalRace(ctx, userName); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+	})
+
+	t.Run("user activity", func(t *testing.T) {
+		s := newStorage(t)
+
+		authorized, err := s.AuthorizedUsers(ctx)
+		mustNoErr(t, err)
+		if authorized != 0 {
+			t.Errorf("actual=%d, expected=0", authorized)
+		}
+
+		mustNoErr(t, s.UpdateUserToken(ctx, userName, "personal", personal))
+		mustNoErr(t, s.UpdateUserToken(ctx, userName, "club", club))
+		mustNoErr(t, s.UpdateUserToken(ctx, "other", DefaultAccountLabel, club))
+
+		authorized, err = s.AuthorizedUsers(ctx)
+		mustNoErr(t, err)
+		if authorized != 2 {
+			t.Errorf("actual=%d, expected=2", authorized)
+		}
+
+		lastActive := time.Date(2026, time.October, 19, 8, 30, 0, 0, time.UTC)
+		mustNoErr(t, s.UpdateUserActivity(ctx, UserActivity{
+			UserName: userName, UserID: 1, ChatID: 20, LastActive: lastActive.Add(-time.Hour),
+		}))
+		mustNoErr(t, s.UpdateUserActivity(ctx, UserActivity{
+			UserName: userName, UserID: 1, ChatID: 21, LastActive: lastActive,
+		}))
+		mustNoErr(t, s.UpdateUserActivity(ctx, UserActivity{
+			UserName: "al", UserID: 2, ChatID: 22, LastActive: lastActive,
+		}))
+
+		activities, err := s.UserActivities(ctx)
+		mustNoErr(t, err)
+		if len(activities) != 2 || activities[0].UserName != "al" || activities[1].UserName != userName ||
+			activities[1].UserID != 1 || activities[1].ChatID != 21 || !activities[1].LastActive.Equal(lastActive) {
+			t.Errorf("actual=%+v", activities)
+		}
+	})
+
+	t.Run("delete user", func(t *testing.T) {
+		s := newStorage(t)
+		const otherUserName = "other"
+
+		mustNoErr(t, s.UpdateUserToken(ctx, userName, "personal", personal))
+		mustNoErr(t, s.UpdateUserToken(ctx, userName, "club", club))
+		mustNoErr(t, s.UpdateUserToken(ctx, otherUserName, DefaultAccountLabel, club))
+		mustNoErr(t, s.Subscribe(ctx, Subscription{UserName: userName, ChatID: 20}))
+		mustNoErr(t, s.UpdateWorkoutSnapshot(ctx, userName, WorkoutSnapshot{Workouts: []Workout{
+			{Date: time.Date(2020, time.December, 20, 0, 0, 0, 0, time.UTC), Description: "10 km"},
+		}}))
+		mustNoErr(t, s.UpdateCalendarToken(ctx, userName, "token"))
+		mustNoErr(t, s.UpdateSettings(ctx, userName, Settings{Language: LanguageGerman}))
+		mustNoErr(t, s.UpdateGoalRace(ctx, userName, Race{
+			Date: time.Date(2021, time.April, 18, 0, 0, 0, 0, time.UTC),
+			Name: "City Marathon",
+		}))
+		mustNoErr(t, s.UpdateUserActivity(ctx, UserActivity{
+			UserName: userName, UserID: 1, ChatID: 20, LastActive: time.Date(2021, time.April, 18, 9, 0, 0, 0, time.UTC),
+		}))
+
+		deleted, err := s.DeleteUser(ctx, userName)
+		mustNoErr(t, err)
+
+		rows := make(map[string]int64, len(deleted))
+		for _, d := range deleted {
+			rows[d.Table] = d.Rows
+		}
+
+		if expected := map[string]int64{
+			"accounts":          2,
+			"subscriptions":     1,
+			"workout_snapshots": 1,
+			"calendar_tokens":   1,
+			"user_settings":     1,
+			"goal_races":        1,
+			"user_activity":     1,
+		}; !reflect.DeepEqual(rows, expected) {
+			t.Errorf("actual=%v, expected=%v", rows, expected)
+		}
+
+		expectAccounts(t, s, userName, nil)
+
+		if _, err := s.CalendarToken(ctx, userName); !errors.Is(err, ErrNotFound) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrNotFound)
+		}
+
+		if _, err := s.UserToken(ctx, otherUserName); err != nil {
+			t.Errorf("other user must be kept: %v", err)
+		}
+	})
+
+	t.Run("user names are not padded", func(t *testing.T) {
+		s := newStorage(t)
+		const shortUserName = "al"
+
+		mustNoErr(t, s.Subscribe(ctx, Subscription{UserName: shortUserName, ChatID: 20}))
+		mustNoErr(t, s.UpdateCalendarToken(ctx, shortUserName, "token"))
+
+		subscriptions, err := s.Subscriptions(ctx)
+		mustNoErr(t, err)
+		if !reflect.DeepEqual(subscriptions, []Subscription{{UserName: shortUserName, ChatID: 20}}) {
+			t.Errorf("actual=%q", subscriptions)
+		}
+
+		calendarUserName, err := s.CalendarUserName(ctx, "token")
+		mustNoErr(t, err)
+		if calendarUserName != shortUserName {
+			t.Errorf("actual=%q, expected=%q", calendarUserName, shortUserName)
+		}
+	})
+
+	t.Run("concurrent writes", func(t *testing.T) {
+		s := newStorage(t)
+		const writers = 10
+
+		var wg sync.WaitGroup
+
+		errs := make(chan error, 2*writers)
+
+		for i := 0; i < writers; i++ {
+			wg.Add(2)
+
+			go func(i int) {
+				defer wg.Done()
+
+				errs <- s.UpdateUserToken(ctx, userName, fmt.Sprintf("label%d", i), personal)
+			}(i)
+
+			go func(i int) {
+				defer wg.Done()
+
+				errs <- s.UpdateUserToken(ctx, fmt.Sprintf("user%d", i), DefaultAccountLabel, club)
+			}(i)
+		}
+
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			mustNoErr(t, err)
+		}
+
+		accounts, err := s.Accounts(ctx, userName)
+		mustNoErr(t, err)
+
+		active := 0
+		for _, a := range accounts {
+			if a.Active {
+				active++
+			}
+		}
+
+		if len(accounts) != writers || active != 1 {
+			t.Errorf("actual %d accounts with %d active, expected %d with 1 active", len(accounts), active, writers)
+		}
+
+		for i := 0; i < writers; i++ {
+			userToken, err := s.UserToken(ctx, fmt.Sprintf("user%d", i))
+			mustNoErr(t, err)
+			if userToken != club {
+				t.Errorf("actual=%+v, expected=%+v", userToken, club)
+			}
+		}
+	})
+}
+
+func expectAccounts(t *testing.T, s Storage, userName string, expected []Account) {
+	t.Helper()
+
+	actual, err := s.Accounts(context.Background(), userName)
+	mustNoErr(t, err)
+
+	if len(actual) != 0 || len(expected) != 0 {
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("actual=%+v, expected=%+v", actual, expected)
+		}
+	}
+}
+
+func mustNoErr(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,218 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	TaskFormatFull    = "full"
+	TaskFormatCompact = "compact"
+	TaskFormatToday   = "today"
+
+	templateExt = ".tmpl"
+	// maxTemplateNameSize keeps the /settings callback data "settings:format:<name>" within the 64 bytes
+	// Telegram allows.
+	maxTemplateNameSize = 64 - len(settingsCallback+":"+settingsFormatField+":")
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+var ErrUnknownTaskFormat = errors.New("unknown task format")
+
+// TaskView is the view model passed to task templates.
+type TaskView struct {
+	// Countdown is a line such as "Race in 12 days: City Marathon" or empty if no race is coming.
+	Countdown string
+	Title     string
+	NotSet    string
+	Days      []TaskDayView
+}
+
+type TaskDayView struct {
+	Name     string
+	Date     string
+	Workouts []TaskWorkoutView
+}
+
+type TaskWorkoutView struct {
+	Description string
+	Activity    string
+	Emoji       string
+	RestDay     bool
+}
+
+// NewPlanView groups workouts by days of the range named with DayName.
+func NewPlanView(loc *Locale, workouts []Workout, r PlanRange, today time.Time) TaskView {
+	view := TaskView{
+		Title:  loc.T(msgTasks),
+		NotSet: loc.T(msgNotSet),
+	}
+
+	for date := r.Start; !date.After(r.End); date = date.AddDate(0, 0, 1) {
+		day := TaskDayView{
+			Name: DayName(loc, date, today),
+			Date: loc.Date(date),
+		}
+
+		for _, w := range workouts {
+			if !w.Date.Equal(date) {
+				continue
+			}
+
+			day.Workouts = append(day.Workouts, TaskWorkoutView{
+				Description: w.Description,
+				Activity:    w.Activity,
+				Emoji:       ActivityEmoji(w),
+				RestDay:     w.RestDay,
+			})
+		}
+
+		view.Days = append(view.Days, day)
+	}
+
+	return view
+}
+
+// DayName calls today and tomorrow so and other days by the day of the week.
+func DayName(loc *Locale, date, today time.Time) string {
+	switch {
+	case date.Equal(today):
+		return loc.T(msgToday)
+	case date.Equal(today.AddDate(0, 0, 1)):
+		return loc.T(msgTomorrow)
+	default:
+		return loc.Weekday(date)
+	}
+}
+
+// TaskTemplates renders task messages with text/template.
+// Templates are called by file name without the .tmpl extension and use functions:
+// bold and escape depend on the user markup, firstLine returns the first line of the text.
+type TaskTemplates struct {
+	templates map[string]*template.Template
+}
+
+// NewTaskTemplates returns the built-in templates full, compact and today.
+func NewTaskTemplates() *TaskTemplates {
+	t := &TaskTemplates{templates: make(map[string]*template.Template)}
+
+	if err := t.load(builtinTemplates, "templates"); err != nil {
+		panic(fmt.Sprintf("load builtin templates: %v", err))
+	}
+
+	return t
+}
+
+// LoadDir registers *.tmpl templates from dir. They replace built-in templates with the same name.
+func (t *TaskTemplates) LoadDir(dir string) error {
+	return t.load(os.DirFS(dir), ".")
+}
+
+func (t *TaskTemplates) load(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*"+templateExt))
+	if err != nil {
+		return fmt.Errorf("glob: %w", err)
+	}
+
+	for _, file := range files {
+		text, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", file, err)
+		}
+
+		name := strings.TrimSuffix(path.Base(file), templateExt)
+		if len(name) > maxTemplateNameSize {
+			return fmt.Errorf("name of %s is longer than %d bytes", file, maxTemplateNameSize)
+		}
+
+		tmpl, err := template.New(name).Funcs(markupFuncs(PlainMarkup{})).Parse(string(text))
+		if err != nil {
+			return fmt.Errorf("parse %s: %w", file, err)
+		}
+
+		t.templates[name] = tmpl
+	}
+
+	return nil
+}
+
+// Names returns sorted template names.
+func (t *TaskTemplates) Names() []string {
+	names := make([]string, 0, len(t.templates))
+	for name := range t.templates {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (t *TaskTemplates) Has(name string) bool {
+	_, ok := t.templates[name]
+
+	return ok
+}
+
+func (t *TaskTemplates) Render(name string, markup Markup, view TaskView) (string, error) {
+	tmpl, ok := t.templates[name]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownTaskFormat, name)
+	}
+
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return "", fmt.Errorf("clone: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Funcs(markupFuncs(markup)).Execute(&buf, view); err != nil {
+		return "", fmt.Errorf("execute %s: %w", name, err)
+	}
+
+	return buf.String(), nil
+}
+
+// UserTaskFormat returns the template name chosen by the user with /format.
+func (t *TaskTemplates) UserTaskFormat(ctx context.Context, db SettingsStore, userName string) (string, error) {
+	settings, err := UserSettings(ctx, db, userName)
+	if err != nil {
+		return "", err
+	}
+
+	return t.Format(settings), nil
+}
+
+// Format returns the template name of the settings.
+func (t *TaskTemplates) Format(settings Settings) string {
+	// The template may have been removed from the directory since it was chosen.
+	if !t.Has(settings.TaskFormat) {
+		return TaskFormatFull
+	}
+
+	return settings.TaskFormat
+}
+
+func markupFuncs(markup Markup) template.FuncMap {
+	return template.FuncMap{
+		"bold":   markup.Bold,
+		"escape": markup.Escape,
+		"firstLine": func(s string) string {
+			line, _, _ := strings.Cut(s, "\n")
+
+			return line
+		},
+	}
+}
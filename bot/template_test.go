@@ -0,0 +1,223 @@
+package bot_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+)
+
+func TestTaskTemplates_Render(t *testing.T) {
+	templates := NewTaskTemplates()
+	today := time.Date(2020, time.December, 23, 0, 0, 0, 0, time.UTC)
+	tomorrow := time.Date(2020, time.December, 24, 0, 0, 0, 0, time.UTC)
+	for name, tc := range map[string]struct {
+		data     []Workout
+		expected string
+	}{
+		"today and tomorrow": {
+			data: []Workout{
+				{
+					Date:        today,
+					Description: "Warm-up",
+				},
+				{
+					Date:        today,
+					Description: "6 km",
+				},
+				{
+					Date:        tomorrow,
+					Description: "12 km",
+				},
+			},
+			expected: `Tasks:
+Today 23.12:
+Warm-up
+6 km
+
+Tomorrow 24.12:
+12 km
+`,
+		},
+		"today not set": {
+			data: []Workout{
+				{
+					Date:        tomorrow,
+					Description: "12 km",
+				},
+			},
+			expected: `Tasks:
+Today 23.12:
+not set
+
+Tomorrow 24.12:
+12 km
+`,
+		},
+		"tomorrow not set": {
+			data: []Workout{
+				{
+					Date:        today,
+					Description: "Warm-up",
+				},
+				{
+					Date:        today,
+					Description: "6 km",
+				},
+			},
+			expected: `Tasks:
+Today 23.12:
+Warm-up
+6 km
+
+Tomorrow 24.12:
+not set
+`,
+		},
+		"today and tomorrow not set": {
+			data: []Workout{},
+			expected: `Tasks:
+Today 23.12:
+not set
+
+Tomorrow 24.12:
+not set
+`,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual := render(t, templates, TaskFormatFull, PlainMarkup{}, taskView(LocaleFor(LanguageEnglish), tc.data, today, tomorrow))
+
+			if actual != tc.expected {
+				t.Errorf("actual=%s, expected=%s", actual, tc.expected)
+			}
+		})
+	}
+
+	t.Run("html", func(t *testing.T) {
+		actual := render(t, templates, TaskFormatFull, HTMLMarkup{}, taskView(LocaleFor(LanguageEnglish), []Workout{
+			{Date: today, Description: "Intervals:\n- 6x400m @ 5K pace & 200m jog", Activity: "Run"},
+			{Date: today, Description: "Core", Activity: "Strength Training"},
+			{Date: tomorrow, Description: "Pool 2 km", Activity: "Swim"},
+			{Date: tomorrow, Description: "Commute", Activity: "Bike"},
+		}, today, tomorrow))
+
+		if expected := `<b>Tasks:</b>
+<b>Today 23.12:</b>
+🏃 Intervals:
+- 6x400m @ 5K pace &amp; 200m jog
+🏋️ Core
+
+<b>Tomorrow 24.12:</b>
+🏊 Pool 2 km
+🚴 Commute
+`; actual != expected {
+			t.Errorf("actual=%s, expected=%s", actual, expected)
+		}
+	})
+
+	t.Run("german", func(t *testing.T) {
+		actual := render(t, templates, TaskFormatFull, PlainMarkup{},
+			taskView(LocaleFor(LanguageGerman), []Workout{{Date: today, Description: "6 km"}}, today, tomorrow))
+
+		if expected := `Trainings:
+Heute 23.12.:
+6 km
+
+Morgen 24.12.:
+nicht geplant
+`; actual != expected {
+			t.Errorf("actual=%s, expected=%s", actual, expected)
+		}
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		actual := render(t, templates, TaskFormatCompact, PlainMarkup{}, taskView(LocaleFor(LanguageEnglish), []Workout{
+			{Date: today, Description: "Intervals:\n- 6x400m", Activity: "Run"},
+			{Date: today, Description: "Core"},
+		}, today, tomorrow))
+
+		if expected := `Tasks:
+Today 23.12: 🏃 Intervals:; Core
+Tomorrow 24.12: not set
+`; actual != expected {
+			t.Errorf("actual=%s, expected=%s", actual, expected)
+		}
+	})
+
+	t.Run("today", func(t *testing.T) {
+		actual := render(t, templates, TaskFormatToday, HTMLMarkup{}, taskView(LocaleFor(LanguageEnglish), []Workout{
+			{Date: tomorrow, Description: "12 km"},
+		}, today, tomorrow))
+
+		if expected := `<b>Today 23.12:</b>
+not set
+`; actual != expected {
+			t.Errorf("actual=%s, expected=%s", actual, expected)
+		}
+	})
+}
+
+func TestTaskTemplates_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "team.tmpl"),
+		[]byte(`{{range .Days}}{{.Name}}: {{len .Workouts}}{{"\n"}}{{end}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	templates := NewTaskTemplates()
+	if err := templates.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if actual, expected := templates.Names(), []string{"compact", "full", "team", "today"}; !reflect.DeepEqual(actual,
+		expected) {
+		t.Errorf("actual=%v, expected=%v", actual, expected)
+	}
+
+	today := time.Date(2020, time.December, 23, 0, 0, 0, 0, time.UTC)
+	actual := render(t, templates, "team", PlainMarkup{}, taskView(LocaleFor(LanguageEnglish), []Workout{
+		{Date: today, Description: "6 km"},
+	}, today, today.AddDate(0, 0, 1)))
+
+	if expected := "Today: 1\nTomorrow: 0\n"; actual != expected {
+		t.Errorf("actual=%q, expected=%q", actual, expected)
+	}
+
+	if _, err := templates.Render("unknown", PlainMarkup{}, TaskView{}); !errors.Is(err, ErrUnknownTaskFormat) {
+		t.Errorf("actual err=%v, expected=%v", err, ErrUnknownTaskFormat)
+	}
+}
+
+func TestTaskTemplates_LoadDir_longName(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, strings.Repeat("n", 49)+".tmpl"), []byte("{{len .Days}}"),
+		0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The name would not fit into the callback data of the /settings button.
+	if err := NewTaskTemplates().LoadDir(dir); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func render(t *testing.T, templates *TaskTemplates, name string, markup Markup, view TaskView) string {
+	t.Helper()
+
+	actual, err := templates.Render(name, markup, view)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return actual
+}
+
+func taskView(loc *Locale, workouts []Workout, today, tomorrow time.Time) TaskView {
+	return NewPlanView(loc, workouts, PlanRange{Start: today, End: tomorrow}, today)
+}
@@ -0,0 +1,169 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	UnitsMetric   = "km"
+	UnitsImperial = "mi"
+
+	UnitMeter     = "m"
+	UnitKilometer = "km"
+	UnitMile      = "mi"
+	UnitYard      = "yd"
+)
+
+// unitMeters converts distance units to meters. The keys are also FinalSurge amount types.
+var unitMeters = map[string]float64{
+	UnitMeter:     1,
+	UnitKilometer: 1000,
+	UnitMile:      1609.344,
+	UnitYard:      0.9144,
+}
+
+// unitAliases are spellings of distance units used by coaches.
+var unitAliases = map[string]string{
+	"m": UnitMeter, "meter": UnitMeter, "meters": UnitMeter, "metres": UnitMeter,
+	"k": UnitKilometer, "km": UnitKilometer, "kms": UnitKilometer,
+	"mi": UnitMile, "mile": UnitMile, "miles": UnitMile,
+	"yd": UnitYard, "yds": UnitYard, "yard": UnitYard, "yards": UnitYard,
+}
+
+// unitsNumber is a number such as "10", "2.5", "2,5" or "1,650".
+const unitsNumber = `(\d+(?:[.,]\d+)?)`
+
+var (
+	unitsThousandsRe = regexp.MustCompile(`^\d{1,3},\d{3}$`)
+	// unitsPaceRe matches paces and pace ranges such as "4:30/km", "7:15-7:30 min/mi" or "8:00 per mile".
+	unitsPaceRe = regexp.MustCompile(`(?i)\b(\d{1,2}:[0-5]\d)(?:\s*[-–]\s*(\d{1,2}:[0-5]\d))?` +
+		`(\s*(?:min)?\s*(?:/|per)\s*)(km|mi|mile)\b`)
+	// unitsDistanceRe matches distances and distance ranges such as "10 km", "8-10mi" or "4x400 yards".
+	unitsDistanceRe = regexp.MustCompile(`(?i)` + unitsNumber + `(?:\s*[-–]\s*` + unitsNumber + `)?` +
+		`(\s*)(kms?|k|miles?|mi|meters|metres|meter|m|yards?|yds?)\b`)
+)
+
+// ConvertDistance converts the distance between units such as UnitKilometer and UnitMile.
+func ConvertDistance(distance float64, from, to string) float64 {
+	return distance * unitMeters[from] / unitMeters[to]
+}
+
+// ConvertPace converts the time per one unit such as min/km to the time per another unit such as min/mi.
+func ConvertPace(pace time.Duration, from, to string) time.Duration {
+	return time.Duration(float64(pace) * unitMeters[to] / unitMeters[from])
+}
+
+// ConvertText rewrites distances and paces in the workout description to the units.
+// Kilometers and miles are converted for every activity. Meters and yards are converted for swimming only,
+// because track intervals are run in meters everywhere while pools are measured in meters or yards.
+func ConvertText(text, units string, swim bool) string {
+	distanceUnit := UnitKilometer
+	swimUnit := UnitMeter
+
+	if units == UnitsImperial {
+		distanceUnit = UnitMile
+		swimUnit = UnitYard
+	}
+
+	text = unitsPaceRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := unitsPaceRe.FindStringSubmatch(s)
+
+		from := unitAliases[strings.ToLower(m[4])]
+		if from == distanceUnit {
+			return s
+		}
+
+		converted := formatPace(ConvertPace(parsePace(m[1]), from, distanceUnit))
+		if m[2] != "" {
+			converted += "-" + formatPace(ConvertPace(parsePace(m[2]), from, distanceUnit))
+		}
+
+		return converted + m[3] + distanceUnit
+	})
+
+	return unitsDistanceRe.ReplaceAllStringFunc(text, func(s string) string {
+		m := unitsDistanceRe.FindStringSubmatch(s)
+
+		to := distanceUnit
+
+		from := unitAliases[strings.ToLower(m[4])]
+		if from == UnitMeter || from == UnitYard {
+			if !swim {
+				return s
+			}
+
+			to = swimUnit
+		}
+
+		if from == to {
+			return s
+		}
+
+		converted := formatDistance(ConvertDistance(parseNumber(m[1]), from, to), to)
+		if m[2] != "" {
+			converted += "-" + formatDistance(ConvertDistance(parseNumber(m[2]), from, to), to)
+		}
+
+		return converted + m[3] + to
+	})
+}
+
+// ConvertTaskView converts descriptions of workouts in the view to the units.
+func ConvertTaskView(view TaskView, units string) TaskView {
+	days := make([]TaskDayView, len(view.Days))
+
+	for i, day := range view.Days {
+		workouts := make([]TaskWorkoutView, len(day.Workouts))
+
+		for j, w := range day.Workouts {
+			w.Description = ConvertText(w.Description, units, strings.Contains(strings.ToLower(w.Activity), "swim"))
+			workouts[j] = w
+		}
+
+		day.Workouts = workouts
+		days[i] = day
+	}
+
+	view.Days = days
+
+	return view
+}
+
+// parseNumber parses "2,5" as a decimal number and "1,650" as a number with a thousands separator.
+func parseNumber(s string) float64 {
+	if unitsThousandsRe.MatchString(s) {
+		s = strings.Replace(s, ",", "", 1)
+	}
+
+	n, _ := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+
+	return n
+}
+
+func parsePace(s string) time.Duration {
+	minutes, seconds, _ := strings.Cut(s, ":")
+	m, _ := strconv.Atoi(minutes)
+	sec, _ := strconv.Atoi(seconds)
+
+	return time.Duration(m)*time.Minute + time.Duration(sec)*time.Second
+}
+
+// formatDistance rounds kilometers and miles to tenths and meters and yards to whole numbers.
+func formatDistance(distance float64, unit string) string {
+	if unit == UnitMeter || unit == UnitYard {
+		return strconv.Itoa(int(math.Round(distance)))
+	}
+
+	return strconv.FormatFloat(math.Round(distance*10)/10, 'f', -1, 64)
+}
+
+func formatPace(pace time.Duration) string {
+	pace = pace.Round(time.Second)
+
+	return fmt.Sprintf("%d:%02d", int(pace.Minutes()), int(pace.Seconds())%60)
+}
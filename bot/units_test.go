@@ -0,0 +1,118 @@
+package bot_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+)
+
+func TestConvertText(t *testing.T) {
+	for name, tc := range map[string]struct {
+		text     string
+		units    string
+		swim     bool
+		expected string
+	}{
+		"km to mi": {
+			text:     "Easy 10 km, then 2km strides",
+			units:    UnitsImperial,
+			expected: "Easy 6.2 mi, then 1.2mi strides",
+		},
+		"mi to km": {
+			text:     "Long run 13.1 miles",
+			units:    UnitsMetric,
+			expected: "Long run 21.1 km",
+		},
+		"range": {
+			text:     "8-10 km easy",
+			units:    UnitsImperial,
+			expected: "5-6.2 mi easy",
+		},
+		"pace": {
+			text:     "6x800m @ 3:10/km w/ 400 jog",
+			units:    UnitsImperial,
+			expected: "6x800m @ 5:06/mi w/ 400 jog",
+		},
+		"pace range": {
+			text:     "Tempo 7:15-7:30 min/mi",
+			units:    UnitsMetric,
+			expected: "Tempo 4:30-4:40 min/km",
+		},
+		"same units": {
+			text:     "WU 2km, 5k @ 4:00/km",
+			units:    UnitsMetric,
+			expected: "WU 2km, 5k @ 4:00/km",
+		},
+		"run meters are kept": {
+			text:     "10x400m",
+			units:    UnitsImperial,
+			expected: "10x400m",
+		},
+		"swim meters to yards": {
+			text:     "10x100m, 1,500 m total",
+			units:    UnitsImperial,
+			swim:     true,
+			expected: "10x109yd, 1640 yd total",
+		},
+		"swim yards to meters": {
+			text:     "1,650 yards",
+			units:    UnitsMetric,
+			swim:     true,
+			expected: "1509 m",
+		},
+		"minutes are not meters": {
+			text:     "30 min easy",
+			units:    UnitsImperial,
+			swim:     true,
+			expected: "30 min easy",
+		},
+	} {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			if actual := ConvertText(tc.text, tc.units, tc.swim); actual != tc.expected {
+				t.Errorf("actual=%q, expected=%q", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestConvertDistance(t *testing.T) {
+	if actual := ConvertDistance(26.2, UnitMile, UnitKilometer); math.Abs(actual-42.165) > 0.001 {
+		t.Errorf("actual=%f, expected=42.165", actual)
+	}
+
+	if actual := ConvertDistance(100, UnitYard, UnitMeter); math.Abs(actual-91.44) > 0.001 {
+		t.Errorf("actual=%f, expected=91.44", actual)
+	}
+}
+
+func TestConvertPace(t *testing.T) {
+	actual := ConvertPace(5*time.Minute, UnitKilometer, UnitMile).Round(time.Second)
+	if expected := 8*time.Minute + 3*time.Second; actual != expected {
+		t.Errorf("actual=%v, expected=%v", actual, expected)
+	}
+}
+
+func TestConvertTaskView(t *testing.T) {
+	view := TaskView{Days: []TaskDayView{{Workouts: []TaskWorkoutView{
+		{Description: "Swim 400 yd", Activity: "Swim"},
+		{Description: "Run 5 mi", Activity: "Run"},
+	}}}}
+
+	converted := ConvertTaskView(view, UnitsMetric)
+
+	if actual := converted.Days[0].Workouts[0].Description; actual != "Swim 366 m" {
+		t.Errorf("actual=%q, expected=%q", actual, "Swim 366 m")
+	}
+
+	if actual := converted.Days[0].Workouts[1].Description; actual != "Run 8 km" {
+		t.Errorf("actual=%q, expected=%q", actual, "Run 8 km")
+	}
+
+	if actual := view.Days[0].Workouts[1].Description; actual != "Run 5 mi" {
+		t.Errorf("the view is changed: %q", actual)
+	}
+}
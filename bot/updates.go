@@ -0,0 +1,269 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	// pollingTimeout is the long polling timeout of getUpdates.
+	pollingTimeout = 60 * time.Second
+	// webhookCheckInterval is how often the webhook is checked with getWebhookInfo.
+	webhookCheckInterval = 5 * time.Minute
+	// updatesBuffer is the number of received updates waiting to be processed.
+	updatesBuffer = 100
+	// maxUpdateSize limits the body of webhook requests.
+	maxUpdateSize = 1 << 20
+)
+
+// Reasons reported in UpdateStatus.LastError. Errors of tgbotapi hold the request URL with the bot token,
+// so they are never reported.
+const (
+	reasonDeleteWebhook  = "delete webhook failed"
+	reasonSetWebhook     = "set webhook failed"
+	reasonWebhookInfo    = "get webhook info failed"
+	reasonGetUpdates     = "get updates failed"
+	reasonWebhookSet     = "webhook is still set"
+	reasonWebhookMissing = "webhook is not set"
+)
+
+// UpdateStatus is the state of receiving updates reported by the readiness endpoint.
+type UpdateStatus struct {
+	Mode  string `json:"mode"`
+	Ready bool   `json:"ready"`
+	// PendingUpdates are updates Telegram has not delivered to the webhook yet.
+	PendingUpdates int `json:"pending_updates"`
+	// LastError is the last error of setting the webhook or delivering updates to it.
+	LastError string    `json:"last_error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// UpdateSource receives updates with a webhook or long polling and keeps Telegram set up for the mode.
+// The webhook URL contains the bot token, so it is never logged or reported.
+type UpdateSource struct {
+	api        UpdatesAPI
+	clock      Clock
+	mode       string
+	webhookURL string
+	updates    chan tgbotapi.Update
+
+	mu     sync.Mutex
+	status UpdateStatus
+	// lastErrorDate is the Unix time of the last webhook error Telegram reported.
+	lastErrorDate int
+}
+
+// NewUpdateSource returns the source for UpdateModeWebhook or UpdateModePolling.
+// webhookURL is used only by the webhook.
+func NewUpdateSource(api UpdatesAPI, clock Clock, mode, webhookURL string) *UpdateSource {
+	return &UpdateSource{
+		api:        api,
+		clock:      clock,
+		mode:       mode,
+		webhookURL: webhookURL,
+		updates:    make(chan tgbotapi.Update, updatesBuffer),
+		status:     UpdateStatus{Mode: mode},
+	}
+}
+
+// Start sets up Telegram for the mode and returns the channel of updates.
+// With a webhook updates come from requests to ServeHTTP.
+func (s *UpdateSource) Start() (tgbotapi.UpdatesChannel, error) {
+	if s.mode == UpdateModeWebhook {
+		if err := s.setWebhook(); err != nil {
+			return nil, err
+		}
+
+		return s.updates, nil
+	}
+
+	return s.startPolling()
+}
+
+func (s *UpdateSource) startPolling() (tgbotapi.UpdatesChannel, error) {
+	// getUpdates fails while a webhook is set, for example the one left by a cloud deployment.
+	if _, err := s.api.MakeRequest("deleteWebhook", url.Values{}); err != nil {
+		s.setStatus(false, 0, reasonDeleteWebhook)
+
+		return nil, fmt.Errorf("delete webhook: %w", redactURL(err))
+	}
+
+	info, err := s.api.GetWebhookInfo()
+	if err != nil {
+		s.setStatus(false, 0, reasonWebhookInfo)
+
+		return nil, fmt.Errorf("get webhook info: %w", redactURL(err))
+	}
+
+	if info.IsSet() {
+		s.setStatus(false, 0, reasonWebhookSet)
+
+		return nil, errors.New("webhook is still set after deleting")
+	}
+
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = int(pollingTimeout.Seconds())
+
+	updates, err := s.api.GetUpdatesChan(u)
+	if err != nil {
+		s.setStatus(false, 0, reasonGetUpdates)
+
+		return nil, fmt.Errorf("get updates chan: %w", redactURL(err))
+	}
+
+	s.setStatus(true, 0, "")
+
+	return updates, nil
+}
+
+// setWebhook registers the webhook and verifies that Telegram has the URL.
+func (s *UpdateSource) setWebhook() error {
+	if _, err := s.api.SetWebhook(tgbotapi.NewWebhook(s.webhookURL)); err != nil {
+		s.setStatus(false, 0, reasonSetWebhook)
+
+		return fmt.Errorf("set webhook: %w", redactURL(err))
+	}
+
+	info, err := s.api.GetWebhookInfo()
+	if err != nil {
+		s.setStatus(false, 0, reasonWebhookInfo)
+
+		return fmt.Errorf("get webhook info: %w", redactURL(err))
+	}
+
+	if info.URL != s.webhookURL {
+		s.setStatus(false, info.PendingUpdateCount, reasonWebhookMissing)
+
+		return errors.New("webhook info has another URL")
+	}
+
+	s.mu.Lock()
+	// Errors before the webhook is set are already handled.
+	s.lastErrorDate = info.LastErrorDate
+	s.mu.Unlock()
+
+	s.setStatus(true, info.PendingUpdateCount, "")
+
+	return nil
+}
+
+// Run checks the webhook every webhookCheckInterval until ctx is done. Long polling needs no checks.
+func (s *UpdateSource) Run(ctx context.Context) {
+	if s.mode != UpdateModeWebhook {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.clock.After(webhookCheckInterval):
+		}
+
+		if err := s.Check(); err != nil {
+			log.Printf("check webhook: %v", err)
+		}
+	}
+}
+
+// Check sets the webhook again when Telegram has lost it or has another URL.
+// Errors of delivering updates to the webhook after it was set are only reported in the status:
+// setting the same URL again does not fix them.
+func (s *UpdateSource) Check() error {
+	info, err := s.api.GetWebhookInfo()
+	if err != nil {
+		s.setStatus(false, 0, reasonWebhookInfo)
+
+		return fmt.Errorf("get webhook info: %w", redactURL(err))
+	}
+
+	if info.URL != s.webhookURL {
+		log.Printf("set webhook again: %s", reasonWebhookMissing)
+
+		if err := s.setWebhook(); err != nil {
+			return err
+		}
+
+		// The webhook works again, the reason is kept to be seen on the readiness endpoint.
+		s.setStatus(true, info.PendingUpdateCount, reasonWebhookMissing)
+
+		return nil
+	}
+
+	s.mu.Lock()
+	newError := info.LastErrorDate > s.lastErrorDate
+	s.mu.Unlock()
+
+	lastError := ""
+	if newError {
+		lastError = info.LastErrorMessage
+	}
+
+	s.setStatus(true, info.PendingUpdateCount, lastError)
+
+	return nil
+}
+
+// redactURL drops the request URL with the bot token from errors of the HTTP client.
+func redactURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+	}
+
+	return err
+}
+
+// ServeHTTP receives updates sent by Telegram to the webhook.
+func (s *UpdateSource) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	var update tgbotapi.Update
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxUpdateSize)).Decode(&update); err != nil {
+		http.Error(w, "invalid update", http.StatusBadRequest)
+
+		return
+	}
+
+	select {
+	case s.updates <- update:
+	case <-r.Context().Done():
+		// Telegram sends the update again when the request fails.
+		http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	}
+}
+
+// Status returns the mode and whether updates are received.
+func (s *UpdateSource) Status() UpdateStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.status
+}
+
+func (s *UpdateSource) setStatus(ready bool, pendingUpdates int, lastError string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.status = UpdateStatus{
+		Mode:           s.mode,
+		Ready:          ready,
+		PendingUpdates: pendingUpdates,
+		LastError:      lastError,
+		CheckedAt:      s.clock.Now(),
+	}
+}
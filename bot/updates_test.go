@@ -0,0 +1,240 @@
+package bot_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+const webhookURL = "https://final-surge-bot.onrender.com/123:token"
+
+var webhookCheckedAt = time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+func newUpdateSource(t *testing.T, mode string) (*UpdateSource, *mock.MockUpdatesAPI) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+	apiMock := mock.NewMockUpdatesAPI(ctrl)
+	clockMock := mock.NewMockClock(ctrl)
+	clockMock.EXPECT().Now().Return(webhookCheckedAt).AnyTimes()
+
+	return NewUpdateSource(apiMock, clockMock, mode, webhookURL), apiMock
+}
+
+func TestUpdateSource_Start_Polling(t *testing.T) {
+	t.Run("deletes webhook", func(t *testing.T) {
+		source, apiMock := newUpdateSource(t, UpdateModePolling)
+		updates := make(chan tgbotapi.Update)
+		gomock.InOrder(
+			apiMock.EXPECT().MakeRequest("deleteWebhook", url.Values{}).
+				Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1),
+			apiMock.EXPECT().GetWebhookInfo().Return(tgbotapi.WebhookInfo{}, nil).Times(1),
+			apiMock.EXPECT().GetUpdatesChan(tgbotapi.UpdateConfig{Timeout: 60}).
+				Return(tgbotapi.UpdatesChannel(updates), nil).Times(1),
+		)
+
+		if _, err := source.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := UpdateStatus{Mode: UpdateModePolling, Ready: true, CheckedAt: webhookCheckedAt}
+		if actual := source.Status(); actual != expected {
+			t.Errorf("actual=%+v, expected=%+v", actual, expected)
+		}
+	})
+
+	t.Run("webhook still set", func(t *testing.T) {
+		source, apiMock := newUpdateSource(t, UpdateModePolling)
+		apiMock.EXPECT().MakeRequest("deleteWebhook", url.Values{}).
+			Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1)
+		apiMock.EXPECT().GetWebhookInfo().Return(tgbotapi.WebhookInfo{URL: webhookURL}, nil).Times(1)
+
+		if _, err := source.Start(); err == nil {
+			t.Fatal("expected error")
+		}
+
+		expected := UpdateStatus{Mode: UpdateModePolling, LastError: "webhook is still set", CheckedAt: webhookCheckedAt}
+		if actual := source.Status(); actual != expected {
+			t.Errorf("actual=%+v, expected=%+v", actual, expected)
+		}
+	})
+}
+
+func TestUpdateSource_Start_Webhook(t *testing.T) {
+	t.Run("sets webhook", func(t *testing.T) {
+		source, apiMock := newUpdateSource(t, UpdateModeWebhook)
+		gomock.InOrder(
+			apiMock.EXPECT().SetWebhook(tgbotapi.NewWebhook(webhookURL)).Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1),
+			apiMock.EXPECT().GetWebhookInfo().
+				Return(tgbotapi.WebhookInfo{URL: webhookURL, PendingUpdateCount: 3}, nil).Times(1),
+		)
+
+		if _, err := source.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := UpdateStatus{Mode: UpdateModeWebhook, Ready: true, PendingUpdates: 3, CheckedAt: webhookCheckedAt}
+		if actual := source.Status(); actual != expected {
+			t.Errorf("actual=%+v, expected=%+v", actual, expected)
+		}
+	})
+
+	t.Run("token not reported", func(t *testing.T) {
+		source, apiMock := newUpdateSource(t, UpdateModeWebhook)
+		// The HTTP client reports the request URL with the bot token.
+		apiMock.EXPECT().SetWebhook(gomock.Any()).Return(tgbotapi.APIResponse{}, &url.Error{
+			Op:  "Post",
+			URL: "https://api.telegram.org/bot123:token/setWebhook",
+			Err: errors.New("connection refused"),
+		}).Times(1)
+
+		_, err := source.Start()
+		if expected := "set webhook: Post: connection refused"; err == nil || err.Error() != expected {
+			t.Errorf("actual=%v, expected=%s", err, expected)
+		}
+
+		expected := UpdateStatus{Mode: UpdateModeWebhook, LastError: "set webhook failed", CheckedAt: webhookCheckedAt}
+		if actual := source.Status(); actual != expected {
+			t.Errorf("actual=%+v, expected=%+v", actual, expected)
+		}
+
+		if actual := source.Status().LastError; strings.Contains(actual, "123:token") {
+			t.Errorf("actual=%s, expected no token", actual)
+		}
+	})
+
+	t.Run("webhook not verified", func(t *testing.T) {
+		source, apiMock := newUpdateSource(t, UpdateModeWebhook)
+		apiMock.EXPECT().SetWebhook(gomock.Any()).Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1)
+		apiMock.EXPECT().GetWebhookInfo().Return(tgbotapi.WebhookInfo{}, nil).Times(1)
+
+		if _, err := source.Start(); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
+
+func TestUpdateSource_Check(t *testing.T) {
+	start := func(t *testing.T) (*UpdateSource, *mock.MockUpdatesAPI) {
+		source, apiMock := newUpdateSource(t, UpdateModeWebhook)
+		apiMock.EXPECT().SetWebhook(gomock.Any()).Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1)
+		// An error before the start is not a reason to set the webhook again.
+		apiMock.EXPECT().GetWebhookInfo().
+			Return(tgbotapi.WebhookInfo{URL: webhookURL, LastErrorDate: 100}, nil).Times(1)
+
+		if _, err := source.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		return source, apiMock
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		source, apiMock := start(t)
+		apiMock.EXPECT().GetWebhookInfo().
+			Return(tgbotapi.WebhookInfo{URL: webhookURL, LastErrorDate: 100}, nil).Times(1)
+
+		if err := source.Check(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("new error", func(t *testing.T) {
+		source, apiMock := start(t)
+		// The same URL is not set again, the error is only reported.
+		apiMock.EXPECT().GetWebhookInfo().Return(tgbotapi.WebhookInfo{
+			URL: webhookURL, PendingUpdateCount: 3, LastErrorDate: 200, LastErrorMessage: "Connection timed out",
+		}, nil).Times(1)
+
+		if err := source.Check(); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := UpdateStatus{
+			Mode: UpdateModeWebhook, Ready: true, PendingUpdates: 3, LastError: "Connection timed out",
+			CheckedAt: webhookCheckedAt,
+		}
+		if actual := source.Status(); actual != expected {
+			t.Errorf("actual=%+v, expected=%+v", actual, expected)
+		}
+	})
+
+	t.Run("another URL", func(t *testing.T) {
+		source, apiMock := start(t)
+		gomock.InOrder(
+			apiMock.EXPECT().GetWebhookInfo().
+				Return(tgbotapi.WebhookInfo{URL: "https://example.com/other"}, nil).Times(1),
+			apiMock.EXPECT().SetWebhook(tgbotapi.NewWebhook(webhookURL)).Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1),
+			apiMock.EXPECT().GetWebhookInfo().Return(tgbotapi.WebhookInfo{URL: webhookURL}, nil).Times(1),
+		)
+
+		if err := source.Check(); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := UpdateStatus{
+			Mode: UpdateModeWebhook, Ready: true, LastError: "webhook is not set", CheckedAt: webhookCheckedAt,
+		}
+		if actual := source.Status(); actual != expected {
+			t.Errorf("actual=%+v, expected=%+v", actual, expected)
+		}
+	})
+
+	t.Run("webhook lost", func(t *testing.T) {
+		source, apiMock := start(t)
+		gomock.InOrder(
+			apiMock.EXPECT().GetWebhookInfo().Return(tgbotapi.WebhookInfo{}, nil).Times(1),
+			apiMock.EXPECT().SetWebhook(tgbotapi.NewWebhook(webhookURL)).Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1),
+			apiMock.EXPECT().GetWebhookInfo().Return(tgbotapi.WebhookInfo{URL: webhookURL}, nil).Times(1),
+		)
+
+		if err := source.Check(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestUpdateSource_ServeHTTP(t *testing.T) {
+	source, apiMock := newUpdateSource(t, UpdateModeWebhook)
+	apiMock.EXPECT().SetWebhook(gomock.Any()).Return(tgbotapi.APIResponse{Ok: true}, nil).Times(1)
+	apiMock.EXPECT().GetWebhookInfo().Return(tgbotapi.WebhookInfo{URL: webhookURL}, nil).Times(1)
+
+	updates, err := source.Start()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	source.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/123:token",
+		strings.NewReader(`{"update_id":7,"message":{"message_id":1,"text":"/help"}}`)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("actual=%d, expected=%d", rec.Code, http.StatusOK)
+	}
+
+	if update := <-updates; update.UpdateID != 7 || update.Message.Text != "/help" {
+		t.Errorf("actual=%d %s, expected=7 /help", update.UpdateID, update.Message.Text)
+	}
+
+	rec = httptest.NewRecorder()
+	source.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/123:token", strings.NewReader("{")))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("actual=%d, expected=%d", rec.Code, http.StatusBadRequest)
+	}
+
+	rec = httptest.NewRecorder()
+	source.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/123:token", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("actual=%d, expected=%d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
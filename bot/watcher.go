@@ -0,0 +1,204 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+type WorkoutChange struct {
+	Date   time.Time
+	Before []string
+	After  []string
+}
+
+// WorkoutSnapshot is the workouts of the polling window saved to compare with on the next poll.
+type WorkoutSnapshot struct {
+	// End is the last day of the window. It is zero in snapshots saved before the end was stored.
+	End      time.Time
+	Workouts []Workout
+}
+
+type Watcher struct {
+	bot   Sender
+	db    Storage
+	fs    FinalSurge
+	clock Clock
+
+	days     int
+	interval time.Duration
+}
+
+func NewWatcher(bot Sender, db Storage, fs FinalSurge, clock Clock, days int, interval time.Duration) *Watcher {
+	return &Watcher{
+		bot:   bot,
+		db:    db,
+		fs:    fs,
+		clock: clock,
+
+		days:     days,
+		interval: interval,
+	}
+}
+
+// Run polls workouts of subscribed users every interval until ctx is done.
+func (w *Watcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.clock.After(w.interval):
+		}
+
+		if err := w.Poll(ctx); err != nil {
+			log.Printf("poll workouts: %v", err)
+		}
+	}
+}
+
+func (w *Watcher) Poll(ctx context.Context) error {
+	subscriptions, err := w.db.Subscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("get subscriptions: %w", err)
+	}
+
+	for _, s := range subscriptions {
+		if err := w.pollSubscription(ctx, s); err != nil {
+			log.Printf("poll workouts of %s: %v", s.UserName, err)
+		}
+	}
+
+	return nil
+}
+
+func (w *Watcher) pollSubscription(ctx context.Context, subscription Subscription) error {
+	userToken, err := w.db.UserToken(ctx, subscription.UserName)
+	if err != nil {
+		return fmt.Errorf("get usertoken: %w", err)
+	}
+
+	start := NewDate(w.clock.Now())
+	end := start.AddDate(0, 0, w.days-1)
+
+	workouts, err := w.fs.Workouts(ctx, userToken, start, end)
+	if err != nil {
+		return fmt.Errorf("get workouts: %w", err)
+	}
+
+	previous, err := w.db.WorkoutSnapshot(ctx, subscription.UserName)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		// The first poll only records a snapshot to compare with.
+	case err != nil:
+		return fmt.Errorf("get workout snapshot: %w", err)
+	default:
+		// Days after the previous window were not polled yet, so their workouts are not added ones.
+		changesEnd := end
+		if !previous.End.IsZero() && previous.End.Before(end) {
+			changesEnd = previous.End
+		}
+
+		changes := WorkoutChanges(previous.Workouts, workouts, start, changesEnd)
+		if errNotify := w.notify(ctx, subscription, changes); errNotify != nil {
+			return fmt.Errorf("notify: %w", errNotify)
+		}
+	}
+
+	snapshot := WorkoutSnapshot{End: end, Workouts: workouts}
+	if err := w.db.UpdateWorkoutSnapshot(ctx, subscription.UserName, snapshot); err != nil {
+		return fmt.Errorf("update workout snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func (w *Watcher) notify(ctx context.Context, subscription Subscription, changes []WorkoutChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	loc, err := UserLocale(ctx, w.db, subscription.UserName, "")
+	if err != nil {
+		return fmt.Errorf("get locale: %w", err)
+	}
+
+	msg := tgbotapi.NewMessage(subscription.ChatID, MessageChanges(loc, changes))
+	if _, err := w.bot.Send(msg); err != nil {
+		return fmt.Errorf("send changes to chat %d: %w", subscription.ChatID, err)
+	}
+
+	return nil
+}
+
+// WorkoutChanges compares workouts per day between start and end inclusive.
+// Days outside the range are ignored, so workouts leaving the polling window are not reported as removed.
+func WorkoutChanges(before, after []Workout, start, end time.Time) []WorkoutChange {
+	descriptions := func(workouts []Workout) map[time.Time][]string {
+		days := make(map[time.Time][]string, len(workouts))
+		for _, w := range workouts {
+			days[w.Date] = append(days[w.Date], w.Description)
+		}
+
+		return days
+	}
+
+	beforeDays := descriptions(before)
+	afterDays := descriptions(after)
+
+	var changes []WorkoutChange
+
+	for date := start; !date.After(end); date = date.AddDate(0, 0, 1) {
+		b, a := beforeDays[date], afterDays[date]
+		if strings.Join(b, "\n") == strings.Join(a, "\n") {
+			continue
+		}
+
+		changes = append(changes, WorkoutChange{
+			Date:   date,
+			Before: b,
+			After:  a,
+		})
+	}
+
+	return changes
+}
+
+func MessageChanges(loc *Locale, changes []WorkoutChange) string {
+	msg := strings.Builder{}
+	msg.WriteString(loc.T(msgPlanChanged))
+	msg.WriteByte('\n')
+
+	writeDescriptions := func(descriptions []string) {
+		for _, d := range descriptions {
+			msg.WriteString(d)
+			msg.WriteByte('\n')
+		}
+	}
+
+	for _, c := range changes {
+		msg.WriteByte('\n')
+
+		switch {
+		case len(c.Before) == 0:
+			msg.WriteString(loc.T(msgWorkoutAdded, loc.Date(c.Date)) + "\n")
+			writeDescriptions(c.After)
+		case len(c.After) == 0:
+			msg.WriteString(loc.T(msgWorkoutRemoved, loc.Date(c.Date)) + "\n")
+			writeDescriptions(c.Before)
+		default:
+			msg.WriteString(loc.T(msgWorkoutEdited, loc.Date(c.Date)) + "\n")
+			msg.WriteString(loc.T(msgBefore) + "\n")
+			writeDescriptions(c.Before)
+			msg.WriteString(loc.T(msgAfter) + "\n")
+			writeDescriptions(c.After)
+		}
+	}
+
+	return msg.String()
+}
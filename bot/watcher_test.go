@@ -0,0 +1,190 @@
+package bot_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	"github.com/alexandear/final-surge-bot/bot/mock"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+func TestWatcher_Poll(t *testing.T) {
+	const userName = "alexandear"
+	const chatID = int64(20)
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	now := time.Date(2020, time.December, 20, 15, 15, 20, 0, time.UTC)
+	today := time.Date(2020, time.December, 20, 0, 0, 0, 0, time.UTC)
+	tomorrow := time.Date(2020, time.December, 21, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, time.December, 22, 0, 0, 0, 0, time.UTC)
+	workouts := []Workout{
+		{
+			Date:        today,
+			Description: "10 km",
+		},
+		{
+			Date:        tomorrow,
+			Description: "12 km",
+		},
+	}
+
+	t.Run("first poll", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		fsMock := mock.NewMockFinalSurge(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		clockMock := mock.NewMockClock(ctrl)
+		watcher := NewWatcher(senderMock, storageMock, fsMock, clockMock, 3, time.Minute)
+
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		storageMock.EXPECT().Subscriptions(gomock.Any()).
+			Return([]Subscription{{UserName: userName, ChatID: chatID}}, nil).Times(1)
+		storageMock.EXPECT().UserToken(gomock.Any(), userName).Return(userToken, nil).Times(1)
+		fsMock.EXPECT().Workouts(gomock.Any(), userToken, today, end).Return(workouts, nil).Times(1)
+		storageMock.EXPECT().WorkoutSnapshot(gomock.Any(), userName).Return(WorkoutSnapshot{}, ErrNotFound).Times(1)
+		storageMock.EXPECT().UpdateWorkoutSnapshot(gomock.Any(), userName, WorkoutSnapshot{End: end, Workouts: workouts}).
+			Return(nil).Times(1)
+
+		if err := watcher.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("changed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		fsMock := mock.NewMockFinalSurge(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		clockMock := mock.NewMockClock(ctrl)
+		watcher := NewWatcher(senderMock, storageMock, fsMock, clockMock, 3, time.Minute)
+
+		previous := WorkoutSnapshot{
+			End: end,
+			Workouts: []Workout{
+				{
+					Date:        today,
+					Description: "10 km",
+				},
+				{
+					Date:        tomorrow,
+					Description: "8 km",
+				},
+				{
+					Date:        end,
+					Description: "Long run",
+				},
+			},
+		}
+		clockMock.EXPECT().Now().Return(now).Times(1)
+		storageMock.EXPECT().Subscriptions(gomock.Any()).
+			Return([]Subscription{{UserName: userName, ChatID: chatID}}, nil).Times(1)
+		storageMock.EXPECT().UserToken(gomock.Any(), userName).Return(userToken, nil).Times(1)
+		fsMock.EXPECT().Workouts(gomock.Any(), userToken, today, end).Return(workouts, nil).Times(1)
+		storageMock.EXPECT().WorkoutSnapshot(gomock.Any(), userName).Return(previous, nil).Times(1)
+		storageMock.EXPECT().Settings(gomock.Any(), userName).Return(Settings{}, ErrNotFound).AnyTimes()
+		senderMock.EXPECT().Send(tgbotapi.MessageConfig{
+			BaseChat: tgbotapi.BaseChat{ChatID: chatID},
+			Text: `Plan changed:
+
+Edited 21.12:
+Before:
+8 km
+After:
+12 km
+
+Removed 22.12:
+Long run
+`,
+		}).Times(1)
+		storageMock.EXPECT().UpdateWorkoutSnapshot(gomock.Any(), userName, WorkoutSnapshot{End: end, Workouts: workouts}).
+			Return(nil).Times(1)
+
+		if err := watcher.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("day after midnight", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		senderMock := mock.NewMockSender(ctrl)
+		fsMock := mock.NewMockFinalSurge(ctrl)
+		storageMock := mock.NewMockStorage(ctrl)
+		clockMock := mock.NewMockClock(ctrl)
+		watcher := NewWatcher(senderMock, storageMock, fsMock, clockMock, 3, time.Minute)
+
+		yesterday := time.Date(2020, time.December, 19, 0, 0, 0, 0, time.UTC)
+		previous := WorkoutSnapshot{
+			End: tomorrow,
+			Workouts: []Workout{
+				{
+					Date:        yesterday,
+					Description: "Rest Day",
+				},
+				workouts[0],
+				workouts[1],
+			},
+		}
+		current := append(workouts[:2:2], Workout{Date: end, Description: "Long run"})
+		clockMock.EXPECT().Now().Return(time.Date(2020, time.December, 20, 0, 1, 0, 0, time.UTC)).Times(1)
+		storageMock.EXPECT().Subscriptions(gomock.Any()).
+			Return([]Subscription{{UserName: userName, ChatID: chatID}}, nil).Times(1)
+		storageMock.EXPECT().UserToken(gomock.Any(), userName).Return(userToken, nil).Times(1)
+		fsMock.EXPECT().Workouts(gomock.Any(), userToken, today, end).Return(current, nil).Times(1)
+		storageMock.EXPECT().WorkoutSnapshot(gomock.Any(), userName).Return(previous, nil).Times(1)
+		storageMock.EXPECT().UpdateWorkoutSnapshot(gomock.Any(), userName, WorkoutSnapshot{End: end, Workouts: current}).
+			Return(nil).Times(1)
+
+		if err := watcher.Poll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestWorkoutChanges(t *testing.T) {
+	start := time.Date(2020, time.December, 23, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2020, time.December, 24, 0, 0, 0, 0, time.UTC)
+	yesterday := time.Date(2020, time.December, 22, 0, 0, 0, 0, time.UTC)
+
+	before := []Workout{
+		{
+			Date:        yesterday,
+			Description: "Rest Day",
+		},
+		{
+			Date:        start,
+			Description: "6 km",
+		},
+	}
+	after := []Workout{
+		{
+			Date:        start,
+			Description: "6 km",
+		},
+		{
+			Date:        end,
+			Description: "12 km",
+		},
+	}
+
+	changes := WorkoutChanges(before, after, start, end)
+
+	if len(changes) != 1 {
+		t.Fatalf("actual=%d changes, expected=1", len(changes))
+	}
+
+	if actual := MessageChanges(LocaleFor(LanguageEnglish), changes); actual != `Plan changed:
+
+Added 24.12:
+12 km
+` {
+		t.Errorf("actual=%s", actual)
+	}
+}
@@ -0,0 +1,669 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	WorkoutFileFIT = "fit"
+	WorkoutFileTCX = "tcx"
+	WorkoutFileZWO = "zwo"
+
+	// stepPaceTolerance widens the pace of the step to the range a watch alerts on.
+	stepPaceTolerance = 5 * time.Second
+)
+
+var (
+	ErrWorkoutFileArgs = errors.New("invalid workout file arguments")
+	// ErrWorkoutFileSteps is returned when the steps do not fit into the format.
+	ErrWorkoutFileSteps = errors.New("too many workout steps")
+)
+
+// ParseWorkoutFileArgs parses the format of /export_workout, FIT by default.
+func ParseWorkoutFileArgs(args string) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(args))
+
+	switch format {
+	case "":
+		return WorkoutFileFIT, nil
+	case WorkoutFileFIT, WorkoutFileTCX, WorkoutFileZWO:
+		return format, nil
+	default:
+		return "", fmt.Errorf("%w: format %s", ErrWorkoutFileArgs, format)
+	}
+}
+
+func WorkoutFileName(workout Workout, format string) string {
+	return "workout_" + workout.Date.Format(exportDateLayout) + "." + format
+}
+
+// MarshalWorkoutFile encodes steps of the workout as a Garmin FIT or TCX workout or a Zwift ZWO file.
+// Step names are in the language of the locale.
+func MarshalWorkoutFile(loc *Locale, workout Workout, format string, created time.Time) ([]byte, error) {
+	steps := ParseWorkoutSteps(workout)
+	if len(steps.Steps) == 0 {
+		return nil, fmt.Errorf("workout %s has no steps", workout.Date.Format(exportDateLayout))
+	}
+
+	switch format {
+	case WorkoutFileFIT:
+		return marshalWorkoutFIT(loc, workout, steps, created), nil
+	case WorkoutFileTCX:
+		return marshalWorkoutTCX(loc, workout, steps)
+	case WorkoutFileZWO:
+		return marshalWorkoutZWO(workout, steps)
+	default:
+		return nil, fmt.Errorf("unknown format %s", format)
+	}
+}
+
+// workoutName is the name of the workout file: the workout name or the first line of the description.
+func workoutName(workout Workout) string {
+	if workout.Name != "" {
+		return workout.Name
+	}
+
+	name, _, _ := strings.Cut(strings.TrimSpace(workout.Description), "\n")
+
+	return name
+}
+
+func workoutSport(workout Workout) string {
+	activity := strings.ToLower(workout.Activity)
+
+	switch {
+	case strings.Contains(activity, "run"):
+		return "run"
+	case strings.Contains(activity, "bike"), strings.Contains(activity, "cycl"):
+		return "bike"
+	case strings.Contains(activity, "swim"):
+		return "swim"
+	default:
+		return ""
+	}
+}
+
+// stepSpeeds returns the speed range in m/s around the pace of the step.
+// It returns false for steps without a pace and for paces too fast to widen, which get no target.
+func stepSpeeds(step WorkoutStep) (low, high float64, ok bool) {
+	if step.Pace <= stepPaceTolerance {
+		return 0, 0, false
+	}
+
+	km := unitMeters[UnitKilometer]
+
+	return km / (step.Pace + stepPaceTolerance).Seconds(), km / (step.Pace - stepPaceTolerance).Seconds(), true
+}
+
+// truncateBytes cuts the name to the number of bytes without splitting characters.
+func truncateBytes(name string, size int) string {
+	for len(name) > size {
+		_, n := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-n]
+	}
+
+	return name
+}
+
+// truncateRunes cuts the name to the number of characters.
+func truncateRunes(name string, size int) string {
+	if runes := []rune(name); len(runes) > size {
+		return string(runes[:size])
+	}
+
+	return name
+}
+
+// FIT is the binary format of Garmin devices: https://developer.garmin.com/fit/protocol/.
+const (
+	fitProtocolVersion = 0x20
+	fitProfileVersion  = 2132
+
+	fitMesgFileID      = 0
+	fitMesgWorkout     = 26
+	fitMesgWorkoutStep = 27
+
+	fitBaseEnum   = 0x00
+	fitBaseString = 0x07
+	fitBaseUint16 = 0x84
+	fitBaseUint32 = 0x86
+
+	fitFileWorkout          = 5
+	fitManufacturerDevelop  = 255
+	fitDurationTime         = 0
+	fitDurationDistance     = 1
+	fitDurationRepeatSteps  = 6
+	fitTargetSpeed          = 0
+	fitTargetOpen           = 2
+	fitIntensityActive      = 0
+	fitIntensityRest        = 1
+	fitIntensityWarmup      = 2
+	fitIntensityCooldown    = 3
+	fitIntensityRecovery    = 4
+	fitIntensityInterval    = 5
+	fitInvalidEnum          = 0xFF
+	fitInvalidUint32        = 0xFFFFFFFF
+	fitWorkoutNameSize      = 32
+	fitWorkoutStepNameSize  = 16
+	fitSecondsSinceUnixTime = 631065600
+)
+
+var (
+	fitSports = map[string]uint8{"run": 1, "bike": 2, "swim": 5}
+
+	fitIntensities = map[string]uint8{
+		StepWarmup:   fitIntensityWarmup,
+		StepSteady:   fitIntensityActive,
+		StepInterval: fitIntensityInterval,
+		StepRecovery: fitIntensityRecovery,
+		StepCooldown: fitIntensityCooldown,
+	}
+
+	fitCRCTable = [16]uint16{
+		0x0000, 0xCC01, 0xD801, 0x1400, 0xF001, 0x3C00, 0x2800, 0xE401,
+		0xA001, 0x6C00, 0x7800, 0xB401, 0x5000, 0x9C01, 0x8801, 0x4400,
+	}
+)
+
+// fitField is a field of a FIT message. The value is uint8, uint16, uint32 or a string of the size.
+type fitField struct {
+	num      uint8
+	baseType uint8
+	size     uint8
+	value    interface{}
+}
+
+type fitMessage struct {
+	global uint16
+	fields []fitField
+}
+
+func marshalWorkoutFIT(loc *Locale, workout Workout, steps WorkoutSteps, created time.Time) []byte {
+	var stepMessages []fitMessage
+
+	for _, step := range steps.Steps {
+		if step.Kind != StepRepeat {
+			stepMessages = append(stepMessages, fitWorkoutStep(loc, len(stepMessages), step))
+
+			continue
+		}
+
+		first := len(stepMessages)
+		for _, inner := range step.Steps {
+			stepMessages = append(stepMessages, fitWorkoutStep(loc, len(stepMessages), inner))
+		}
+
+		stepMessages = append(stepMessages, fitMessage{global: fitMesgWorkoutStep, fields: fitStepFields(
+			len(stepMessages), "", fitDurationRepeatSteps, uint32(first), fitInvalidEnum, uint32(step.Repeat),
+			fitInvalidUint32, fitInvalidUint32, fitInvalidEnum)})
+	}
+
+	// Other activities are the generic sport 0.
+	sport := fitSports[workoutSport(workout)]
+
+	messages := append([]fitMessage{
+		{global: fitMesgFileID, fields: []fitField{
+			{num: 0, baseType: fitBaseEnum, size: 1, value: uint8(fitFileWorkout)},
+			{num: 1, baseType: fitBaseUint16, size: 2, value: uint16(fitManufacturerDevelop)},
+			{num: 2, baseType: fitBaseUint16, size: 2, value: uint16(0)},
+			{num: 4, baseType: fitBaseUint32, size: 4, value: uint32(created.Unix() - fitSecondsSinceUnixTime)},
+		}},
+		{global: fitMesgWorkout, fields: []fitField{
+			{num: 8, baseType: fitBaseString, size: fitWorkoutNameSize, value: workoutName(workout)},
+			{num: 4, baseType: fitBaseEnum, size: 1, value: sport},
+			{num: 6, baseType: fitBaseUint16, size: 2, value: uint16(len(stepMessages))},
+		}},
+	}, stepMessages...)
+
+	data := bytes.Buffer{}
+
+	for i, m := range messages {
+		// Every message type has the same fields, so it is defined once. All use the local message type 0.
+		if i == 0 || messages[i-1].global != m.global {
+			data.Write([]byte{0x40, 0, 0})
+			_ = binary.Write(&data, binary.LittleEndian, m.global)
+			data.WriteByte(uint8(len(m.fields)))
+
+			for _, f := range m.fields {
+				data.Write([]byte{f.num, f.size, f.baseType})
+			}
+		}
+
+		data.WriteByte(0)
+
+		for _, f := range m.fields {
+			if s, ok := f.value.(string); ok {
+				bs := make([]byte, f.size)
+				copy(bs, truncateBytes(s, int(f.size)-1))
+				data.Write(bs)
+
+				continue
+			}
+
+			_ = binary.Write(&data, binary.LittleEndian, f.value)
+		}
+	}
+
+	file := bytes.Buffer{}
+	file.Write([]byte{14, fitProtocolVersion})
+	_ = binary.Write(&file, binary.LittleEndian, uint16(fitProfileVersion))
+	_ = binary.Write(&file, binary.LittleEndian, uint32(data.Len()))
+	file.WriteString(".FIT")
+	_ = binary.Write(&file, binary.LittleEndian, FITChecksum(file.Bytes()))
+	file.Write(data.Bytes())
+	_ = binary.Write(&file, binary.LittleEndian, FITChecksum(file.Bytes()))
+
+	return file.Bytes()
+}
+
+func fitWorkoutStep(loc *Locale, index int, step WorkoutStep) fitMessage {
+	durationType, durationValue := uint8(fitDurationDistance), uint32(math.Round(step.Distance*100))
+	if step.ByTime {
+		durationType, durationValue = fitDurationTime, uint32(step.Duration.Milliseconds())
+	}
+
+	targetType, low, high := uint8(fitTargetOpen), uint32(fitInvalidUint32), uint32(fitInvalidUint32)
+	if lowSpeed, highSpeed, ok := stepSpeeds(step); ok {
+		targetType, low, high = fitTargetSpeed, uint32(math.Round(lowSpeed*1000)), uint32(math.Round(highSpeed*1000))
+	}
+
+	intensity := fitIntensities[step.Kind]
+	if standingRest(step) {
+		intensity = fitIntensityRest
+	}
+
+	return fitMessage{global: fitMesgWorkoutStep, fields: fitStepFields(index, loc.T(stepNames[step.Kind]),
+		durationType, durationValue, targetType, 0, low, high, intensity)}
+}
+
+func fitStepFields(index int, name string, durationType uint8, durationValue uint32, targetType uint8,
+	targetValue, low, high uint32, intensity uint8,
+) []fitField {
+	return []fitField{
+		{num: 254, baseType: fitBaseUint16, size: 2, value: uint16(index)},
+		{num: 0, baseType: fitBaseString, size: fitWorkoutStepNameSize, value: name},
+		{num: 1, baseType: fitBaseEnum, size: 1, value: durationType},
+		{num: 2, baseType: fitBaseUint32, size: 4, value: durationValue},
+		{num: 3, baseType: fitBaseEnum, size: 1, value: targetType},
+		{num: 4, baseType: fitBaseUint32, size: 4, value: targetValue},
+		{num: 5, baseType: fitBaseUint32, size: 4, value: low},
+		{num: 6, baseType: fitBaseUint32, size: 4, value: high},
+		{num: 7, baseType: fitBaseEnum, size: 1, value: intensity},
+	}
+}
+
+// FITChecksum is the CRC-16 of FIT headers and files.
+func FITChecksum(bs []byte) uint16 {
+	var crc uint16
+
+	for _, b := range bs {
+		tmp := fitCRCTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ fitCRCTable[b&0xF]
+
+		tmp = fitCRCTable[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ fitCRCTable[(b>>4)&0xF]
+	}
+
+	return crc
+}
+
+// TCX is the Garmin Training Center XML: https://www8.garmin.com/xmlschemas/TrainingCenterDatabasev2.xsd.
+const (
+	tcxNamespace    = "http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2"
+	tcxXSINamespace = "http://www.w3.org/2001/XMLSchema-instance"
+	// tcxNameSize is the limit of workout and step names in the schema.
+	tcxNameSize = 15
+	// tcxMaxStepID is the limit of StepId_t, so a workout has at most 20 steps and repeats.
+	tcxMaxStepID = 20
+	// tcxMinRepetitions and tcxMaxRepetitions are the limits of Repetitions_t.
+	tcxMinRepetitions = 2
+	tcxMaxRepetitions = 99
+	// tcxMaxLength is the limit of Seconds and Meters, which are unsignedShort.
+	tcxMaxLength = math.MaxUint16
+)
+
+var tcxSports = map[string]string{"run": "Running", "bike": "Biking"}
+
+type tcxDatabase struct {
+	XMLName  xml.Name   `xml:"TrainingCenterDatabase"`
+	XMLNS    string     `xml:"xmlns,attr"`
+	XSI      string     `xml:"xmlns:xsi,attr"`
+	Workouts tcxWorkout `xml:"Workouts>Workout"`
+}
+
+type tcxWorkout struct {
+	Sport string    `xml:"Sport,attr"`
+	Name  string    `xml:"Name"`
+	Steps []tcxStep `xml:"Step"`
+	Notes string    `xml:"Notes,omitempty"`
+}
+
+// tcxStep is a Step_t or a Repeat_t with Child steps.
+type tcxStep struct {
+	Type        string       `xml:"xsi:type,attr"`
+	StepID      int          `xml:"StepId"`
+	Name        string       `xml:"Name,omitempty"`
+	Repetitions int          `xml:"Repetitions,omitempty"`
+	Duration    *tcxDuration `xml:"Duration"`
+	Intensity   string       `xml:"Intensity,omitempty"`
+	Target      *tcxTarget   `xml:"Target"`
+	Children    []tcxStep    `xml:"Child"`
+}
+
+// tcxDuration is a Time_t with Seconds, a Distance_t with Meters or a UserInitiated_t with neither.
+// The schema requires the element of the type even when it is zero.
+type tcxDuration struct {
+	Type    string `xml:"xsi:type,attr"`
+	Seconds *int   `xml:"Seconds"`
+	Meters  *int   `xml:"Meters"`
+}
+
+type tcxTarget struct {
+	Type      string        `xml:"xsi:type,attr"`
+	SpeedZone *tcxSpeedZone `xml:"SpeedZone"`
+}
+
+type tcxSpeedZone struct {
+	Type   string  `xml:"xsi:type,attr"`
+	ViewAs string  `xml:"ViewAs"`
+	Low    float64 `xml:"LowInMetersPerSecond"`
+	High   float64 `xml:"HighInMetersPerSecond"`
+}
+
+func marshalWorkoutTCX(loc *Locale, workout Workout, steps WorkoutSteps) ([]byte, error) {
+	sport, ok := tcxSports[workoutSport(workout)]
+	if !ok {
+		sport = "Other"
+	}
+
+	db := tcxDatabase{
+		XMLNS: tcxNamespace,
+		XSI:   tcxXSINamespace,
+		Workouts: tcxWorkout{
+			Sport: sport,
+			Name:  truncateRunes(workoutName(workout), tcxNameSize),
+			Notes: workout.Description,
+		},
+	}
+
+	id := 0
+
+	for _, step := range steps.Steps {
+		if step.Kind != StepRepeat {
+			id++
+			db.Workouts.Steps = append(db.Workouts.Steps, tcxWorkoutStep(loc, id, step))
+
+			continue
+		}
+
+		// The schema has no repeats done once.
+		if step.Repeat < tcxMinRepetitions {
+			for _, inner := range step.Steps {
+				id++
+				db.Workouts.Steps = append(db.Workouts.Steps, tcxWorkoutStep(loc, id, inner))
+			}
+
+			continue
+		}
+
+		if step.Repeat > tcxMaxRepetitions {
+			return nil, fmt.Errorf("%w: %d repetitions, TCX holds %d", ErrWorkoutFileSteps, step.Repeat, tcxMaxRepetitions)
+		}
+
+		id++
+		repeat := tcxStep{Type: "Repeat_t", StepID: id, Repetitions: step.Repeat}
+
+		for _, inner := range step.Steps {
+			id++
+			repeat.Children = append(repeat.Children, tcxWorkoutStep(loc, id, inner))
+		}
+
+		db.Workouts.Steps = append(db.Workouts.Steps, repeat)
+	}
+
+	if id > tcxMaxStepID {
+		return nil, fmt.Errorf("%w: %d steps, TCX holds %d", ErrWorkoutFileSteps, id, tcxMaxStepID)
+	}
+
+	bs, err := xml.MarshalIndent(db, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	return append([]byte(xml.Header), bs...), nil
+}
+
+func tcxWorkoutStep(loc *Locale, id int, step WorkoutStep) tcxStep {
+	target := &tcxTarget{Type: "None_t"}
+	if low, high, ok := stepSpeeds(step); ok {
+		target = &tcxTarget{Type: "Speed_t", SpeedZone: &tcxSpeedZone{
+			Type:   "CustomSpeedZone_t",
+			ViewAs: "Pace",
+			Low:    math.Round(low*1000) / 1000,
+			High:   math.Round(high*1000) / 1000,
+		}}
+	}
+
+	intensity := "Active"
+	if step.Kind == StepRecovery {
+		intensity = "Resting"
+	}
+
+	return tcxStep{
+		Type:      "Step_t",
+		StepID:    id,
+		Name:      truncateRunes(loc.T(stepNames[step.Kind]), tcxNameSize),
+		Duration:  tcxStepDuration(step),
+		Intensity: intensity,
+		Target:    target,
+	}
+}
+
+// tcxStepDuration ends the step after the distance or the time. A step too long for the schema ends after
+// the estimated time, or when the lap button is pressed.
+func tcxStepDuration(step WorkoutStep) *tcxDuration {
+	seconds := int(step.Duration.Round(time.Second).Seconds())
+	meters := int(math.Round(step.Distance))
+
+	switch {
+	case !step.ByTime && meters <= tcxMaxLength:
+		return &tcxDuration{Type: "Distance_t", Meters: &meters}
+	case seconds <= tcxMaxLength:
+		return &tcxDuration{Type: "Time_t", Seconds: &seconds}
+	default:
+		return &tcxDuration{Type: "UserInitiated_t"}
+	}
+}
+
+// ZWO is the workout file of Zwift. Zwift knows no absolute paces, so the power of steps
+// is a share of the threshold power or the threshold pace of the athlete.
+const (
+	zwoAuthor = "Final Surge Bot"
+
+	zwoPowerEasy      = 0.5
+	zwoPowerSteady    = 0.75
+	zwoPowerInterval  = 1
+	zwoPowerRecovery  = 0.55
+	zwoPowerStanding  = 0.4
+	zwoSportRun       = "run"
+	zwoSportBike      = "bike"
+	zwoTagSteady      = "SteadyState"
+	zwoTagWarmup      = "Warmup"
+	zwoTagCooldown    = "Cooldown"
+	zwoTagIntervals   = "IntervalsT"
+	zwoIntervalsSteps = 2
+)
+
+type zwoFile struct {
+	XMLName     xml.Name     `xml:"workout_file"`
+	Author      string       `xml:"author"`
+	Name        string       `xml:"name"`
+	Description string       `xml:"description"`
+	SportType   string       `xml:"sportType"`
+	Segments    []zwoSegment `xml:"workout>segment"`
+}
+
+// zwoSegment is an element of the workout such as SteadyState. Its name is in XMLName.
+type zwoSegment struct {
+	XMLName     xml.Name
+	Duration    int     `xml:"Duration,attr,omitempty"`
+	Power       float64 `xml:"Power,attr,omitempty"`
+	PowerLow    float64 `xml:"PowerLow,attr,omitempty"`
+	PowerHigh   float64 `xml:"PowerHigh,attr,omitempty"`
+	Repeat      int     `xml:"Repeat,attr,omitempty"`
+	OnDuration  int     `xml:"OnDuration,attr,omitempty"`
+	OffDuration int     `xml:"OffDuration,attr,omitempty"`
+	OnPower     float64 `xml:"OnPower,attr,omitempty"`
+	OffPower    float64 `xml:"OffPower,attr,omitempty"`
+}
+
+func marshalWorkoutZWO(workout Workout, steps WorkoutSteps) ([]byte, error) {
+	sport := zwoSportRun
+	if workoutSport(workout) == zwoSportBike {
+		sport = zwoSportBike
+	}
+
+	file := zwoFile{
+		Author:      zwoAuthor,
+		Name:        workoutName(workout),
+		Description: workout.Description,
+		SportType:   sport,
+	}
+
+	for _, step := range steps.Steps {
+		switch {
+		case step.Kind != StepRepeat:
+			file.Segments = append(file.Segments, zwoStep(step))
+		case len(step.Steps) == zwoIntervalsSteps:
+			on, off := step.Steps[0], step.Steps[1]
+			file.Segments = append(file.Segments, zwoSegment{
+				XMLName:     xml.Name{Local: zwoTagIntervals},
+				Repeat:      step.Repeat,
+				OnDuration:  zwoSeconds(on.Duration),
+				OffDuration: zwoSeconds(off.Duration),
+				OnPower:     zwoPower(on),
+				OffPower:    zwoPower(off),
+			})
+		default:
+			// Zwift repeats only pairs of steps, so other repeats are written out.
+			for i := 0; i < step.Repeat; i++ {
+				for _, inner := range step.Steps {
+					file.Segments = append(file.Segments, zwoStep(inner))
+				}
+			}
+		}
+	}
+
+	bs, err := xml.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal: %w", err)
+	}
+
+	return bs, nil
+}
+
+func zwoStep(step WorkoutStep) zwoSegment {
+	segment := zwoSegment{Duration: zwoSeconds(step.Duration)}
+
+	switch step.Kind {
+	case StepWarmup:
+		segment.XMLName.Local = zwoTagWarmup
+		segment.PowerLow, segment.PowerHigh = zwoPowerEasy, zwoPowerSteady
+	case StepCooldown:
+		segment.XMLName.Local = zwoTagCooldown
+		segment.PowerLow, segment.PowerHigh = zwoPowerSteady, zwoPowerEasy
+	default:
+		segment.XMLName.Local = zwoTagSteady
+		segment.Power = zwoPower(step)
+	}
+
+	return segment
+}
+
+func zwoPower(step WorkoutStep) float64 {
+	switch {
+	case standingRest(step):
+		return zwoPowerStanding
+	case step.Kind == StepRecovery:
+		return zwoPowerRecovery
+	case step.Kind == StepInterval:
+		return zwoPowerInterval
+	default:
+		return zwoPowerSteady
+	}
+}
+
+func zwoSeconds(d time.Duration) int {
+	if seconds := int(d.Round(time.Second).Seconds()); seconds > 0 {
+		return seconds
+	}
+
+	return 1
+}
+
+// commandExportWorkout sends the first workout of today with steps as a file for a watch or Zwift.
+func (b *Bot) commandExportWorkout(ctx context.Context, userName string, chatID int64, loc *Locale, args string,
+) (tgbotapi.Chattable, error) {
+	format, err := ParseWorkoutFileArgs(args)
+	if errors.Is(err, ErrWorkoutFileArgs) {
+		return tgbotapi.NewMessage(chatID, loc.T(msgExportWorkoutUsage)), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parse export workout args: %w", err)
+	}
+
+	today, err := b.userToday(ctx, userName)
+	if err != nil {
+		return nil, err
+	}
+
+	userTokens, err := b.db.UserTokens(ctx, userName)
+	if errors.Is(err, ErrNotFound) {
+		return tgbotapi.NewMessage(chatID, loc.T(msgAuthorizeFirst)), nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get usertokens: %w", err)
+	}
+
+	workouts, err := accountsWorkouts(ctx, b.fs, userTokens, today, today)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range workouts {
+		if !w.Date.Equal(today) || w.RestDay || len(ParseWorkoutSteps(w).Steps) == 0 {
+			continue
+		}
+
+		bs, err := MarshalWorkoutFile(loc, w, format, b.clock.Now())
+		if errors.Is(err, ErrWorkoutFileSteps) {
+			return tgbotapi.NewMessage(chatID, loc.T(msgExportWorkoutSteps, strings.ToUpper(format))), nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("marshal workout file: %w", err)
+		}
+
+		return tgbotapi.NewDocumentUpload(chatID, tgbotapi.FileBytes{
+			Name:  WorkoutFileName(w, format),
+			Bytes: bs,
+		}), nil
+	}
+
+	return tgbotapi.NewMessage(chatID, loc.T(msgExportWorkoutNone)), nil
+}
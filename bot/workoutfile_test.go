@@ -0,0 +1,436 @@
+package bot_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/alexandear/final-surge-bot/bot"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+	"github.com/golang/mock/gomock"
+)
+
+var intervalsWorkout = Workout{
+	Date:        time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC),
+	Activity:    "Run",
+	Name:        "Track",
+	Description: "WU 2km, 6x800m @ 3:10 w/ 90 sec rest, CD 10 min",
+}
+
+// fitMessage is a decoded FIT data message. Numbers are uint64 and strings are without trailing zeros.
+type fitMessage struct {
+	Global uint16
+	Fields map[uint8]interface{}
+}
+
+// decodeFIT decodes FIT files with little-endian definition and data messages without developer fields.
+func decodeFIT(bs []byte) ([]fitMessage, error) {
+	if len(bs) < 14 || bs[0] != 14 || string(bs[8:12]) != ".FIT" {
+		return nil, errors.New("invalid header")
+	}
+
+	if crc := binary.LittleEndian.Uint16(bs[12:14]); crc != FITChecksum(bs[:12]) {
+		return nil, fmt.Errorf("header crc %x", crc)
+	}
+
+	size := int(binary.LittleEndian.Uint32(bs[4:8]))
+	if len(bs) != 14+size+2 {
+		return nil, fmt.Errorf("data size %d, file size %d", size, len(bs))
+	}
+
+	if crc := binary.LittleEndian.Uint16(bs[14+size:]); crc != FITChecksum(bs[:14+size]) {
+		return nil, fmt.Errorf("file crc %x", crc)
+	}
+
+	type field struct{ Num, Size, BaseType uint8 }
+
+	type definition struct {
+		global uint16
+		fields []field
+	}
+
+	definitions := map[uint8]definition{}
+	r := bytes.NewReader(bs[14 : 14+size])
+
+	var messages []fitMessage
+
+	for r.Len() > 0 {
+		header, _ := r.ReadByte()
+		local := header & 0x0F
+
+		if header&0x40 != 0 {
+			var def struct {
+				Reserved, Architecture uint8
+				Global                 uint16
+				Fields                 uint8
+			}
+			if err := binary.Read(r, binary.LittleEndian, &def); err != nil {
+				return nil, fmt.Errorf("read definition: %w", err)
+			}
+
+			fields := make([]field, def.Fields)
+			if err := binary.Read(r, binary.LittleEndian, fields); err != nil {
+				return nil, fmt.Errorf("read fields: %w", err)
+			}
+
+			definitions[local] = definition{global: def.Global, fields: fields}
+
+			continue
+		}
+
+		def, ok := definitions[local]
+		if !ok {
+			return nil, fmt.Errorf("undefined local message %d", local)
+		}
+
+		m := fitMessage{Global: def.global, Fields: map[uint8]interface{}{}}
+
+		for _, f := range def.fields {
+			value := make([]byte, f.Size)
+			if _, err := r.Read(value); err != nil {
+				return nil, fmt.Errorf("read field %d: %w", f.Num, err)
+			}
+
+			switch f.BaseType {
+			case 0x07:
+				m.Fields[f.Num] = string(bytes.TrimRight(value, "\x00"))
+			case 0x00, 0x02:
+				m.Fields[f.Num] = uint64(value[0])
+			case 0x84:
+				m.Fields[f.Num] = uint64(binary.LittleEndian.Uint16(value))
+			case 0x86:
+				m.Fields[f.Num] = uint64(binary.LittleEndian.Uint32(value))
+			default:
+				return nil, fmt.Errorf("base type %x", f.BaseType)
+			}
+		}
+
+		messages = append(messages, m)
+	}
+
+	return messages, nil
+}
+
+func TestMarshalWorkoutFile_FIT(t *testing.T) {
+	created := time.Date(2026, time.October, 20, 6, 0, 0, 0, time.UTC)
+
+	bs, err := MarshalWorkoutFile(LocaleFor(LanguageEnglish), intervalsWorkout, WorkoutFileFIT, created)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := decodeFIT(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const invalid = 0xFFFFFFFF
+
+	step := func(index uint64, name string, durationType, durationValue, targetType, targetValue, low, high,
+		intensity uint64,
+	) fitMessage {
+		return fitMessage{Global: 27, Fields: map[uint8]interface{}{
+			254: index, 0: name, 1: durationType, 2: durationValue, 3: targetType, 4: targetValue,
+			5: low, 6: high, 7: intensity,
+		}}
+	}
+
+	expected := []fitMessage{
+		{Global: 0, Fields: map[uint8]interface{}{0: uint64(5), 1: uint64(255), 2: uint64(0),
+			4: uint64(created.Unix() - 631065600)}},
+		{Global: 26, Fields: map[uint8]interface{}{8: "Track", 4: uint64(1), 6: uint64(5)}},
+		step(0, "Warm-up", 1, 200000, 2, 0, invalid, invalid, 2),
+		step(1, "Interval", 1, 80000, 0, 0, 4124, 4301, 5),
+		step(2, "Recovery", 0, 90000, 2, 0, invalid, invalid, 1),
+		step(3, "", 6, 1, 0xFF, 6, invalid, invalid, 0xFF),
+		step(4, "Cool-down", 0, 600000, 2, 0, invalid, invalid, 3),
+	}
+
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("actual=%+v, expected=%+v", messages, expected)
+	}
+}
+
+type tcxStep struct {
+	Type        string `xml:"http://www.w3.org/2001/XMLSchema-instance type,attr"`
+	StepID      int    `xml:"StepId"`
+	Name        string
+	Repetitions int
+	Duration    struct {
+		Type    string `xml:"http://www.w3.org/2001/XMLSchema-instance type,attr"`
+		Seconds int
+		Meters  int
+	}
+	Intensity string
+	Target    struct {
+		Type      string `xml:"http://www.w3.org/2001/XMLSchema-instance type,attr"`
+		SpeedZone struct {
+			Low  float64 `xml:"LowInMetersPerSecond"`
+			High float64 `xml:"HighInMetersPerSecond"`
+		}
+	}
+	Children []tcxStep `xml:"Child"`
+}
+
+func TestMarshalWorkoutFile_TCX(t *testing.T) {
+	bs, err := MarshalWorkoutFile(LocaleFor(LanguageEnglish), intervalsWorkout, WorkoutFileTCX, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var db struct {
+		XMLName  xml.Name `xml:"http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2 TrainingCenterDatabase"`
+		Workouts []struct {
+			Sport string `xml:"Sport,attr"`
+			Name  string
+			Steps []tcxStep `xml:"Step"`
+		} `xml:"Workouts>Workout"`
+	}
+	if err := xml.Unmarshal(bs, &db); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(db.Workouts) != 1 {
+		t.Fatalf("actual workouts=%d, expected=1", len(db.Workouts))
+	}
+
+	w := db.Workouts[0]
+	if w.Sport != "Running" || w.Name != "Track" || len(w.Steps) != 3 {
+		t.Fatalf("actual sport=%s, name=%s, steps=%d", w.Sport, w.Name, len(w.Steps))
+	}
+
+	if s := w.Steps[0]; s.Type != "Step_t" || s.Duration.Type != "Distance_t" || s.Duration.Meters != 2000 ||
+		s.Target.Type != "None_t" {
+		t.Errorf("warm-up: %+v", s)
+	}
+
+	repeat := w.Steps[1]
+	if repeat.Type != "Repeat_t" || repeat.StepID != 2 || repeat.Repetitions != 6 || len(repeat.Children) != 2 {
+		t.Fatalf("repeat: %+v", repeat)
+	}
+
+	if s := repeat.Children[0]; s.StepID != 3 || s.Duration.Meters != 800 || s.Target.Type != "Speed_t" ||
+		s.Target.SpeedZone.Low != 4.124 || s.Target.SpeedZone.High != 4.301 || s.Intensity != "Active" {
+		t.Errorf("interval: %+v", s)
+	}
+
+	if s := repeat.Children[1]; s.Duration.Type != "Time_t" || s.Duration.Seconds != 90 || s.Intensity != "Resting" {
+		t.Errorf("recovery: %+v", s)
+	}
+
+	if s := w.Steps[2]; s.StepID != 5 || s.Name != "Cool-down" || s.Duration.Seconds != 600 {
+		t.Errorf("cool-down: %+v", s)
+	}
+}
+
+func TestMarshalWorkoutFile_TCXLimits(t *testing.T) {
+	marshal := func(description string) ([]byte, error) {
+		workout := Workout{Date: intervalsWorkout.Date, Activity: "Run", Description: description}
+
+		return MarshalWorkoutFile(LocaleFor(LanguageEnglish), workout, WorkoutFileTCX, time.Time{})
+	}
+
+	t.Run("zero length", func(t *testing.T) {
+		bs, err := marshal("6x400m w/ 0.2'' rest")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The schema requires Seconds and Meters in Time_t and Distance_t even when they are zero.
+		for _, expected := range []string{"<Meters>400</Meters>", "<Seconds>0</Seconds>"} {
+			if !bytes.Contains(bs, []byte(expected)) {
+				t.Errorf("actual=%s, expected to contain %s", bs, expected)
+			}
+		}
+	})
+
+	t.Run("20 steps", func(t *testing.T) {
+		if _, err := marshal(strings.Repeat("1km, ", 19) + "1km"); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("21 steps", func(t *testing.T) {
+		if _, err := marshal(strings.Repeat("1km, ", 20) + "1km"); !errors.Is(err, ErrWorkoutFileSteps) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrWorkoutFileSteps)
+		}
+	})
+
+	t.Run("repeat once", func(t *testing.T) {
+		bs, err := marshal("1x1km w/ 400 jog")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if bytes.Contains(bs, []byte("Repeat_t")) {
+			t.Errorf("actual=%s, expected steps without a repeat", bs)
+		}
+	})
+
+	t.Run("100 repetitions", func(t *testing.T) {
+		if _, err := marshal("100x100m w/ 100 jog"); !errors.Is(err, ErrWorkoutFileSteps) {
+			t.Errorf("actual err=%v, expected=%v", err, ErrWorkoutFileSteps)
+		}
+	})
+}
+
+func TestMarshalWorkoutFile_FastPace(t *testing.T) {
+	// A pace within the tolerance has no speed range, so the step has no target.
+	workout := Workout{Date: intervalsWorkout.Date, Activity: "Run", Description: "6x1000m @ 0:04/km"}
+
+	bs, err := MarshalWorkoutFile(LocaleFor(LanguageEnglish), workout, WorkoutFileFIT, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages, err := decodeFIT(bs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const targetOpen = 2
+
+	interval := messages[2]
+	if interval.Fields[0] != "Interval" || interval.Fields[3] != uint64(targetOpen) {
+		t.Errorf("actual=%+v, expected interval without a target", interval)
+	}
+
+	bs, err = MarshalWorkoutFile(LocaleFor(LanguageEnglish), workout, WorkoutFileTCX, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(bs, []byte("Speed_t")) {
+		t.Errorf("actual=%s, expected steps without a speed target", bs)
+	}
+}
+
+func TestMarshalWorkoutFile_ZWO(t *testing.T) {
+	bs, err := MarshalWorkoutFile(LocaleFor(LanguageEnglish), intervalsWorkout, WorkoutFileZWO, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type segment struct {
+		XMLName     xml.Name
+		Duration    int     `xml:"Duration,attr"`
+		Power       float64 `xml:"Power,attr"`
+		PowerLow    float64 `xml:"PowerLow,attr"`
+		PowerHigh   float64 `xml:"PowerHigh,attr"`
+		Repeat      int     `xml:"Repeat,attr"`
+		OnDuration  int     `xml:"OnDuration,attr"`
+		OffDuration int     `xml:"OffDuration,attr"`
+		OnPower     float64 `xml:"OnPower,attr"`
+		OffPower    float64 `xml:"OffPower,attr"`
+	}
+
+	var file struct {
+		Name      string `xml:"name"`
+		SportType string `xml:"sportType"`
+		Workout   struct {
+			Segments []segment `xml:",any"`
+		} `xml:"workout"`
+	}
+	if err := xml.Unmarshal(bs, &file); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []segment{
+		{XMLName: xml.Name{Local: "Warmup"}, Duration: 720, PowerLow: 0.5, PowerHigh: 0.75},
+		{
+			XMLName: xml.Name{Local: "IntervalsT"}, Repeat: 6, OnDuration: 190, OffDuration: 90,
+			OnPower: 1, OffPower: 0.4,
+		},
+		{XMLName: xml.Name{Local: "Cooldown"}, Duration: 600, PowerLow: 0.75, PowerHigh: 0.5},
+	}
+
+	if file.Name != "Track" || file.SportType != "run" || !reflect.DeepEqual(file.Workout.Segments, expected) {
+		t.Errorf("actual=%+v, expected segments=%+v", file, expected)
+	}
+}
+
+func TestParseWorkoutFileArgs(t *testing.T) {
+	for args, expected := range map[string]string{"": WorkoutFileFIT, "TCX": WorkoutFileTCX, " zwo ": WorkoutFileZWO} {
+		if actual, err := ParseWorkoutFileArgs(args); err != nil || actual != expected {
+			t.Errorf("args=%q: actual=%s, err=%v, expected=%s", args, actual, err, expected)
+		}
+	}
+
+	if _, err := ParseWorkoutFileArgs("gpx"); !errors.Is(err, ErrWorkoutFileArgs) {
+		t.Errorf("actual=%v, expected=%v", err, ErrWorkoutFileArgs)
+	}
+}
+
+func TestBot_ProcessUpdate_ExportWorkout(t *testing.T) {
+	userToken := UserToken{
+		UserKey: "a0acc35a-c910-4f80-b410-b616d03cf917",
+		Token:   "d174c652-b12f-4aad-b730-a43a2c74fa9f",
+	}
+	today := intervalsWorkout.Date
+
+	newBot := func(t *testing.T, workouts []Workout) *testBot {
+		bot := newTestBot(t, &Config{}, Settings{})
+		bot.clock.EXPECT().Now().Return(today.Add(6 * time.Hour)).AnyTimes()
+		bot.storage.EXPECT().UserTokens(gomock.Any(), testUserName).Return([]UserToken{userToken}, nil).Times(1)
+		bot.fs.EXPECT().Workouts(gomock.Any(), userToken, today, today).Return(workouts, nil).Times(1)
+
+		return bot
+	}
+
+	command := func(text string) tgbotapi.Update {
+		return tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: testChatID},
+			From:     &tgbotapi.User{UserName: testUserName},
+			Entities: &[]tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len("/export_workout")}},
+			Text:     text,
+		}}
+	}
+
+	t.Run("file", func(t *testing.T) {
+		rest := Workout{Date: today, Description: "Rest Day", RestDay: true}
+		bot := newBot(t, []Workout{rest, intervalsWorkout})
+		bot.sender.EXPECT().Send(gomock.Any()).DoAndReturn(func(msg tgbotapi.Chattable) (tgbotapi.Message, error) {
+			file := msg.(tgbotapi.DocumentConfig).File.(tgbotapi.FileBytes)
+			if file.Name != "workout_2026-10-20.fit" {
+				t.Errorf("actual=%s, expected=workout_2026-10-20.fit", file.Name)
+			}
+
+			if _, err := decodeFIT(file.Bytes); err != nil {
+				t.Error(err)
+			}
+
+			return tgbotapi.Message{}, nil
+		}).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), command("/export_workout")); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("too many steps", func(t *testing.T) {
+		bot := newBot(t, []Workout{{Date: today, Activity: "Run", Description: strings.Repeat("1km, ", 20) + "1km"}})
+		bot.sender.EXPECT().Send(tgbotapi.NewMessage(testChatID,
+			"Today's workout has more steps than a TCX file holds. Try /export_workout fit")).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), command("/export_workout tcx")); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("no steps", func(t *testing.T) {
+		bot := newBot(t, []Workout{{Date: today, Description: "Easy as you feel"}})
+		bot.sender.EXPECT().Send(tgbotapi.NewMessage(testChatID,
+			"No workout with distances or durations today to export")).Times(1)
+
+		if err := bot.ProcessUpdate(context.Background(), command("/export_workout zwo")); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -7,6 +7,8 @@ require (
 	github.com/golang/mock v1.3.1
 	github.com/jackc/pgx/v4 v4.10.1
 	github.com/kelseyhightower/envconfig v1.4.0
+	github.com/mattn/go-sqlite3 v1.14.22
+	golang.org/x/image v0.18.0
 )
 
 require (
@@ -20,6 +22,6 @@ require (
 	github.com/jackc/puddle v1.1.3 // indirect
 	github.com/technoweenie/multipartstreamer v1.0.1 // indirect
 	golang.org/x/crypto v0.1.0 // indirect
-	golang.org/x/text v0.4.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
 	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
 )
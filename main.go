@@ -2,12 +2,15 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"strconv"
 	"time"
+	// Embedded so /timezone works in images without system tzdata.
+	_ "time/tzdata"
 
 	"github.com/alexandear/final-surge-bot/bot"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
@@ -34,18 +37,12 @@ func run() error {
 		return fmt.Errorf("init config: %w", err)
 	}
 
-	dbPool, err := pgxpool.Connect(context.Background(), config.DatabaseURL)
+	storage, closeStorage, err := newStorage(context.Background(), config)
 	if err != nil {
-		return fmt.Errorf("unable to connect to database %s: %w", config.DatabaseURL, err)
+		return fmt.Errorf("init storage: %w", err)
 	}
 
-	defer dbPool.Close()
-
-	pg := bot.NewPostgres(dbPool)
-
-	if errInit := pg.Init(context.Background()); errInit != nil {
-		return fmt.Errorf("init postgres: %w", errInit)
-	}
+	defer closeStorage()
 
 	tgbot, err := tgbotapi.NewBotAPI(config.BotAPIKey)
 	if err != nil {
@@ -54,20 +51,13 @@ func run() error {
 
 	tgbot.Debug = config.Debug
 
-	updates, err := updates(tgbot, config)
-	if err != nil {
-		return fmt.Errorf("init bot api: %w", err)
+	if config.Debug {
+		log.Printf("bot authorized on account %s", tgbot.Self.UserName)
 	}
 
-	go func() {
-		var host string
-		if config.Debug {
-			host = "localhost"
-		}
-
-		addr := net.JoinHostPort(host, strconv.Itoa(config.Port))
-		serve(config.Debug, addr)
-	}()
+	mode := config.Updates()
+	webhookPath := "/" + tgbot.Token
+	source := bot.NewUpdateSource(tgbot, bot.NewClock(), mode, config.PublicURL+tgbot.Token)
 
 	fs := bot.NewFinalSurgeAPI(&http.Client{
 		Timeout: fsClientTimeout,
@@ -75,81 +65,117 @@ func run() error {
 
 	clock := bot.NewClock()
 
-	b := bot.NewBot(tgbot, pg, fs, clock)
-
-	for update := range updates {
-		if err := b.ProcessUpdate(context.Background(), update); err != nil {
-			log.Printf("process update: %v", err)
+	templates := bot.NewTaskTemplates()
+	if config.TemplateDir != "" {
+		if err := templates.LoadDir(config.TemplateDir); err != nil {
+			return fmt.Errorf("load templates: %w", err)
 		}
 	}
 
-	return nil
-}
+	b := bot.NewBot(tgbot, storage, fs, clock, config, templates)
 
-func updates(bot *tgbotapi.BotAPI, config *bot.Config) (tgbotapi.UpdatesChannel, error) {
-	if config.Debug {
-		log.Printf("bot authorized on account %s", bot.Self.UserName)
+	// The bot works without the menu, so a failure is not fatal.
+	if err := b.RegisterCommands(); err != nil {
+		log.Printf("register commands: %v", err)
 	}
 
-	if config.RunOnCloud {
-		updates, err := updatesCloud(bot, config.PublicURL)
-		if err != nil {
-			return nil, fmt.Errorf("get updates on cloud: %w", err)
+	// The client of the bot counts requests for /stats, so every other component shares it.
+	countingFS := b.FinalSurge()
+
+	calendar := bot.NewCalendar(storage, countingFS, clock, config.CalendarDays)
+
+	go func() {
+		var host string
+		if config.Debug {
+			host = "localhost"
 		}
 
-		return updates, nil
-	}
+		addr := net.JoinHostPort(host, strconv.Itoa(config.Port))
+		serve(config.Debug, addr, calendar, source, webhookPath)
+	}()
 
-	updates, err := updatesLocal(bot)
+	// The server is started before the webhook is set, so Telegram can deliver updates right away.
+	updates, err := source.Start()
 	if err != nil {
-		return nil, fmt.Errorf("get updates local: %w", err)
+		return fmt.Errorf("start %s updates: %w", mode, err)
 	}
 
-	return updates, nil
-}
+	go source.Run(context.Background())
 
-func updatesCloud(bot *tgbotapi.BotAPI, publicURL string) (updates tgbotapi.UpdatesChannel, err error) {
-	webhookURL := publicURL + bot.Token
-	if _, err = bot.SetWebhook(tgbotapi.NewWebhook(webhookURL)); err != nil {
-		return nil, fmt.Errorf("set webhook to %s: %w", webhookURL, err)
-	}
+	go bot.NewWatcher(tgbot, storage, countingFS, clock, config.WatchDays, config.WatchInterval).
+		Run(context.Background())
 
-	info, err := bot.GetWebhookInfo()
+	digestDay, err := bot.ParseWeekday(config.DigestDay)
 	if err != nil {
-		return nil, fmt.Errorf("get webhook info: %w", err)
+		return fmt.Errorf("parse digest day: %w", err)
 	}
 
-	if info.LastErrorDate != 0 {
-		log.Printf("telegram callback failed: %s", info.LastErrorMessage)
-	}
+	go bot.NewDigest(tgbot, storage, countingFS, clock, digestDay, config.DigestHour).Run(context.Background())
+	go bot.NewReminder(tgbot, storage, countingFS, clock, config.ReminderHour).Run(context.Background())
 
-	updates = bot.ListenForWebhook("/" + bot.Token)
+	for update := range updates {
+		if err := b.ProcessUpdate(context.Background(), update); err != nil {
+			log.Printf("process update: %v", err)
+		}
+	}
 
-	return updates, nil
+	return nil
 }
 
-func updatesLocal(bot *tgbotapi.BotAPI) (updates tgbotapi.UpdatesChannel, err error) {
-	const updateTimeout = 60 * time.Second
+func newStorage(ctx context.Context, config *bot.Config) (bot.Storage, func(), error) {
+	switch config.Storage {
+	case bot.StorageMemory:
+		return bot.NewMemory(), func() {}, nil
+	case bot.StorageSQLite:
+		db, err := bot.OpenSQLite(config.SQLitePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open sqlite: %w", err)
+		}
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = int(updateTimeout.Seconds())
+		closeDB := func() {
+			if err := db.Close(); err != nil {
+				log.Printf("close sqlite: %v", err)
+			}
+		}
 
-	updates, err = bot.GetUpdatesChan(u)
-	if err != nil {
-		return nil, fmt.Errorf("get updates chan: %w", err)
-	}
+		sqlite := bot.NewSQLite(db)
+		if err := sqlite.Init(ctx); err != nil {
+			closeDB()
+
+			return nil, nil, fmt.Errorf("init sqlite: %w", err)
+		}
+
+		return sqlite, closeDB, nil
+	default:
+		dbPool, err := pgxpool.Connect(ctx, config.DatabaseURL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to connect to database %s: %w", config.DatabaseURL, err)
+		}
 
-	return updates, nil
+		pg := bot.NewPostgres(dbPool)
+		if err := pg.Init(ctx); err != nil {
+			dbPool.Close()
+
+			return nil, nil, fmt.Errorf("init postgres: %w", err)
+		}
+
+		return pg, dbPool.Close, nil
+	}
 }
 
-func serve(debug bool, addr string) {
+func serve(debug bool, addr string, calendar http.Handler, source *bot.UpdateSource, webhookPath string) {
 	if debug {
 		log.Printf("start listening on %s", addr)
 	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/", http.FileServer(http.Dir("./web")))
-	mux.Handle("/check", checkHandler(debug))
+	mux.Handle("/check", checkHandler(debug, source))
+	mux.Handle(bot.CalendarPath, calendar)
+
+	if source.Status().Mode == bot.UpdateModeWebhook {
+		mux.Handle(webhookPath, source)
+	}
 
 	srv := &http.Server{
 		Addr:         addr,
@@ -163,12 +189,23 @@ func serve(debug bool, addr string) {
 	}
 }
 
-func checkHandler(debug bool) http.HandlerFunc {
+// checkHandler reports how updates are received and fails while they are not.
+func checkHandler(debug bool, source *bot.UpdateSource) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if debug {
 			log.Println("check requested")
 		}
 
-		w.WriteHeader(http.StatusOK)
+		status := source.Status()
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if !status.Ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		if err := json.NewEncoder(w).Encode(status); err != nil {
+			log.Printf("write check: %v", err)
+		}
 	}
 }